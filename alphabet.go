@@ -0,0 +1,126 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AlphabetEncoder writes ids as base-N numbers in a caller's alphabet,
+// most significant digit first and without padding.
+type AlphabetEncoder struct {
+	alphabet string
+	index    [256]int16 // 字符到数值, -1 表示不在字母表中
+}
+
+// confusable are the pairs of characters easily misread for each other.
+var confusable = [][2]byte{{'0', 'O'}, {'0', 'o'}, {'1', 'l'}, {'1', 'I'}, {'I', 'l'}}
+
+// NewAlphabetEncoder builds an encoder from alphabet, whose i-th character
+// is digit i. The alphabet must be 2 to 94 printable ASCII characters
+// other than space, none repeated, and must not hold both characters of a
+// pair readers confuse, such as 0 and O or 1 and l, so every token reads
+// one way.
+func NewAlphabetEncoder(alphabet string) (*AlphabetEncoder, error) {
+	if len(alphabet) < 2 {
+		return nil, errors.New("alphabet needs at least 2 characters")
+	}
+	e := &AlphabetEncoder{alphabet: alphabet}
+	for i := range e.index {
+		e.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c <= ' ' || c > '~' {
+			return nil, errors.New(fmt.Sprintf("alphabet character %q is not printable ASCII", c))
+		}
+		if e.index[c] >= 0 {
+			return nil, errors.New(fmt.Sprintf("alphabet repeats %q", c))
+		}
+		e.index[c] = int16(i)
+	}
+	for _, p := range confusable {
+		if e.index[p[0]] >= 0 && e.index[p[1]] >= 0 {
+			return nil, errors.New(fmt.Sprintf("alphabet holds both %q and %q, which read alike", p[0], p[1]))
+		}
+	}
+	return e, nil
+}
+
+// Alphabet returns the alphabet of the encoder.
+func (e *AlphabetEncoder) Alphabet() string {
+	return e.alphabet
+}
+
+// Encode returns f in the encoder's alphabet.
+func (e *AlphabetEncoder) Encode(f ID) string {
+	base := uint64(len(e.alphabet))
+	n := uint64(f)
+	var buf [64]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = e.alphabet[n%base]
+		n /= base
+		if n == 0 {
+			break
+		}
+	}
+	return string(buf[i:])
+}
+
+// Decode parses an id written by Encode.
+func (e *AlphabetEncoder) Decode(s string) (ID, error) {
+	if s == "" {
+		return 0, errors.New("empty snowflake id")
+	}
+	base := uint64(len(e.alphabet))
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		d := e.index[s[i]]
+		if d < 0 {
+			return 0, errors.New(fmt.Sprintf("invalid character %q in snowflake id %q", s[i], s))
+		}
+		if n > (uint64(MaxID)-uint64(d))/base {
+			return 0, errors.New(fmt.Sprintf("snowflake id %q overflows 63 bits", s))
+		}
+		n = n*base + uint64(d)
+	}
+	return ID(n), nil
+}
+
+var encodings = struct {
+	sync.RWMutex
+	m map[string]*AlphabetEncoder
+}{m: map[string]*AlphabetEncoder{
+	"base58":           mustAlphabet("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"),
+	"base32-crockford": mustAlphabet("0123456789ABCDEFGHJKMNPQRSTVWXYZ"),
+}}
+
+func mustAlphabet(alphabet string) *AlphabetEncoder {
+	e, err := NewAlphabetEncoder(alphabet)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// RegisterEncoding makes e available as name to LookupEncoding. Names of
+// the built-in encodings, base58 and base32-crockford, cannot be reused.
+func RegisterEncoding(name string, e *AlphabetEncoder) error {
+	encodings.Lock()
+	defer encodings.Unlock()
+	if _, ok := encodings.m[name]; ok {
+		return errors.New(fmt.Sprintf("encoding %q already registered", name))
+	}
+	encodings.m[name] = e
+	return nil
+}
+
+// LookupEncoding returns the encoding registered as name.
+func LookupEncoding(name string) (*AlphabetEncoder, bool) {
+	encodings.RLock()
+	defer encodings.RUnlock()
+	e, ok := encodings.m[name]
+	return e, ok
+}
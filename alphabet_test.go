@@ -0,0 +1,55 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestAlphabetEncoder(t *testing.T) {
+	hex, err := NewAlphabetEncoder("0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := hex.Encode(0x1f); s != "1f" {
+		t.Errorf("Encode: got %q, want 1f", s)
+	}
+	if s := hex.Encode(0); s != "0" {
+		t.Errorf("Encode(0): got %q, want 0", s)
+	}
+	for _, name := range []string{"base58", "base32-crockford"} {
+		e, ok := LookupEncoding(name)
+		if !ok {
+			t.Fatalf("%s not registered", name)
+		}
+		for _, f := range []ID{0, 1, 2703196044657165313, MaxID} {
+			if got, err := e.Decode(e.Encode(f)); err != nil || got != f {
+				t.Errorf("%s %d: round trip got %d, %v", name, f, got, err)
+			}
+		}
+	}
+	if _, err := hex.Decode("8000000000000000"); err == nil {
+		t.Error("64-bit value should not decode")
+	}
+	if _, err := hex.Decode("1g"); err == nil {
+		t.Error("character outside the alphabet should not decode")
+	}
+
+	for _, bad := range []string{"a", "abca", "ab c", "01O", "1lx"} {
+		if _, err := NewAlphabetEncoder(bad); err == nil {
+			t.Errorf("alphabet %q should be refused", bad)
+		}
+	}
+	if err := RegisterEncoding("hex", hex); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		encodings.Lock()
+		delete(encodings.m, "hex")
+		encodings.Unlock()
+	}()
+	if e, ok := LookupEncoding("hex"); !ok || e != hex {
+		t.Error("registered encoding not found")
+	}
+	if err := RegisterEncoding("base58", hex); err == nil {
+		t.Error("built-in name should not be reusable")
+	}
+}
@@ -0,0 +1,45 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTimeOutOfRange is returned when an adjusted timestamp does not fit the
+// layout.
+var ErrTimeOutOfRange = errors.New("snowflake: time out of range")
+
+// timestamp returns the unix millisecond f was issued at.
+func (f ID) timestamp() int64 {
+	return (int64(f) >> timestampLeftShift) + twepoch
+}
+
+// fromTimestamp returns the smallest id of the unix millisecond ts.
+func fromTimestamp(ts int64) (ID, error) {
+	if ts < twepoch || ts-twepoch > DefaultLayout.MaxTimestamp() {
+		return 0, ErrTimeOutOfRange
+	}
+	return ID((ts - twepoch) << timestampLeftShift), nil
+}
+
+// AddTime returns the smallest id of the millisecond d after f, e.g. as a
+// pagination boundary. The low bits of the result are zero.
+func (f ID) AddTime(d time.Duration) (ID, error) {
+	ms := d.Milliseconds()
+	ts := f.timestamp()
+	if (ms > 0 && ts > 1<<62-ms) || (ms < 0 && ts < -1<<62-ms) {
+		return 0, ErrTimeOutOfRange
+	}
+	return fromTimestamp(ts + ms)
+}
+
+// Truncate returns the smallest id of the millisecond f's time rounded down
+// to a multiple of d since the unix epoch, like time.Time.Truncate.
+func (f ID) Truncate(d time.Duration) (ID, error) {
+	ms := d.Milliseconds()
+	if ms <= 0 {
+		return fromTimestamp(f.timestamp())
+	}
+	ts := f.timestamp()
+	return fromTimestamp(ts - ts%ms)
+}
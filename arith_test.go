@@ -0,0 +1,33 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIDArithmetic(t *testing.T) {
+	idworker, _ := NewIdWorker(9)
+	id, _ := idworker.NextId()
+
+	next, err := id.AddTime(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.timestamp() != id.timestamp()+int64(time.Hour/time.Millisecond) || next.NodeId() != 0 {
+		t.Errorf("AddTime: got %d", next)
+	}
+	if _, err := id.AddTime(-100 * 365 * 24 * time.Hour); err != ErrTimeOutOfRange {
+		t.Errorf("before epoch: got %v", err)
+	}
+	if _, err := id.AddTime(100 * 365 * 24 * time.Hour); err != ErrTimeOutOfRange {
+		t.Errorf("beyond layout: got %v", err)
+	}
+
+	day, err := id.Truncate(24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day > id || day.timestamp()%int64(24*time.Hour/time.Millisecond) != 0 {
+		t.Errorf("Truncate: got %d for %d", day, id)
+	}
+}
@@ -0,0 +1,27 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSequenceExhausted is returned by NextIdAt when every sequence of the
+// requested millisecond is used.
+var ErrSequenceExhausted = errors.New("snowflake: sequence exhausted for this millisecond")
+
+// NextIdAt get a snowflake id for the caller-provided time t instead of the
+// clock, for deterministic tests and event replay. Times must not go
+// backwards: t before the last timestamp used fails like a clock rollback,
+// and a millisecond with no sequence left fails with ErrSequenceExhausted.
+func (id *IdWorker) NextIdAt(t time.Time) (ID, error) {
+	id.Lock()
+	defer id.Unlock()
+	timestamp := toMillis(t)
+	if id.layout.Micros {
+		timestamp = t.UnixMicro()
+	}
+	if timestamp < id.epochFor(id.districtId) {
+		return 0, ErrTimeOutOfRange
+	}
+	return id.nextidAt(timestamp, false)
+}
@@ -0,0 +1,38 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextIdAt(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	at := time.Unix(1600000000, 0)
+	first, err := idworker.NextIdAt(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Time() != at.Unix() {
+		t.Errorf("time: got %d, want %d", first.Time(), at.Unix())
+	}
+	second, _ := idworker.NextIdAt(at)
+	if second <= first {
+		t.Errorf("same millisecond: %d not after %d", second, first)
+	}
+	if _, err := idworker.NextIdAt(at.Add(-time.Millisecond)); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("backwards: got %v", err)
+	}
+	for i := 0; i < sequenceMask-1; i++ {
+		idworker.NextIdAt(at)
+	}
+	if _, err := idworker.NextIdAt(at); err != ErrSequenceExhausted {
+		t.Errorf("exhausted: got %v", err)
+	}
+	if _, err := idworker.NextIdAt(at.Add(time.Millisecond)); err != nil {
+		t.Errorf("next millisecond: got %v", err)
+	}
+	if _, err := idworker.NextIdAt(time.Unix(0, 0)); err != ErrTimeOutOfRange {
+		t.Errorf("before epoch: got %v", err)
+	}
+}
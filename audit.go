@@ -0,0 +1,86 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditRecord summarises the ids a worker issued during one interval.
+type AuditRecord struct {
+	NodeId int64 `json:"node_id"`
+	Start  int64 `json:"start"` // 区间内第一个 ID 的毫秒
+	End    int64 `json:"end"`   // 区间内最后一个 ID 的毫秒
+	Count  int64 `json:"count"`
+	First  ID    `json:"first"`
+	Last   ID    `json:"last"`
+}
+
+// AuditLog writes one AuditRecord per interval in which its worker issued
+// ids, as JSON lines to an append-only writer, so issued ranges can be
+// reconciled after an incident without logging every id. Use one AuditLog
+// per worker.
+type AuditLog struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	interval int64 // 毫秒
+	bucket   int64
+	cur      AuditRecord
+	err      error
+}
+
+// NewAuditLog new an audit log writing to w every interval; interval is
+// rounded up to one millisecond. w is typically a file opened with
+// os.O_APPEND, or the Writer of a log.Logger.
+func NewAuditLog(w io.Writer, interval time.Duration) *AuditLog {
+	ms := int64(interval / time.Millisecond)
+	if ms < 1 {
+		ms = 1
+	}
+	return &AuditLog{enc: json.NewEncoder(w), interval: ms, bucket: -1}
+}
+
+// WithAuditLog makes the worker record the ids it issues in a.
+func WithAuditLog(a *AuditLog) Option {
+	return func(id *IdWorker) error {
+		id.audit = a
+		return nil
+	}
+}
+
+// record adds n ids, from first to last, issued at timestamp.
+func (a *AuditLog) record(nodeId, timestamp int64, first, last ID, n int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if bucket := timestamp / a.interval; bucket != a.bucket {
+		a.flush()
+		a.bucket = bucket
+		a.cur = AuditRecord{NodeId: nodeId, Start: timestamp, First: first}
+	}
+	a.cur.End = timestamp
+	a.cur.Last = last
+	a.cur.Count += n
+}
+
+// flush writes the current record, if any. The first write error is kept
+// and reported by Flush.
+func (a *AuditLog) flush() {
+	if a.cur.Count == 0 {
+		return
+	}
+	if err := a.enc.Encode(a.cur); err != nil && a.err == nil {
+		a.err = err
+	}
+	a.cur = AuditRecord{}
+}
+
+// Flush writes the record of the current interval, e.g. at shutdown, and
+// returns the first error met while writing records.
+func (a *AuditLog) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.flush()
+	a.bucket = -1
+	return a.err
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewAuditLog(&buf, time.Second)
+	w, _ := NewIdWorker(2, WithAuditLog(a))
+	base := time.UnixMilli(1700000000000)
+	first, _ := w.NextIdAt(base)
+	w.NextIdAt(base.Add(10 * time.Millisecond))
+	last, _ := w.NextIdAt(base.Add(999 * time.Millisecond))
+	next, _ := w.NextIdAt(base.Add(time.Second))
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var got []AuditRecord
+	for dec.More() {
+		var r AuditRecord
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	want := []AuditRecord{
+		{NodeId: 2, Start: 1700000000000, End: 1700000000999, Count: 3, First: first, Last: last},
+		{NodeId: 2, Start: 1700000001000, End: 1700000001000, Count: 1, First: next, Last: next},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
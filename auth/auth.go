@@ -0,0 +1,138 @@
+// Package auth secures the remote generator service: API-key and JWT
+// authentication for HTTP and gRPC, and mutual TLS configuration.
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthenticated is returned for a missing or unknown credential.
+var ErrUnauthenticated = errors.New("auth: unauthenticated")
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Name  string
+	Admin bool // 可调用管理接口
+}
+
+// Authenticator checks a bearer token.
+type Authenticator interface {
+	Authenticate(token string) (Principal, error)
+}
+
+// APIKeys authenticates static API keys.
+type APIKeys struct {
+	keys map[string]Principal
+}
+
+// NewAPIKeys new an empty API key set.
+func NewAPIKeys() *APIKeys {
+	return &APIKeys{keys: make(map[string]Principal)}
+}
+
+// Add registers key for p.
+func (a *APIKeys) Add(key string, p Principal) {
+	a.keys[key] = p
+}
+
+// ReadAPIKeys reads lines of "name key [admin]"; blank lines and lines
+// starting with # are skipped.
+func ReadAPIKeys(r io.Reader) (*APIKeys, error) {
+	a := NewAPIKeys()
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		if len(f) < 2 || len(f) > 3 || (len(f) == 3 && f[2] != "admin") {
+			return nil, errors.New(fmt.Sprintf("auth: line %d: want \"name key [admin]\"", line))
+		}
+		a.Add(f[1], Principal{Name: f[0], Admin: len(f) == 3})
+	}
+	return a, sc.Err()
+}
+
+// Authenticate looks token up, comparing every key in constant time.
+func (a *APIKeys) Authenticate(token string) (Principal, error) {
+	var found Principal
+	ok := 0
+	for key, p := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			found, ok = p, 1
+		}
+	}
+	if ok == 0 {
+		return Principal{}, ErrUnauthenticated
+	}
+	return found, nil
+}
+
+// Chain tries each authenticator in turn.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(token string) (Principal, error) {
+	for _, a := range c {
+		if p, err := a.Authenticate(token); err == nil {
+			return p, nil
+		}
+	}
+	return Principal{}, ErrUnauthenticated
+}
+
+// Token extracts the credential from an Authorization: Bearer header or an
+// X-API-Key header.
+func Token(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimPrefix(h, "Bearer ")
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// HTTP rejects requests that a does not authenticate and stores the
+// principal of the others in the request context.
+func HTTP(a Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(Token(r))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+	})
+}
+
+// RequireAdmin rejects requests whose principal, stored by HTTP, is not an
+// admin.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := FromContext(r.Context()); !ok || !p.Admin {
+			http.Error(w, "auth: admin required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeys(t *testing.T) {
+	keys, err := ReadAPIKeys(strings.NewReader("# comment\nbilling k1\nops k2 admin\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := keys.Authenticate("k2"); err != nil || p.Name != "ops" || !p.Admin {
+		t.Errorf("k2: got %+v, %v", p, err)
+	}
+	if _, err := keys.Authenticate("nope"); err != ErrUnauthenticated {
+		t.Errorf("unknown key: got %v", err)
+	}
+	if _, err := ReadAPIKeys(strings.NewReader("lonely\n")); err == nil {
+		t.Error("malformed line should fail")
+	}
+}
+
+func TestJWT(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	j := &JWT{Secret: []byte("s3cret"), Issuer: "idp", Now: func() time.Time { return now }}
+	tok, _ := j.Sign(map[string]interface{}{"sub": "svc", "iss": "idp", "exp": now.Unix() + 60})
+	if p, err := j.Authenticate(tok); err != nil || p.Name != "svc" || p.Admin {
+		t.Errorf("valid token: got %+v, %v", p, err)
+	}
+	expired, _ := j.Sign(map[string]interface{}{"sub": "svc", "iss": "idp", "exp": now.Unix()})
+	if _, err := j.Authenticate(expired); err == nil {
+		t.Error("expired token should fail")
+	}
+	other := &JWT{Secret: []byte("other")}
+	forged, _ := other.Sign(map[string]interface{}{"sub": "svc", "iss": "idp"})
+	if _, err := j.Authenticate(forged); err == nil {
+		t.Error("token signed with another secret should fail")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	keys := NewAPIKeys()
+	keys.Add("k1", Principal{Name: "billing"})
+	h := HTTP(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := FromContext(r.Context())
+		w.Write([]byte(p.Name))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/next", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("no key: got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/next", nil)
+	req.Header.Set("Authorization", "Bearer k1")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "billing" {
+		t.Errorf("with key: got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	keys := NewAPIKeys()
+	keys.Add("k1", Principal{Name: "billing"})
+	keys.Add("k2", Principal{Name: "ops", Admin: true})
+	h := HTTP(keys, RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	for key, want := range map[string]int{"k1": http.StatusForbidden, "k2": http.StatusOK} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/admin/rotate-node", nil)
+		req.Header.Set("X-API-Key", key)
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: got %d, want %d", key, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BackfillWorker issues ids for historical records, so data migrations get
+// ids whose time component matches the original creation time.
+//
+// The node ID of a BackfillWorker must be reserved for backfilling: no live
+// IdWorker may use it, otherwise a backfilled id could collide with one
+// issued for the same millisecond in the past.
+//
+// Only the current millisecond is tracked, so records must be backfilled in
+// time order; a timestamp before the last one issued is rejected.
+type BackfillWorker struct {
+	sync.Mutex
+	worker        *IdWorker
+	start         int64 // 起始毫秒(含)
+	end           int64 // 结束毫秒(不含)
+	lastTimestamp int64 // 上次发号的毫秒
+	sequence      int64 // lastTimestamp 内下一个序号
+}
+
+// NewBackfillWorker new a backfill worker issuing ids for [start, end).
+func NewBackfillWorker(NodeId int64, start, end time.Time, opts ...Option) (*BackfillWorker, error) {
+	worker, err := NewIdWorker(NodeId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if worker.layout.Micros {
+		return nil, errors.New("backfill does not support microsecond layouts")
+	}
+	s, e := toMillis(start), toMillis(end)
+	if s < worker.epoch() {
+		return nil, errors.New("backfill start is before the epoch")
+	}
+	if e <= s {
+		return nil, errors.New("backfill end must be after start")
+	}
+	if e > timeGen() {
+		return nil, errors.New("backfill end is in the future")
+	}
+	return &BackfillWorker{
+		worker: worker,
+		start:  s,
+		end:    e,
+	}, nil
+}
+
+// NextIdAt get a snowflake id whose time component is t.
+func (b *BackfillWorker) NextIdAt(t time.Time) (ID, error) {
+	timestamp := toMillis(t)
+	if timestamp < b.start || timestamp >= b.end {
+		return 0, errors.New(fmt.Sprintf("timestamp %d out of backfill range [%d, %d)", timestamp, b.start, b.end))
+	}
+	b.Lock()
+	defer b.Unlock()
+	if timestamp < b.lastTimestamp {
+		return 0, errors.New(fmt.Sprintf("timestamp %d is before the last backfilled timestamp %d", timestamp, b.lastTimestamp))
+	}
+	if timestamp > b.lastTimestamp {
+		b.lastTimestamp = timestamp
+		b.sequence = 0
+	}
+	if b.sequence > b.worker.layout.MaxSequence() {
+		return 0, errors.New(fmt.Sprintf("sequence exhausted for timestamp %d", timestamp))
+	}
+	b.sequence++
+	return b.worker.pack(timestamp, b.sequence-1), nil
+}
+
+// Resume continues a backfill that was interrupted after issuing last, so
+// ids issued later in the same millisecond do not reuse its sequence.
+func (b *BackfillWorker) Resume(last ID) error {
+	v := last.WithLayout(b.worker.currentLayout())
+	if v.NodeId() != b.worker.nodeId {
+		return errors.New(fmt.Sprintf("id %d was not issued by backfill node %d", last, b.worker.nodeId))
+	}
+	b.Lock()
+	defer b.Unlock()
+	if t := v.Timestamp(); t > b.lastTimestamp {
+		b.lastTimestamp, b.sequence = t, v.Sequence()+1
+	} else if t == b.lastTimestamp && v.Sequence() >= b.sequence {
+		b.sequence = v.Sequence() + 1
+	}
+	return nil
+}
+
+// toMillis convert t to a unix millisecond.
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
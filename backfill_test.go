@@ -0,0 +1,74 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackfillWorker(t *testing.T) {
+	end := time.Now().Add(-time.Hour)
+	start := end.Add(-24 * time.Hour)
+	b, err := NewBackfillWorker(maxNodeId, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := start.Add(time.Hour)
+	first, err := b.NextIdAt(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := b.NextIdAt(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("duplicate id for the same timestamp")
+	}
+	if first.Time() != at.Unix() {
+		t.Errorf("time: got %d, want %d", first.Time(), at.Unix())
+	}
+	if _, err := b.NextIdAt(end); err == nil {
+		t.Error("timestamp at end should be rejected")
+	}
+}
+
+func TestBackfillWorkerFail(t *testing.T) {
+	if _, err := NewBackfillWorker(1, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)); err == nil {
+		t.Error("future range should be rejected")
+	}
+}
+
+func TestBackfillWorkerResume(t *testing.T) {
+	at := time.Now().Add(-time.Hour)
+	first, _ := NewBackfillWorker(500, at.Add(-time.Minute), at.Add(time.Minute))
+	last, _ := first.NextIdAt(at)
+
+	resumed, _ := NewBackfillWorker(500, at.Add(-time.Minute), at.Add(time.Minute))
+	if err := resumed.Resume(last); err != nil {
+		t.Fatal(err)
+	}
+	if next, _ := resumed.NextIdAt(at); next != last+1 {
+		t.Errorf("got %d, want %d", next, last+1)
+	}
+	other, _ := NewIdWorker(3)
+	id, _ := other.NextId()
+	if err := resumed.Resume(id); err == nil {
+		t.Error("resuming after another node's id should fail")
+	}
+}
+
+func TestBackfillWorkerOrder(t *testing.T) {
+	at := time.Now().Add(-time.Hour)
+	b, _ := NewBackfillWorker(500, at.Add(-time.Minute), at.Add(time.Minute))
+	first, _ := b.NextIdAt(at)
+	later, err := b.NextIdAt(at.Add(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if later.WithLayout(DefaultLayout).Sequence() != 0 || later <= first {
+		t.Errorf("new millisecond: got %d after %d", later, first)
+	}
+	if _, err := b.NextIdAt(at); err == nil {
+		t.Error("timestamp before the last one should be rejected")
+	}
+}
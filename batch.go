@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"fmt"
+)
+
+// BatchError reports a batch that failed part way. The ids issued before
+// the failure are valid and unique, and callers may use them.
+type BatchError struct {
+	IDs   []ID  // 失败前已生成的 ID
+	Index int   // 失败的位置, 即 len(IDs)
+	Err   error // 失败原因
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch failed at id %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// WithFairBatching makes NextIds release the lock while it waits for the
+// next millisecond, so single-id callers are served between the
+// milliseconds of a bulk request instead of queueing behind all of it.
+func WithFairBatching() Option {
+	return func(id *IdWorker) error {
+		id.fairBatch = true
+		return nil
+	}
+}
+
+// yieldIfExhausted waits for the next millisecond without holding the lock
+// when the current one has no sequence left. Called with the lock held.
+func (id *IdWorker) yieldIfExhausted() {
+	if _, ok := id.clock.(*counterClock); ok {
+		return // 计数器只随发放推进, 由 nextid 处理
+	}
+	for id.nextSequence() < 0 && id.lastTimestamp >= id.clock.Millis() {
+		if id.awaitTick(id.lastTimestamp) {
+			continue
+		}
+		last, clock := id.lastTimestamp, id.clock
+		id.Unlock()
+		tilNextMillis(clock, last)
+		id.Lock()
+	}
+}
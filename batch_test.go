@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFairBatching(t *testing.T) {
+	idworker, _ := NewIdWorker(1, WithFairBatching())
+	var (
+		mu   sync.Mutex
+		seen = make(map[ID]bool)
+		wg   sync.WaitGroup
+	)
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 30; i++ {
+				ids, err := idworker.NextIds(maxNextIdsNum)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				single, _ := idworker.NextId()
+				mu.Lock()
+				for _, id := range append(ids, single) {
+					if seen[id] {
+						t.Errorf("duplicate id %d", id)
+					}
+					seen[id] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// stepClock returns base for its first n reads and base-10 afterwards.
+type stepClock struct {
+	base, n, calls int64
+}
+
+func (c *stepClock) Millis() int64 {
+	c.calls++
+	if c.calls > c.n {
+		return c.base - 10
+	}
+	return c.base
+}
+
+func TestBatchError(t *testing.T) {
+	idworker, _ := NewIdWorker(1, WithClock(&stepClock{base: timeGen(), n: 5}))
+	ids, err := idworker.NextIds(10)
+	var be *BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("want a BatchError, got %v", err)
+	}
+	if be.Index != 5 || len(be.IDs) != 5 || len(ids) != 5 {
+		t.Fatalf("failed at %d with %d ids, returned %d", be.Index, len(be.IDs), len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Errorf("ids out of order: %v", ids)
+		}
+	}
+	if !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("cause should be a clock rollback, got %v", be.Err)
+	}
+}
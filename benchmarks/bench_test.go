@@ -0,0 +1,108 @@
+package benchmarks
+
+import (
+	"testing"
+
+	bwmarrin "github.com/bwmarrin/snowflake"
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sony/sonyflake"
+)
+
+func BenchmarkSnowflake(b *testing.B) {
+	worker, err := snowflake.NewIdWorker(1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := worker.NextId(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSnowflakeBatch(b *testing.B) {
+	worker, err := snowflake.NewIdWorker(1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i += 100 {
+		if _, err := worker.NextIds(100); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSnowflakeParallel(b *testing.B) {
+	worker, err := snowflake.NewIdWorker(1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := worker.NextId(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkBwmarrin(b *testing.B) {
+	node, err := bwmarrin.NewNode(1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		node.Generate()
+	}
+}
+
+func BenchmarkBwmarrinParallel(b *testing.B) {
+	node, err := bwmarrin.NewNode(1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			node.Generate()
+		}
+	})
+}
+
+func newSonyflake(b *testing.B) *sonyflake.Sonyflake {
+	sf := sonyflake.NewSonyflake(sonyflake.Settings{
+		MachineID: func() (uint16, error) { return 1, nil },
+	})
+	if sf == nil {
+		b.Fatal("sonyflake: invalid settings")
+	}
+	return sf
+}
+
+func BenchmarkSonyflake(b *testing.B) {
+	sf := newSonyflake(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := sf.NextID(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSonyflakeParallel(b *testing.B) {
+	sf := newSonyflake(b)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := sf.NextID(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
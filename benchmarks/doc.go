@@ -0,0 +1,10 @@
+// Package benchmarks compares the id generators of this module with
+// github.com/bwmarrin/snowflake and github.com/sony/sonyflake on the same
+// machine. It only holds benchmarks; run them with
+//
+//	go test -run '^$' -bench . -benchmem ./benchmarks
+//
+// and compare runs across commits with benchstat. Keep in mind that each
+// library caps its own throughput: sonyflake issues 256 ids per 10ms per
+// machine, so its serial numbers mostly measure that limit, not overhead.
+package benchmarks
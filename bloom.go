@@ -0,0 +1,99 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// BloomFilter answers "seen this id?" for large id sets in a fixed amount
+// of memory, with false positives at about the rate it was sized for and
+// no false negatives. It is not safe for concurrent use.
+type BloomFilter struct {
+	bits []uint64
+	m    uint64 // 位数
+	k    uint64 // 哈希函数个数
+}
+
+const bloomMagic = "SFBF1"
+
+// NewBloomFilter new a filter sized for n ids with false positive rate p.
+func NewBloomFilter(n int, p float64) (*BloomFilter, error) {
+	if n <= 0 || p <= 0 || p >= 1 {
+		return nil, errors.New("bloom filter needs n > 0 and 0 < p < 1")
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	return &BloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}, nil
+}
+
+// mix64 scrambles the structured bits of an id (splitmix64 finalizer). ID.Shard
+// depends on it: it must never change.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// positions calls fn with the k bit positions of f, by double hashing.
+func (b *BloomFilter) positions(f ID, fn func(pos uint64) bool) bool {
+	h1 := mix64(uint64(f))
+	h2 := mix64(h1) | 1
+	for i := uint64(0); i < b.k; i++ {
+		if !fn((h1 + i*h2) % b.m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add records f.
+func (b *BloomFilter) Add(f ID) {
+	b.positions(f, func(pos uint64) bool {
+		b.bits[pos/64] |= 1 << (pos % 64)
+		return true
+	})
+}
+
+// Contains reports whether f may have been added; false means it never was.
+func (b *BloomFilter) Contains(f ID) bool {
+	return b.positions(f, func(pos uint64) bool {
+		return b.bits[pos/64]&(1<<(pos%64)) != 0
+	})
+}
+
+// MarshalBinary encodes the filter, to be stored or shipped to other jobs.
+func (b *BloomFilter) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, len(bloomMagic)+16+8*len(b.bits))
+	out = append(out, bloomMagic...)
+	out = binary.BigEndian.AppendUint64(out, b.m)
+	out = binary.BigEndian.AppendUint64(out, b.k)
+	for _, w := range b.bits {
+		out = binary.BigEndian.AppendUint64(out, w)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes a filter encoded by MarshalBinary.
+func (b *BloomFilter) UnmarshalBinary(data []byte) error {
+	head := len(bloomMagic) + 16
+	if len(data) < head || string(data[:len(bloomMagic)]) != bloomMagic {
+		return errors.New("not a snowflake bloom filter")
+	}
+	m := binary.BigEndian.Uint64(data[len(bloomMagic):])
+	k := binary.BigEndian.Uint64(data[len(bloomMagic)+8:])
+	words := (m + 63) / 64
+	if m == 0 || k == 0 || k > 64 || uint64(len(data)-head) != 8*words {
+		return errors.New(fmt.Sprintf("corrupt bloom filter of %d bits and %d hashes in %d bytes", m, k, len(data)))
+	}
+	bits := make([]uint64, words)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint64(data[head+8*i:])
+	}
+	b.bits, b.m, b.k = bits, m, k
+	return nil
+}
@@ -0,0 +1,55 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n = 10000
+	b, err := NewBloomFilter(n, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idworker, _ := NewIdWorker(1)
+	added := make([]ID, 0, n)
+	for len(added) < n {
+		ids, _ := idworker.NextIds(100)
+		for _, id := range ids {
+			b.Add(id)
+		}
+		added = append(added, ids...)
+	}
+	for _, id := range added {
+		if !b.Contains(id) {
+			t.Fatalf("false negative for %d", id)
+		}
+	}
+	// 之后生成的 ID 未加入, 误判率应接近 1%
+	falsePositives := 0
+	for i := 0; i < n/100; i++ {
+		ids, _ := idworker.NextIds(100)
+		for _, id := range ids {
+			if b.Contains(id) {
+				falsePositives++
+			}
+		}
+	}
+	if rate := float64(falsePositives) / n; rate > 0.02 {
+		t.Errorf("false positive rate %.3f", rate)
+	}
+
+	data, _ := b.MarshalBinary()
+	var c BloomFilter
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Contains(added[0]) || !c.Contains(added[n-1]) {
+		t.Error("decoded filter lost ids")
+	}
+	if err := c.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("truncated filter should fail")
+	}
+	if _, err := NewBloomFilter(0, 0.01); err == nil {
+		t.Error("empty filter should fail")
+	}
+}
@@ -0,0 +1,263 @@
+// Package bwcompat offers the Node and ID API of github.com/bwmarrin/snowflake
+// backed by an IdWorker, so code written against that package migrates by
+// changing its import:
+//
+//	import snowflake "github.com/sakishum/go_snowflake/bwcompat"
+//
+// Ids keep bwmarrin's layout (41 bits of milliseconds since Epoch, NodeBits
+// of node and StepBits of step) and encodings, so ids issued before and
+// after the switch decode and sort alike.
+package bwcompat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+var (
+	// Epoch is the Twitter snowflake epoch of Nov 04 2010 01:42:54 UTC in
+	// milliseconds. Change it, NodeBits and StepBits before NewNode.
+	Epoch int64 = 1288834974657
+
+	NodeBits uint8 = 10 // 节点所占位数
+	StepBits uint8 = 12 // 毫秒内序号所占位数
+)
+
+var (
+	// ErrInvalidBase58 is returned by ParseBase58 for an invalid id.
+	ErrInvalidBase58 = errors.New("invalid base58")
+	// ErrInvalidBase32 is returned by ParseBase32 for an invalid id.
+	ErrInvalidBase32 = errors.New("invalid base32")
+)
+
+var (
+	base32 = mustAlphabet("ybndrfg8ejkmcpqxot1uwisza345h769")
+	base58 = mustAlphabet("123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ")
+)
+
+func mustAlphabet(alphabet string) *snowflake.AlphabetEncoder {
+	e, err := snowflake.NewAlphabetEncoder(alphabet)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Node generates ids for one node number.
+type Node struct {
+	worker *snowflake.IdWorker
+}
+
+// ID is a snowflake id in bwmarrin's layout.
+type ID int64
+
+// monoClock counts milliseconds since Epoch on the monotonic clock, as
+// bwmarrin does, so a wall clock step back never stalls Generate.
+type monoClock struct {
+	start time.Time
+	epoch int64
+}
+
+func (c monoClock) Millis() int64 {
+	return c.epoch + time.Since(c.start).Milliseconds()
+}
+
+// Layout returns the layout of the ids of NewNode for the current Epoch,
+// NodeBits and StepBits.
+func Layout() snowflake.Layout {
+	return snowflake.Layout{
+		TimestampBits: 63 - uint(NodeBits) - uint(StepBits),
+		NodeBits:      uint(NodeBits),
+		SequenceBits:  uint(StepBits),
+		Epoch:         Epoch,
+	}
+}
+
+// NewNode returns a Node generating ids for node.
+func NewNode(node int64) (*Node, error) {
+	if NodeBits+StepBits > 22 {
+		return nil, errors.New("Remember, you have a total 22 bits to share between Node/Step")
+	}
+	l := Layout()
+	if node < 0 || node > l.MaxNodeId() {
+		return nil, errors.New("Node number must be between 0 and " + strconv.FormatInt(l.MaxNodeId(), 10))
+	}
+	now := time.Now()
+	worker, err := snowflake.NewIdWorker(node,
+		snowflake.WithDistrictId(0),
+		snowflake.WithLayout(l),
+		snowflake.WithClock(monoClock{start: now, epoch: now.UnixMilli()}))
+	if err != nil {
+		return nil, err
+	}
+	return &Node{worker: worker}, nil
+}
+
+// Generate returns a new id.
+func (n *Node) Generate() ID {
+	f, err := n.worker.NextId()
+	if err != nil {
+		// 单调时钟不会回拨, 只有冻结等显式操作才会失败
+		panic(err)
+	}
+	return ID(f)
+}
+
+// Int64 returns the id as an int64.
+func (f ID) Int64() int64 {
+	return int64(f)
+}
+
+// String returns the decimal of the id.
+func (f ID) String() string {
+	return strconv.FormatInt(int64(f), 10)
+}
+
+// Base2 returns the binary of the id.
+func (f ID) Base2() string {
+	return strconv.FormatInt(int64(f), 2)
+}
+
+// Base32 returns the z-base-32 of the id.
+func (f ID) Base32() string {
+	return base32.Encode(snowflake.ID(f))
+}
+
+// Base36 returns the base 36 of the id.
+func (f ID) Base36() string {
+	return strconv.FormatInt(int64(f), 36)
+}
+
+// Base58 returns the Flickr base58 of the id.
+func (f ID) Base58() string {
+	return base58.Encode(snowflake.ID(f))
+}
+
+// Base64 returns the base64 of the decimal of the id.
+func (f ID) Base64() string {
+	return base64.StdEncoding.EncodeToString(f.Bytes())
+}
+
+// Bytes returns the decimal of the id.
+func (f ID) Bytes() []byte {
+	return []byte(f.String())
+}
+
+// IntBytes returns the 8 bytes of the id, most significant first.
+func (f ID) IntBytes() [8]byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(f))
+	return b
+}
+
+// Time returns the unix millisecond of the id.
+func (f ID) Time() int64 {
+	return int64(f)>>(NodeBits+StepBits) + Epoch
+}
+
+// Node returns the node number of the id.
+func (f ID) Node() int64 {
+	return int64(f) >> StepBits & (-1 ^ (-1 << NodeBits))
+}
+
+// Step returns the sequence of the id within its millisecond.
+func (f ID) Step() int64 {
+	return int64(f) & (-1 ^ (-1 << StepBits))
+}
+
+// MarshalJSON encodes the id as a JSON string.
+func (f ID) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 22)
+	b = append(b, '"')
+	b = strconv.AppendInt(b, int64(f), 10)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON decodes an id from a JSON string.
+func (f *ID) UnmarshalJSON(b []byte) error {
+	if len(b) < 3 || b[0] != '"' || b[len(b)-1] != '"' {
+		return JSONSyntaxError{b}
+	}
+	i, err := strconv.ParseInt(string(b[1:len(b)-1]), 10, 64)
+	if err != nil {
+		return err
+	}
+	*f = ID(i)
+	return nil
+}
+
+// JSONSyntaxError is returned by UnmarshalJSON for an id that is not a
+// JSON string.
+type JSONSyntaxError struct{ original []byte }
+
+func (j JSONSyntaxError) Error() string {
+	return fmt.Sprintf("invalid snowflake ID %q", string(j.original))
+}
+
+// ParseInt64 converts an int64 into an id.
+func ParseInt64(id int64) ID {
+	return ID(id)
+}
+
+// ParseString parses a decimal id.
+func ParseString(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 10, 64)
+	return ID(i), err
+}
+
+// ParseBase2 parses an id written by Base2.
+func ParseBase2(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 2, 64)
+	return ID(i), err
+}
+
+// ParseBase32 parses an id written by Base32.
+func ParseBase32(b []byte) (ID, error) {
+	f, err := base32.Decode(string(b))
+	if err != nil {
+		return -1, ErrInvalidBase32
+	}
+	return ID(f), nil
+}
+
+// ParseBase36 parses an id written by Base36.
+func ParseBase36(id string) (ID, error) {
+	i, err := strconv.ParseInt(id, 36, 64)
+	return ID(i), err
+}
+
+// ParseBase58 parses an id written by Base58.
+func ParseBase58(b []byte) (ID, error) {
+	f, err := base58.Decode(string(b))
+	if err != nil {
+		return -1, ErrInvalidBase58
+	}
+	return ID(f), nil
+}
+
+// ParseBase64 parses an id written by Base64.
+func ParseBase64(id string) (ID, error) {
+	b, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return -1, err
+	}
+	return ParseBytes(b)
+}
+
+// ParseBytes parses an id written by Bytes.
+func ParseBytes(id []byte) (ID, error) {
+	i, err := strconv.ParseInt(string(bytes.TrimSpace(id)), 10, 64)
+	return ID(i), err
+}
+
+// ParseIntBytes parses an id written by IntBytes.
+func ParseIntBytes(id [8]byte) ID {
+	return ID(int64(binary.BigEndian.Uint64(id[:])))
+}
@@ -0,0 +1,67 @@
+package bwcompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	bwmarrin "github.com/bwmarrin/snowflake"
+)
+
+func TestMatchesBwmarrin(t *testing.T) {
+	node, err := NewNode(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, _ := bwmarrin.NewNode(7)
+	want := theirs.Generate()
+	got := node.Generate()
+	if got.Node() != want.Node() || got.Time()-want.Time() > 50 || got <= 0 {
+		t.Errorf("got node %d time %d, bwmarrin node %d time %d", got.Node(), got.Time(), want.Node(), want.Time())
+	}
+	if next := node.Generate(); next <= got {
+		t.Errorf("ids not increasing: %d after %d", next, got)
+	}
+
+	b := bwmarrin.ID(got)
+	for _, c := range []struct{ name, got, want string }{
+		{"Base2", got.Base2(), b.Base2()},
+		{"Base32", got.Base32(), b.Base32()},
+		{"Base36", got.Base36(), b.Base36()},
+		{"Base58", got.Base58(), b.Base58()},
+		{"Base64", got.Base64(), b.Base64()},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s: got %q, bwmarrin %q", c.name, c.got, c.want)
+		}
+	}
+	if got.Step() != b.Step() || got.Time() != b.Time() {
+		t.Errorf("step %d time %d, bwmarrin step %d time %d", got.Step(), got.Time(), b.Step(), b.Time())
+	}
+
+	if f, err := ParseBase58([]byte(b.Base58())); err != nil || f != got {
+		t.Errorf("ParseBase58: got %d, %v", f, err)
+	}
+	if f, err := ParseBase32([]byte(b.Base32())); err != nil || f != got {
+		t.Errorf("ParseBase32: got %d, %v", f, err)
+	}
+	if _, err := ParseBase58([]byte("0")); err != ErrInvalidBase58 {
+		t.Errorf("ParseBase58: got %v, want ErrInvalidBase58", err)
+	}
+
+	mine, _ := json.Marshal(got)
+	their, _ := json.Marshal(b)
+	if string(mine) != string(their) {
+		t.Errorf("json: got %s, bwmarrin %s", mine, their)
+	}
+	var back ID
+	if err := json.Unmarshal(their, &back); err != nil || back != got {
+		t.Errorf("UnmarshalJSON: got %d, %v", back, err)
+	}
+	if err := json.Unmarshal([]byte("1"), &back); err == nil {
+		t.Error("unquoted id should not unmarshal")
+	}
+
+	if _, err := NewNode(1024); err == nil {
+		t.Error("node out of range should fail")
+	}
+}
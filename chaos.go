@@ -0,0 +1,117 @@
+package snowflake
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ChaosClock is a Clock for integration tests that can jump forwards or
+// backwards and freeze, so a setup's drift tolerance (RetryPolicy, stores,
+// standbys) can be exercised before production.
+type ChaosClock struct {
+	mu       sync.Mutex
+	base     Clock
+	offset   int64 // 毫秒
+	frozen   bool
+	frozenAt int64
+}
+
+// NewChaosClock new a chaos clock following base, or the system clock when
+// base is nil.
+func NewChaosClock(base Clock) *ChaosClock {
+	if base == nil {
+		base = systemClock{}
+	}
+	return &ChaosClock{base: base}
+}
+
+// Millis returns the current unix millisecond as skewed by the chaos.
+func (c *ChaosClock) Millis() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frozen {
+		return c.frozenAt
+	}
+	return c.base.Millis() + c.offset
+}
+
+// Jump moves the clock by d, backwards when d is negative.
+func (c *ChaosClock) Jump(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ms := int64(d / time.Millisecond)
+	c.offset += ms
+	c.frozenAt += ms
+}
+
+// Freeze stops the clock at its current time until Unfreeze.
+func (c *ChaosClock) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.frozen {
+		c.frozenAt = c.base.Millis() + c.offset
+		c.frozen = true
+	}
+}
+
+// Unfreeze lets the clock follow its base again, offset included.
+func (c *ChaosClock) Unfreeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frozen = false
+}
+
+// ChaosStep is one event of a ChaosScenario, At after the scenario starts.
+type ChaosStep struct {
+	At       time.Duration
+	Jump     time.Duration
+	Freeze   bool
+	Unfreeze bool
+}
+
+// ChaosScenario is a timed list of clock events.
+type ChaosScenario []ChaosStep
+
+// Ready-made scenarios.
+var (
+	// ChaosSmallRewind steps back 5ms, like an NTP slew correction.
+	ChaosSmallRewind = ChaosScenario{{At: 10 * time.Millisecond, Jump: -5 * time.Millisecond}}
+	// ChaosStepBack steps back a full second, like an NTP step.
+	ChaosStepBack = ChaosScenario{{At: 10 * time.Millisecond, Jump: -time.Second}}
+	// ChaosForwardJump jumps an hour ahead, like a misconfigured host.
+	ChaosForwardJump = ChaosScenario{{At: 10 * time.Millisecond, Jump: time.Hour}}
+	// ChaosFreeze stops time for 50ms, like a paused VM.
+	ChaosFreeze = ChaosScenario{{At: 10 * time.Millisecond, Freeze: true}, {At: 60 * time.Millisecond, Unfreeze: true}}
+	// ChaosFlapping goes back and forth by 3ms five times.
+	ChaosFlapping = ChaosScenario{
+		{At: 10 * time.Millisecond, Jump: -3 * time.Millisecond}, {At: 15 * time.Millisecond, Jump: 3 * time.Millisecond},
+		{At: 20 * time.Millisecond, Jump: -3 * time.Millisecond}, {At: 25 * time.Millisecond, Jump: 3 * time.Millisecond},
+		{At: 30 * time.Millisecond, Jump: -3 * time.Millisecond}, {At: 35 * time.Millisecond, Jump: 3 * time.Millisecond},
+		{At: 40 * time.Millisecond, Jump: -3 * time.Millisecond}, {At: 45 * time.Millisecond, Jump: 3 * time.Millisecond},
+		{At: 50 * time.Millisecond, Jump: -3 * time.Millisecond}, {At: 55 * time.Millisecond, Jump: 3 * time.Millisecond},
+	}
+)
+
+// Run plays the scenario on c, blocking until its last step or until ctx
+// is done.
+func (s ChaosScenario) Run(ctx context.Context, c *ChaosClock) error {
+	start := time.Now()
+	for _, step := range s {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(step.At - time.Since(start)):
+		}
+		if step.Freeze {
+			c.Freeze()
+		}
+		if step.Jump != 0 {
+			c.Jump(step.Jump)
+		}
+		if step.Unfreeze {
+			c.Unfreeze()
+		}
+	}
+	return nil
+}
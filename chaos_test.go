@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChaosClock(t *testing.T) {
+	c := NewChaosClock(nil)
+	before := c.Millis()
+	c.Jump(-time.Second)
+	if d := before - c.Millis(); d < 990 || d > 1010 {
+		t.Errorf("jump back: moved %dms", d)
+	}
+	c.Freeze()
+	frozen := c.Millis()
+	time.Sleep(5 * time.Millisecond)
+	if c.Millis() != frozen {
+		t.Error("frozen clock moved")
+	}
+	c.Unfreeze()
+	time.Sleep(2 * time.Millisecond)
+	if c.Millis() <= frozen {
+		t.Error("unfrozen clock did not move")
+	}
+}
+
+func TestChaosScenarios(t *testing.T) {
+	for name, s := range map[string]ChaosScenario{
+		"small rewind": ChaosSmallRewind,
+		"freeze":       ChaosFreeze,
+		"flapping":     ChaosFlapping,
+	} {
+		c := NewChaosClock(nil)
+		w, _ := NewIdWorker(1, WithClock(c))
+		done := make(chan error, 1)
+		go func() { done <- s.Run(context.Background(), c) }()
+
+		policy := RetryPolicy{MaxAttempts: 20, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+		seen := make(map[ID]bool)
+		var prev ID
+		deadline := time.Now().Add(80 * time.Millisecond)
+		for time.Now().Before(deadline) {
+			id, err := RetryNextId(context.Background(), w, policy)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if seen[id] || id <= prev {
+				t.Fatalf("%s: id %d after %d", name, id, prev)
+			}
+			seen[id], prev = true, id
+		}
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewChaosClock(nil)
+	w, _ := NewIdWorker(1, WithClock(c))
+	w.NextId()
+	ChaosStepBack.Run(context.Background(), c)
+	if _, err := w.NextId(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("step back: got %v", err)
+	}
+}
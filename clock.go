@@ -0,0 +1,84 @@
+package snowflake
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the time source of a worker, in unix milliseconds.
+type Clock interface {
+	Millis() int64
+}
+
+type systemClock struct{}
+
+func (systemClock) Millis() int64 {
+	return timeGen()
+}
+
+// WithClock makes the worker read time from clock.
+func WithClock(clock Clock) Option {
+	return func(id *IdWorker) error {
+		id.clock = clock
+		return nil
+	}
+}
+
+// CoarseClock caches the current millisecond in an atomic that a ticker
+// goroutine refreshes, so workers issuing millions of ids per second do not
+// read the system clock on every id. Millis never goes backwards and lags
+// the system clock by at most one tick (plus scheduling delay).
+type CoarseClock struct {
+	now  int64 // 缓存的毫秒, 原子读写
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewCoarseClock starts a coarse clock refreshed every interval; interval
+// is rounded up to one millisecond.
+func NewCoarseClock(interval time.Duration) *CoarseClock {
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	c := &CoarseClock{stop: make(chan struct{})}
+	c.calibrate()
+	go c.run(interval)
+	return c
+}
+
+func (c *CoarseClock) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.calibrate()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// calibrate sets the cached millisecond from the system clock, never moving
+// it backwards, so a wall clock step back is absorbed instead of surfacing
+// as a tiny rollback between two ticks.
+func (c *CoarseClock) calibrate() {
+	now := timeGen()
+	for {
+		cur := atomic.LoadInt64(&c.now)
+		if now <= cur || atomic.CompareAndSwapInt64(&c.now, cur, now) {
+			return
+		}
+	}
+}
+
+// Millis returns the cached unix millisecond.
+func (c *CoarseClock) Millis() int64 {
+	return atomic.LoadInt64(&c.now)
+}
+
+// Stop stops the ticker goroutine; Millis keeps returning the last value.
+func (c *CoarseClock) Stop() {
+	c.once.Do(func() { close(c.stop) })
+}
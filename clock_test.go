@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoarseClock(t *testing.T) {
+	c := NewCoarseClock(time.Millisecond)
+	defer c.Stop()
+	before := timeGen()
+	if now := c.Millis(); now < before-1 || now > timeGen() {
+		t.Errorf("coarse clock %d out of [%d, %d]", now, before-1, timeGen())
+	}
+	last := c.Millis()
+	deadline := time.Now().Add(time.Second)
+	for c.Millis() == last {
+		if time.Now().After(deadline) {
+			t.Fatal("coarse clock did not tick")
+		}
+	}
+}
+
+func TestCoarseClockMonotonic(t *testing.T) {
+	c := &CoarseClock{now: timeGen() + 1000, stop: make(chan struct{})}
+	last := c.Millis()
+	c.calibrate()
+	if c.Millis() != last {
+		t.Errorf("calibrate moved clock backwards: %d -> %d", last, c.Millis())
+	}
+}
+
+// TestCoarseClockTickBoundary exhausts the sequence within one cached
+// millisecond, so the worker has to wait for the next tick.
+func TestCoarseClockTickBoundary(t *testing.T) {
+	c := NewCoarseClock(time.Millisecond)
+	defer c.Stop()
+	idworker, _ := NewIdWorker(1, WithClock(c))
+	seen := make(map[ID]bool)
+	var last ID
+	for i := 0; i < 4*(sequenceMask+1); i++ {
+		id, err := idworker.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] || id <= last {
+			t.Fatalf("id %d not unique and increasing after %d", id, last)
+		}
+		seen[id] = true
+		last = id
+	}
+}
+
+func BenchmarkSystemClock(b *testing.B) {
+	var c systemClock
+	for i := 0; i < b.N; i++ {
+		c.Millis()
+	}
+}
+
+func BenchmarkCoarseClock(b *testing.B) {
+	c := NewCoarseClock(time.Millisecond)
+	defer c.Stop()
+	for i := 0; i < b.N; i++ {
+		c.Millis()
+	}
+}
@@ -0,0 +1,52 @@
+// Command snowflake-sim simulates a fleet of generators to help choose a
+// layout: it reports how often the sequence space of a millisecond runs
+// out, how many ids collide when clocks roll back or node ids are shared,
+// and how many years are left until the timestamp field overflows.
+//
+//	snowflake-sim -nodes 64 -qps 200000 -skew 50ms
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func main() {
+	layout := snowflake.DefaultLayout
+	nodes := flag.Int("nodes", 16, "number of generator nodes")
+	qps := flag.Float64("qps", 10000, "ids per second per node")
+	skew := flag.Duration("skew", 10*time.Millisecond, "size of a clock rollback replayed after a restart")
+	duration := flag.Duration("duration", 10*time.Second, "simulated time")
+	seed := flag.Int64("seed", 1, "random seed")
+	flag.UintVar(&layout.TimestampBits, "timestamp-bits", layout.TimestampBits, "timestamp bits")
+	flag.UintVar(&layout.TagBits, "tag-bits", layout.TagBits, "tag bits")
+	flag.UintVar(&layout.DistrictBits, "district-bits", layout.DistrictBits, "district bits")
+	flag.UintVar(&layout.NodeBits, "node-bits", layout.NodeBits, "node bits")
+	flag.UintVar(&layout.SequenceBits, "sequence-bits", layout.SequenceBits, "sequence bits")
+	flag.Int64Var(&layout.Epoch, "epoch", layout.Epoch, "epoch in unix milliseconds")
+	flag.Parse()
+
+	if err := layout.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if int64(*nodes) > layout.MaxNodeId()+1 {
+		fmt.Fprintf(os.Stderr, "%d nodes do not fit in %d node bits\n", *nodes, layout.NodeBits)
+		os.Exit(2)
+	}
+
+	sim := &Sim{
+		Layout:   layout,
+		Nodes:    *nodes,
+		QPS:      *qps,
+		Skew:     *skew,
+		Duration: *duration,
+		Rand:     rand.New(rand.NewSource(*seed)),
+	}
+	sim.Run().Print(os.Stdout)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Sim simulates Nodes generators issuing ids at QPS each.
+type Sim struct {
+	Layout   snowflake.Layout
+	Nodes    int
+	QPS      float64
+	Skew     time.Duration
+	Duration time.Duration
+	Rand     *rand.Rand
+}
+
+// Report is the outcome of a simulation.
+type Report struct {
+	Layout           snowflake.Layout
+	Millis           int64   // 模拟的节点毫秒数
+	ExhaustedMillis  int64   // 序号用尽的节点毫秒数
+	ExhaustedProb    float64 // 单个节点毫秒序号用尽的概率
+	DelayedIds       int64   // 需要等待下一毫秒的 ID 数
+	RollbackCollides float64 // 一次回拨后重启, 重放窗口内的期望重复 ID 数
+	SharedNodeRate   float64 // 两个节点误用同一节点 ID 时, 每秒的期望重复 ID 数
+	OverflowAt       time.Time
+	YearsLeft        float64
+}
+
+// Run runs the simulation.
+func (s *Sim) Run() Report {
+	r := Report{Layout: s.Layout}
+	lambda := s.QPS / 1000 // 每毫秒到达数
+	capacity := s.Layout.MaxSequence() + 1
+
+	millis := s.Duration.Milliseconds()
+	for n := 0; n < s.Nodes; n++ {
+		var backlog int64 // 上一毫秒未能分配的请求
+		for ms := int64(0); ms < millis; ms++ {
+			demand := backlog + s.poisson(lambda)
+			r.Millis++
+			if demand > capacity {
+				r.ExhaustedMillis++
+				backlog = demand - capacity
+				r.DelayedIds += backlog
+			} else {
+				backlog = 0
+			}
+		}
+	}
+	if r.Millis > 0 {
+		r.ExhaustedProb = float64(r.ExhaustedMillis) / float64(r.Millis)
+	}
+
+	// 重放窗口内, 新旧两次都用到的序号会重复, 即 min(X, Y)
+	overlap := s.expectedOverlap(lambda, capacity, 20000)
+	r.RollbackCollides = overlap * float64(s.Skew.Milliseconds())
+	r.SharedNodeRate = overlap * 1000
+
+	r.OverflowAt = time.Unix(0, (s.Layout.Epoch+s.Layout.MaxTimestamp())*int64(time.Millisecond))
+	r.YearsLeft = time.Until(r.OverflowAt).Hours() / 24 / 365.25
+	return r
+}
+
+// expectedOverlap estimates E[min(X, Y, capacity)] for X, Y ~ Poisson(lambda).
+func (s *Sim) expectedOverlap(lambda float64, capacity int64, rounds int) float64 {
+	var sum int64
+	for i := 0; i < rounds; i++ {
+		x, y := s.poisson(lambda), s.poisson(lambda)
+		if y < x {
+			x = y
+		}
+		if x > capacity {
+			x = capacity
+		}
+		sum += x
+	}
+	return float64(sum) / float64(rounds)
+}
+
+// poisson draws from a Poisson distribution, by Knuth's method for small
+// lambda and a normal approximation for large lambda.
+func (s *Sim) poisson(lambda float64) int64 {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		n := math.Round(lambda + math.Sqrt(lambda)*s.Rand.NormFloat64())
+		if n < 0 {
+			return 0
+		}
+		return int64(n)
+	}
+	l := math.Exp(-lambda)
+	var k int64
+	for p := s.Rand.Float64(); p > l; p *= s.Rand.Float64() {
+		k++
+	}
+	return k
+}
+
+// Print writes the report in a human readable form.
+func (r Report) Print(w io.Writer) {
+	l := r.Layout
+	fmt.Fprintf(w, "layout                  %d-%d-%d-%d-%d, epoch %d\n", l.TimestampBits, l.TagBits, l.DistrictBits, l.NodeBits, l.SequenceBits, l.Epoch)
+	fmt.Fprintf(w, "sequence exhaustion     %.6f of node-milliseconds (%d of %d)\n", r.ExhaustedProb, r.ExhaustedMillis, r.Millis)
+	fmt.Fprintf(w, "ids delayed             %d\n", r.DelayedIds)
+	fmt.Fprintf(w, "rollback duplicates     %.1f ids per rollback replayed after restart\n", r.RollbackCollides)
+	fmt.Fprintf(w, "shared node duplicates  %.1f ids per second per pair of nodes sharing an id\n", r.SharedNodeRate)
+	fmt.Fprintf(w, "timestamp overflow      %s (%.1f years left)\n", r.OverflowAt.UTC().Format(time.RFC3339), r.YearsLeft)
+}
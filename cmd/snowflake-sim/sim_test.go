@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestSim(t *testing.T) {
+	s := &Sim{
+		Layout:   snowflake.DefaultLayout,
+		Nodes:    2,
+		QPS:      2000 * 1000, // 每毫秒 2000, 远超 1024
+		Skew:     10 * time.Millisecond,
+		Duration: 100 * time.Millisecond,
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+	r := s.Run()
+	if r.ExhaustedProb < 0.99 {
+		t.Errorf("overloaded nodes should exhaust, got %f", r.ExhaustedProb)
+	}
+	if r.RollbackCollides < 1000 {
+		t.Errorf("rollback duplicates: got %f", r.RollbackCollides)
+	}
+
+	s.QPS = 1000
+	if r = s.Run(); r.ExhaustedMillis != 0 {
+		t.Errorf("light load should not exhaust, got %d", r.ExhaustedMillis)
+	}
+	if r.YearsLeft <= 0 {
+		t.Errorf("years left: got %f", r.YearsLeft)
+	}
+}
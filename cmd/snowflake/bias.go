@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sakishum/go_snowflake/verify"
+)
+
+func runBias(args []string) error {
+	fs := flag.NewFlagSet("bias", flag.ExitOnError)
+	shards := fs.Int("shards", 16, "candidate shard count")
+	fs.Parse(args)
+
+	a, err := verify.NewBiasAnalyzer(*shards)
+	if err != nil {
+		return err
+	}
+	if err := openInputs(fs.Args(), func(in *os.File) error { return a.AddFrom(in) }); err != nil {
+		return err
+	}
+	fmt.Printf("%-10s %10s %10s %8s\n", "function", "max/mean", "chi2", "z")
+	for _, r := range a.Reports() {
+		verdict := "ok"
+		if r.Biased {
+			verdict = "biased"
+		}
+		fmt.Printf("%-10s %10.3f %10.1f %8.1f %s\n", r.Func, r.MaxRatio, r.ChiSquare, r.Z, verdict)
+	}
+	safe := a.Recommend()
+	if len(safe) == 0 {
+		return errors.New("no function spreads the sample evenly")
+	}
+	fmt.Fprintf(os.Stderr, "%d ids over %d shards, recommended: %s\n", safe[0].Total, *shards, safe[0].Func)
+	return nil
+}
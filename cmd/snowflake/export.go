@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/export"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv, jsonl or parquet")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	f, err := createOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := export.NewWriter(*format, f)
+	if err != nil {
+		return err
+	}
+	return openInputs(fs.Args(), func(in *os.File) error {
+		_, err := export.Export(in, w, snowflake.DefaultLayout)
+		return err
+	})
+}
@@ -0,0 +1,84 @@
+// Command snowflake is a toolbox for working with snowflake ids.
+//
+//	snowflake export [-format csv|jsonl|parquet] [-out file] [files...]
+//	snowflake verify [-partitions n] [-tmp dir] [files...]
+//	snowflake tags [-config tags.conf] [-pkg ids] [-out file]
+//	snowflake vectors [-layout default] [-n 100] [-seed 1] [-out file]
+//	snowflake pregen [-n 1e6] [-node 0] [-layout default] [-out file]
+//	snowflake validate [-layout default] [-max-skew 0] [-min-node 0] [-max-node n] [files...]
+//	snowflake bias [-shards 16] [files...]
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"export", "decode ids into CSV, JSON lines or Parquet", runExport},
+	{"verify", "check files of ids for duplicates", runVerify},
+	{"tags", "generate Go tag constants from a tag config", runTags},
+	{"vectors", "write interop test vectors for a layout", runVectors},
+	{"pregen", "mint a block of ids into a binary file", runPregen},
+	{"validate", "vet a foreign dataset of ids for anomalies", runValidate},
+	{"bias", "measure the shard bias of a sample of ids", runBias},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "snowflake %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: snowflake <command> [arguments]\n\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	os.Exit(2)
+}
+
+// openInputs calls fn with every file named in args, or stdin when there
+// are none.
+func openInputs(args []string, fn func(f *os.File) error) error {
+	if len(args) == 0 {
+		return fn(os.Stdin)
+	}
+	for _, name := range args {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = fn(f)
+		f.Close()
+		if err != nil {
+			return errors.New(fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return nil
+}
+
+// createOutput returns the file named name, or stdout when name is empty.
+func createOutput(name string) (*os.File, error) {
+	if name == "" {
+		return os.Stdout, nil
+	}
+	return os.Create(name)
+}
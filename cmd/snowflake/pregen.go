@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// runPregen mints a block of ids into a file of 8-byte big-endian ids, for
+// systems that assign ids offline. The ids are leased millisecond by
+// millisecond in real time, so the node must be reserved for pregen: no
+// live worker may use it while the command runs.
+func runPregen(args []string) error {
+	fs := flag.NewFlagSet("pregen", flag.ExitOnError)
+	count := fs.String("n", "1000000", "number of ids, e.g. 1e7")
+	node := fs.Int64("node", 0, "node id reserved for pregenerated ids")
+	name := fs.String("layout", "default", "layout: a preset name or bit widths such as 39-5-9-10")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	v, err := strconv.ParseFloat(*count, 64)
+	if err != nil || v < 1 || v != float64(int64(v)) {
+		return errors.New(fmt.Sprintf("invalid count %q", *count))
+	}
+	l, err := snowflake.ParseLayout(*name)
+	if err != nil {
+		return err
+	}
+	worker, err := snowflake.NewIdWorker(*node, snowflake.WithLayout(l))
+	if err != nil {
+		return err
+	}
+	f, err := createOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	first, last, err := pregen(f, worker, int64(v))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d ids from %d to %d, issued between %s and %s\n",
+		int64(v), first, last, idTime(first, l), idTime(last, l))
+	return nil
+}
+
+// pregen writes n ids of worker to w and returns the first and last.
+func pregen(w io.Writer, worker *snowflake.IdWorker, n int64) (first, last snowflake.ID, err error) {
+	bw := bufio.NewWriterSize(w, 1<<16)
+	size := worker.Layout().MaxSequence() + 1
+	for written := int64(0); written < n; {
+		if size > n-written {
+			size = n - written
+		}
+		lease, err := worker.LeaseRange(size)
+		if err != nil {
+			return 0, 0, err
+		}
+		for i := 0; i < lease.Len(); i++ {
+			id := lease.ID(i)
+			if written == 0 {
+				first = id
+			}
+			last = id
+			if _, err := bw.Write(id.RawBytes()); err != nil {
+				return 0, 0, err
+			}
+			written++
+		}
+	}
+	return first, last, bw.Flush()
+}
+
+func idTime(f snowflake.ID, l snowflake.Layout) string {
+	return time.UnixMilli(f.WithLayout(l).Timestamp()).UTC().Format(time.RFC3339Nano)
+}
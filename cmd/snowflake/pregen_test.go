@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestPregen(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(7)
+	var buf bytes.Buffer
+	first, last, err := pregen(&buf, worker, 2500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 2500*8 {
+		t.Fatalf("wrote %d bytes, want %d", buf.Len(), 2500*8)
+	}
+	prev := snowflake.ID(-1)
+	for b := buf.Bytes(); len(b) > 0; b = b[8:] {
+		id, err := snowflake.ParseRawBytes(b[:8])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev || id.NodeId() != 7 {
+			t.Fatalf("id %d after %d", id, prev)
+		}
+		prev = id
+	}
+	if first >= last || last != prev {
+		t.Errorf("range %d-%d, last written %d", first, last, prev)
+	}
+	// 预生成的毫秒不能再被该节点使用
+	if id, _ := worker.NextId(); id <= last {
+		t.Errorf("worker reissued pregenerated range: %d <= %d", id, last)
+	}
+}
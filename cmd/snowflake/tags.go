@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// tagEntry is one line of a tag config: an entity name and its tag.
+type tagEntry struct {
+	name string
+	tag  int64
+	line int
+}
+
+var tagName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// readTagConfig reads "name tag" lines, failing when two lines claim the
+// same name or the same tag.
+func readTagConfig(r io.Reader) ([]tagEntry, error) {
+	var entries []tagEntry
+	byName := make(map[string]int)
+	byTag := make(map[int64]int)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		if len(f) != 2 || !tagName.MatchString(f[0]) {
+			return nil, errors.New(fmt.Sprintf("line %d: want \"name tag\" with a lower-case name", line))
+		}
+		tag, err := strconv.ParseInt(f[1], 10, 64)
+		if max := snowflake.TaggedLayout.MaxTag(); err != nil || tag <= 0 || tag > max {
+			return nil, errors.New(fmt.Sprintf("line %d: tag must be between 1 and %d", line, max))
+		}
+		if prev, ok := byName[f[0]]; ok {
+			return nil, errors.New(fmt.Sprintf("line %d: %q already defined on line %d", line, f[0], prev))
+		}
+		if prev, ok := byTag[tag]; ok {
+			return nil, errors.New(fmt.Sprintf("line %d: tag %d already claimed on line %d", line, tag, prev))
+		}
+		byName[f[0]], byTag[tag] = line, line
+		entries = append(entries, tagEntry{name: f[0], tag: tag, line: line})
+	}
+	return entries, sc.Err()
+}
+
+// generateTags returns Go source declaring a Tag constant per entry and
+// registering them all at init.
+func generateTags(pkg string, entries []tagEntry) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"snowflake tags\". DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	b.WriteString("import snowflake \"github.com/sakishum/go_snowflake\"\n\nconst (\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\tTag%s snowflake.Tag = %d\n", goName(e.name), e.tag)
+	}
+	b.WriteString(")\n\nfunc init() {\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\tsnowflake.MustRegisterTag(%q, Tag%s)\n", e.name, goName(e.name))
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// goName turns "user_profile" into "UserProfile".
+func goName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func runTags(args []string) error {
+	fs := flag.NewFlagSet("tags", flag.ExitOnError)
+	config := fs.String("config", "tags.conf", "file of \"name tag\" lines")
+	pkg := fs.String("pkg", "ids", "package of the generated file")
+	out := fs.String("out", "", "output file, stdout when empty")
+	fs.Parse(args)
+
+	f, err := os.Open(*config)
+	if err != nil {
+		return err
+	}
+	entries, err := readTagConfig(f)
+	f.Close()
+	if err != nil {
+		return errors.New(fmt.Sprintf("%s: %v", *config, err))
+	}
+	src, err := generateTags(*pkg, entries)
+	if err != nil {
+		return err
+	}
+	w, err := createOutput(*out)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(src); err != nil {
+		return err
+	}
+	if w != os.Stdout {
+		return w.Close()
+	}
+	return nil
+}
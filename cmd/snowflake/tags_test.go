@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateTags(t *testing.T) {
+	entries, err := readTagConfig(strings.NewReader("# billing team\ninvoice 3\nuser_profile 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generateTags("ids", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"TagInvoice     snowflake.Tag = 3",
+		"snowflake.MustRegisterTag(\"user_profile\", TagUserProfile)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("missing %q in\n%s", want, src)
+		}
+	}
+
+	for _, bad := range []string{"a 1\nb 1\n", "a 1\na 2\n", "a 0\n", "a 4\n", "A 1\n", "a\n"} {
+		if _, err := readTagConfig(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q should fail", bad)
+		}
+	}
+}
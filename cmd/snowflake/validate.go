@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/verify"
+)
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	layoutName := fs.String("layout", "default", "layout the ids claim to use")
+	maxSkew := fs.Duration("max-skew", 0, "accept timestamps up to this far in the future")
+	minNode := fs.Int64("min-node", 0, "lowest node id the issuer uses")
+	maxNode := fs.Int64("max-node", -1, "highest node id the issuer uses, -1 for the layout's maximum")
+	partitions := fs.Int("partitions", 256, "number of temporary partitions; raise it for larger inputs")
+	tmp := fs.String("tmp", "", "directory for temporary partitions")
+	fs.Parse(args)
+
+	layout, err := snowflake.ParseLayout(*layoutName)
+	if err != nil {
+		return err
+	}
+	x, err := verify.NewValidator(layout, *tmp, *partitions, func(a verify.Anomaly) {
+		fmt.Println(a)
+	})
+	if err != nil {
+		return err
+	}
+	defer x.Close()
+	x.MaxSkew = *maxSkew
+	x.MinNodeId = *minNode
+	if *maxNode >= 0 {
+		x.MaxNodeId = *maxNode
+	}
+	if err := openInputs(fs.Args(), func(in *os.File) error { return x.AddFrom(in) }); err != nil {
+		return err
+	}
+	counts, err := x.Finish()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, kind := range []verify.AnomalyKind{verify.OutsideLayout, verify.NoTimestamp, verify.FutureTimestamp, verify.ImpossibleNode, verify.DuplicateTuple} {
+		if counts[kind] > 0 {
+			fmt.Fprintf(os.Stderr, "%s: %d\n", kind, counts[kind])
+			total += counts[kind]
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d ids checked, %d anomalies\n", x.Total(), total)
+	if total > 0 {
+		return errors.New("anomalies found")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func runVectors(args []string) error {
+	fs := flag.NewFlagSet("vectors", flag.ExitOnError)
+	name := fs.String("layout", "default", "layout: a preset name or bit widths such as 39-5-9-10")
+	n := fs.Int("n", 100, "number of random vectors after the edge cases")
+	seed := fs.Int64("seed", 1, "random seed")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	l, err := snowflake.ParseLayout(*name)
+	if err != nil {
+		return err
+	}
+	f, err := createOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snowflake.GenerateTestVectors(l, *n, *seed))
+}
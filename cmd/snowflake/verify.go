@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sakishum/go_snowflake/verify"
+)
+
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	partitions := fs.Int("partitions", 256, "number of temporary partitions; raise it for larger inputs")
+	tmp := fs.String("tmp", "", "directory for temporary partitions")
+	fs.Parse(args)
+
+	v, err := verify.New(*tmp, *partitions)
+	if err != nil {
+		return err
+	}
+	defer v.Close()
+	err = openInputs(fs.Args(), func(in *os.File) error {
+		return v.AddFrom(in)
+	})
+	if err != nil {
+		return err
+	}
+	dups := 0
+	err = v.Check(func(c verify.Collision) {
+		dups++
+		d := c.Decoded
+		fmt.Printf("%s x%d time=%s tag=%s district=%d node=%d sequence=%d\n",
+			c.ID, c.Count, d.Time, d.Tag, d.DistrictId, d.NodeId, d.Sequence)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d ids checked, %d duplicated\n", v.Total(), dups)
+	if dups > 0 {
+		return errors.New("duplicate ids found")
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/auth"
+	"github.com/sakishum/go_snowflake/export"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer serves the snowflake service next to the standard gRPC health
+// and reflection services, so grpcurl and Kubernetes gRPC probes work
+// against snowflaked.
+type grpcServer struct {
+	srv    *grpc.Server
+	health *health.Server
+}
+
+func newGRPCServer(worker *snowflake.IdWorker, tlsConfig *tls.Config, authn auth.Authenticator) *grpcServer {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if authn != nil {
+		opts = append(opts,
+			grpc.UnaryInterceptor(unaryAuth(authn)),
+			grpc.StreamInterceptor(streamAuth(authn)),
+		)
+	}
+	s := &grpcServer{
+		srv:    grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+	healthpb.RegisterHealthServer(s.srv, s.health)
+	s.srv.RegisterService(&snowflakeServiceDesc, &snowflakeService{worker: worker})
+	reflection.Register(s.srv)
+	return s
+}
+
+// grpcService is the snowflake service. Its messages are plain Go structs
+// encoded as JSON, with no generated protobuf code: clients call it with
+// the "json" content subtype, e.g. grpc.CallContentSubtype("json").
+const grpcService = "snowflake.v1.Snowflake"
+
+// jsonCodec encodes the messages of the snowflake service.
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(b []byte, v interface{}) error {
+	return json.Unmarshal(b, v)
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// leaseRequest asks for N sequences of one millisecond, for the client to
+// mint ids from offline, see IdWorker.LeaseRange.
+type leaseRequest struct {
+	N int64 `json:"n"`
+}
+
+// maxDecodeBatch is the most ids one DecodeBatch call takes.
+const maxDecodeBatch = 10000
+
+// decodeRequest asks for the fields of IDs, decoded with the named layout,
+// see snowflake.ParseLayout, or with the server's when Layout is empty.
+type decodeRequest struct {
+	IDs    []snowflake.ID `json:"ids"`
+	Layout string         `json:"layout,omitempty"`
+}
+
+type snowflakeService struct {
+	worker *snowflake.IdWorker
+}
+
+var snowflakeServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcService,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "LeaseRange", Handler: leaseRangeHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "DecodeBatch", Handler: decodeBatchHandler, ServerStreams: true},
+	},
+	Metadata: "snowflake.v1",
+}
+
+func (s *snowflakeService) leaseRange(ctx context.Context, req *leaseRequest) (*snowflake.Lease, error) {
+	l, err := s.worker.LeaseRange(req.N)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &l, nil
+}
+
+func leaseRangeHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(leaseRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	s := srv.(*snowflakeService)
+	if interceptor == nil {
+		return s.leaseRange(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + grpcService + "/LeaseRange"}
+	return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.leaseRange(ctx, req.(*leaseRequest))
+	})
+}
+
+// decodeBatch streams back one export.Record per id of the request, in
+// order.
+func (s *snowflakeService) decodeBatch(req *decodeRequest, stream grpc.ServerStream) error {
+	if len(req.IDs) > maxDecodeBatch {
+		return status.Errorf(codes.InvalidArgument, "at most %d ids per batch", maxDecodeBatch)
+	}
+	layout := s.worker.Layout()
+	if req.Layout != "" {
+		var err error
+		if layout, err = snowflake.ParseLayout(req.Layout); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	for _, id := range req.IDs {
+		r := export.Decode(id, layout)
+		if err := stream.SendMsg(&r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeBatchHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(decodeRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(*snowflakeService).decodeBatch(req, stream)
+}
+
+// grpcError maps worker errors to status codes: a worker that cannot issue
+// right now is unavailable, anything else is the caller's fault.
+func grpcError(err error) error {
+	var back *snowflake.ClockMovedBackwardsError
+	if errors.Is(err, snowflake.ErrFrozen) || errors.Is(err, snowflake.ErrNodeLeaseLost) || errors.As(err, &back) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// serve listens on addr and reports the server as serving.
+func (s *grpcServer) serve(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.health.Resume()
+	return s.srv.Serve(ln)
+}
+
+// stop reports the server as not serving, so probes fail while in-flight
+// calls finish, then stops it.
+func (s *grpcServer) stop() {
+	s.health.Shutdown()
+	s.srv.GracefulStop()
+}
+
+// public reports whether method may be called without credentials: probes
+// cannot send any.
+func public(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/")
+}
+
+func authenticate(ctx context.Context, authn auth.Authenticator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if v := md.Get("authorization"); len(v) > 0 {
+		token = strings.TrimPrefix(v[0], "Bearer ")
+	} else if v := md.Get("x-api-key"); len(v) > 0 {
+		token = v[0]
+	}
+	p, err := authn.Authenticate(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return auth.NewContext(ctx, p), nil
+}
+
+func unaryAuth(authn auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, authn)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(authn auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), authn)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
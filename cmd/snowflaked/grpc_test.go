@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/export"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// dialSnowflake serves worker over gRPC on a loopback port and dials it.
+func dialSnowflake(t *testing.T, worker *snowflake.IdWorker) *grpc.ClientConn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := newGRPCServer(worker, nil, nil)
+	go gs.srv.Serve(ln)
+	t.Cleanup(gs.stop)
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithDefaultCallOptions(grpc.CallContentSubtype("json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCLeaseRange(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(5)
+	conn := dialSnowflake(t, worker)
+	ctx := context.Background()
+
+	var l snowflake.Lease
+	if err := conn.Invoke(ctx, "/"+grpcService+"/LeaseRange", &leaseRequest{N: 10}, &l); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := worker.NextId()
+	if l.Len() != 10 || l.NodeId != 5 || l.ID(9) >= next {
+		t.Errorf("lease %+v, next id %d", l, next)
+	}
+
+	err := conn.Invoke(ctx, "/"+grpcService+"/LeaseRange", &leaseRequest{N: 0}, &l)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty lease: got %v", err)
+	}
+	worker.Freeze("test")
+	err = conn.Invoke(ctx, "/"+grpcService+"/LeaseRange", &leaseRequest{N: 1}, &l)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("frozen worker: got %v", err)
+	}
+}
+
+func TestGRPCDecodeBatch(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(5)
+	conn := dialSnowflake(t, worker)
+	ids, _ := worker.NextIds(3)
+
+	desc := &grpc.StreamDesc{StreamName: "DecodeBatch", ServerStreams: true}
+	stream, err := conn.NewStream(context.Background(), desc, "/"+grpcService+"/DecodeBatch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&decodeRequest{IDs: ids}); err != nil {
+		t.Fatal(err)
+	}
+	stream.CloseSend()
+	for i, id := range ids {
+		var r export.Record
+		if err := stream.RecvMsg(&r); err != nil {
+			t.Fatal(err)
+		}
+		if r.ID != id || r.NodeId != 5 || r.Timestamp != id.WithLayout(worker.Layout()).Timestamp() {
+			t.Errorf("record %d: got %+v", i, r)
+		}
+	}
+	if err := stream.RecvMsg(new(export.Record)); err != io.EOF {
+		t.Errorf("after the last record: got %v", err)
+	}
+
+	stream, _ = conn.NewStream(context.Background(), desc, "/"+grpcService+"/DecodeBatch")
+	stream.SendMsg(&decodeRequest{IDs: make([]snowflake.ID, maxDecodeBatch+1)})
+	stream.CloseSend()
+	if err := stream.RecvMsg(new(export.Record)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("oversized batch: got %v", err)
+	}
+}
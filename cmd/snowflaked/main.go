@@ -0,0 +1,305 @@
+// Command snowflaked runs one snowflake generator per host and serves ids
+// to local processes over a unix domain socket and, optionally, over HTTP.
+// With -grpc it also serves a gRPC service leasing id ranges to clients and
+// decoding batches of ids, next to the standard gRPC health and reflection
+// services.
+//
+// The HTTP and gRPC listeners can be protected with TLS (mutual when
+// -tls-client-ca is set) and with API keys or HS256 JWTs; the unix socket
+// is local and left open. With authentication configured, admins can
+// freeze and unfreeze issuance with POST /admin/freeze and /admin/unfreeze
+// and, given -spare-nodes, move the server to a spare node ID at runtime
+// with POST /admin/rotate-node. With -quotas, each authenticated caller may
+// take a limited number of ids per minute over HTTP. With -drift-ntp the
+// clock is compared to an NTP server and skews above -drift-threshold are
+// logged; admins can change that threshold and the quotas at runtime with
+// POST /admin/config.
+//
+// At startup it logs a report on the clock and epoch it depends on; with
+// -strict-startup it refuses to start when the report has severe findings.
+//
+// Every flag can also be set from the environment, -drift-ntp as
+// SNOWFLAKED_DRIFT_NTP, which suits containers; the command line wins.
+// On SIGTERM or SIGINT it stops accepting requests, lets in-flight ones
+// finish within -drain-timeout, releases a spare node it rotated onto and
+// flushes the high-water timestamp to -state. It exits with 2 on a
+// configuration error and 1 when it fails while running.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/auth"
+	"github.com/sakishum/go_snowflake/daemon"
+	"github.com/sakishum/go_snowflake/httpapi"
+)
+
+// Exit codes, so orchestrators can tell a bad deployment from a crash.
+const (
+	exitRuntime = 1 // 运行中失败
+	exitConfig  = 2 // 配置错误, 与 flag 包解析失败时一致
+)
+
+// fatalConfig logs a configuration error and exits with exitConfig.
+func fatalConfig(v ...interface{}) {
+	log.Print(v...)
+	os.Exit(exitConfig)
+}
+
+// fatalRuntime logs a runtime failure and exits with exitRuntime.
+func fatalRuntime(v ...interface{}) {
+	log.Print(v...)
+	os.Exit(exitRuntime)
+}
+
+// envPrefix prefixes the environment variables that set flags: -drift-ntp
+// is read from SNOWFLAKED_DRIFT_NTP.
+const envPrefix = "SNOWFLAKED_"
+
+// envName returns the environment variable of flag name.
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// setFlagsFromEnv sets every flag of fs from its environment variable.
+// Call it before fs.Parse, so the command line wins.
+func setFlagsFromEnv(fs *flag.FlagSet, lookup func(string) (string, bool)) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if v, ok := lookup(envName(f.Name)); ok && err == nil {
+			if e := f.Value.Set(v); e != nil {
+				err = errors.New(fmt.Sprintf("%s: %v", envName(f.Name), e))
+			}
+		}
+	})
+	return err
+}
+
+func main() {
+	nodeId := flag.Int64("node", 0, "node id of this host")
+	socket := flag.String("socket", "/var/run/snowflake.sock", "unix socket path")
+	httpAddr := flag.String("http", "", "serve the HTTP API on this address")
+	grpcAddr := flag.String("grpc", "", "serve the gRPC snowflake, health and reflection services on this address")
+	stringIds := flag.Bool("string-ids", false, "encode ids as strings for JavaScript clients")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file")
+	tlsKey := flag.String("tls-key", "", "TLS key file")
+	tlsClientCA := flag.String("tls-client-ca", "", "require client certificates signed by this CA")
+	apiKeys := flag.String("api-keys", "", "file of \"name key [admin]\" lines")
+	jwtSecret := flag.String("jwt-secret", "", "file holding the HS256 JWT secret")
+	spareNodes := flag.String("spare-nodes", "", "node IDs reserved for rotation, as first-last")
+	quotaFile := flag.String("quotas", "", "file of \"name ids-per-minute\" lines, * for everyone else")
+	driftNTP := flag.String("drift-ntp", "", "watch the clock against this NTP server, e.g. pool.ntp.org:123")
+	driftThreshold := flag.Duration("drift-threshold", 100*time.Millisecond, "log clock skews above this")
+	strictStartup := flag.Bool("strict-startup", false, "refuse to start on severe clock or epoch findings")
+	stateFile := flag.String("state", "", "file keeping the high-water timestamp across restarts")
+	drainTimeout := flag.Duration("drain-timeout", 8*time.Second, "how long to let in-flight requests finish on shutdown")
+	if err := setFlagsFromEnv(flag.CommandLine, os.LookupEnv); err != nil {
+		fatalConfig(err)
+	}
+	flag.Parse()
+	snowflake.StringIDs = *stringIds
+
+	var opts []snowflake.Option
+	var store *snowflake.MmapStore
+	if *stateFile != "" {
+		var err error
+		if store, err = snowflake.NewMmapStore(*stateFile); err != nil {
+			fatalConfig(err)
+		}
+		opts = append(opts, snowflake.WithStore(store))
+	}
+	worker, err := snowflake.NewIdWorker(*nodeId, opts...)
+	if err != nil {
+		fatalConfig(err)
+	}
+	report := worker.StartupReport()
+	for _, f := range report.Findings {
+		log.Printf("snowflaked: startup %s %s: %s", f.Severity, f.Check, f.Message)
+	}
+	if *strictStartup && report.Severe() {
+		fatalConfig("snowflaked: severe startup findings, refusing to start")
+	}
+	var tlsConfig *tls.Config
+	if *tlsCert != "" {
+		if tlsConfig, err = auth.ServerTLS(*tlsCert, *tlsKey, *tlsClientCA); err != nil {
+			fatalConfig(err)
+		}
+	}
+	authn, err := loadAuthenticator(*apiKeys, *jwtSecret)
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	var quotas *httpapi.Quotas
+	if *quotaFile != "" {
+		if authn == nil {
+			fatalConfig("snowflaked: -quotas needs -api-keys or -jwt-secret")
+		}
+		if quotas, err = loadQuotas(*quotaFile); err != nil {
+			fatalConfig(err)
+		}
+	}
+	var alloc snowflake.NodeAllocator
+	if *spareNodes != "" {
+		if alloc, err = parseNodeRange(*spareNodes); err != nil {
+			fatalConfig(err)
+		}
+	}
+
+	ctx, stopDrift := context.WithCancel(context.Background())
+	var drift *snowflake.DriftMonitor
+	if *driftNTP != "" {
+		drift = snowflake.NewDriftMonitor(worker, snowflake.NTPSource{Addr: *driftNTP}, *driftThreshold, func(s snowflake.DriftSample) {
+			log.Printf("snowflaked: clock skew %s against %s", s.Skew, *driftNTP)
+		})
+		drift.Start(ctx, time.Minute)
+	}
+
+	failed := make(chan error, 3)
+	srv := daemon.NewServer(worker)
+	var hs *http.Server
+	if *httpAddr != "" {
+		var h http.Handler = httpapi.NewHandler(worker)
+		if quotas != nil {
+			h = quotas.Middleware(h)
+		}
+		if authn != nil {
+			mux := http.NewServeMux()
+			mux.Handle("/", h)
+			mux.Handle("/admin/", auth.RequireAdmin(httpapi.NewAdminHandler(worker, alloc)))
+			mux.Handle("/admin/config", auth.RequireAdmin(httpapi.NewConfigHandler(httpapi.Tunables{Drift: drift, Quotas: quotas})))
+			h = auth.HTTP(authn, mux)
+		}
+		hs = &http.Server{Addr: *httpAddr, Handler: h, TLSConfig: tlsConfig}
+		go func() {
+			var err error
+			if tlsConfig != nil {
+				err = hs.ListenAndServeTLS("", "")
+			} else {
+				err = hs.ListenAndServe()
+			}
+			if err != http.ErrServerClosed {
+				failed <- err
+			}
+		}()
+	}
+	var gs *grpcServer
+	if *grpcAddr != "" {
+		gs = newGRPCServer(worker, tlsConfig, authn)
+		go func() {
+			if err := gs.serve(*grpcAddr); err != nil {
+				failed <- err
+			}
+		}()
+	}
+	go func() {
+		log.Printf("snowflaked: node %d serving on %s", *nodeId, *socket)
+		if err := srv.ListenAndServe(*socket); err != nil {
+			failed <- err
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	code := 0
+	select {
+	case s := <-sig:
+		log.Printf("snowflaked: %s, draining for up to %s", s, *drainTimeout)
+	case err := <-failed:
+		log.Printf("snowflaked: %v", err)
+		code = exitRuntime
+	}
+	stopDrift()
+	if err := drain(worker, *nodeId, alloc, store, srv, hs, gs, *drainTimeout); err != nil {
+		fatalRuntime("snowflaked: shutdown: ", err)
+	}
+	os.Remove(*socket)
+	os.Exit(code)
+}
+
+// drain shuts the server down: it stops the listeners and lets in-flight
+// requests finish within timeout, freezes the worker so nothing is issued
+// past the saved high-water mark, releases a spare node the server rotated
+// onto, and flushes the state file.
+func drain(worker *snowflake.IdWorker, nodeId int64, alloc snowflake.NodeAllocator, store *snowflake.MmapStore, srv *daemon.Server, hs *http.Server, gs *grpcServer, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var errs []error
+	if hs != nil {
+		errs = append(errs, hs.Shutdown(ctx))
+	}
+	if gs != nil {
+		gs.stop()
+	}
+	errs = append(errs, srv.Shutdown(ctx))
+	worker.Freeze("shutting down")
+	if current := worker.DumpState().NodeId; alloc != nil && current != nodeId {
+		errs = append(errs, alloc.Release(current))
+	}
+	if store != nil {
+		errs = append(errs, store.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// parseNodeRange parses "first-last" into an allocator of those node IDs.
+func parseNodeRange(s string) (*snowflake.MemoryNodeAllocator, error) {
+	first, last, ok := strings.Cut(s, "-")
+	a, err1 := strconv.ParseInt(first, 10, 64)
+	b, err2 := strconv.ParseInt(last, 10, 64)
+	if !ok || err1 != nil || err2 != nil || a > b {
+		return nil, errors.New(fmt.Sprintf("invalid node range %q, want first-last", s))
+	}
+	return snowflake.NewMemoryNodeAllocator(a, b), nil
+}
+
+func loadQuotas(path string) (*httpapi.Quotas, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return httpapi.ReadQuotas(f)
+}
+
+// loadAuthenticator returns nil when neither API keys nor a JWT secret are
+// configured, leaving the service open.
+func loadAuthenticator(apiKeys, jwtSecret string) (auth.Authenticator, error) {
+	var chain auth.Chain
+	if apiKeys != "" {
+		f, err := os.Open(apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := auth.ReadAPIKeys(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+	if jwtSecret != "" {
+		secret, err := os.ReadFile(jwtSecret)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, &auth.JWT{Secret: []byte(strings.TrimSpace(string(secret)))})
+	}
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestSetFlagsFromEnv(t *testing.T) {
+	fs := flag.NewFlagSet("snowflaked", flag.ContinueOnError)
+	node := fs.Int64("node", 0, "")
+	ntp := fs.String("drift-ntp", "", "")
+	drain := fs.Duration("drain-timeout", time.Second, "")
+	env := map[string]string{"SNOWFLAKED_NODE": "7", "SNOWFLAKED_DRIFT_NTP": "pool.ntp.org:123"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	if err := setFlagsFromEnv(fs, lookup); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"-node", "9"}); err != nil {
+		t.Fatal(err)
+	}
+	if *node != 9 || *ntp != "pool.ntp.org:123" || *drain != time.Second {
+		t.Errorf("got node %d, ntp %q, drain %s", *node, *ntp, *drain)
+	}
+	env["SNOWFLAKED_DRAIN_TIMEOUT"] = "soon"
+	if err := setFlagsFromEnv(fs, lookup); err == nil {
+		t.Error("invalid value should fail")
+	}
+}
@@ -0,0 +1,141 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CompactID is a 31-bit id for systems that cannot carry 64-bit integers
+// end to end, such as embedded firmware. It is always positive as an int32.
+type CompactID int32
+
+func (c CompactID) Int32() int32 {
+	return int32(c)
+}
+
+func (c CompactID) String() string {
+	return strconv.FormatInt(int64(c), 10)
+}
+
+// CompactLayout splits the 31 bits of a CompactID. The timestamp counts
+// Units since Epoch instead of milliseconds.
+type CompactLayout struct {
+	TimestampBits uint
+	NodeBits      uint
+	SequenceBits  uint
+	Unit          time.Duration
+	Epoch         int64 // unix 毫秒
+}
+
+// DefaultCompactLayout counts minutes for about 4 years (21 bits), with 4
+// nodes (2 bits) issuing up to 256 ids a minute each (8 bits).
+var DefaultCompactLayout = CompactLayout{
+	TimestampBits: 21,
+	NodeBits:      2,
+	SequenceBits:  8,
+	Unit:          time.Minute,
+	Epoch:         1735689600000, // 2025-01-01 UTC
+}
+
+// Validate checks the fields fit in 31 bits and the unit is at least a
+// millisecond.
+func (l CompactLayout) Validate() error {
+	if l.TimestampBits == 0 || l.SequenceBits == 0 {
+		return errors.New("compact layout needs timestamp and sequence bits")
+	}
+	if total := l.TimestampBits + l.NodeBits + l.SequenceBits; total > 31 {
+		return errors.New(fmt.Sprintf("compact layout uses %d bits, at most 31 allowed", total))
+	}
+	if l.Unit < time.Millisecond || l.Unit%time.Millisecond != 0 {
+		return errors.New("compact layout unit must be a whole number of milliseconds")
+	}
+	return nil
+}
+
+// layout returns the equivalent worker layout, counting units.
+func (l CompactLayout) layout() Layout {
+	return Layout{
+		TimestampBits: l.TimestampBits,
+		NodeBits:      l.NodeBits,
+		SequenceBits:  l.SequenceBits,
+		Epoch:         l.Epoch / l.unit(),
+	}
+}
+
+func (l CompactLayout) unit() int64 {
+	return int64(l.Unit / time.Millisecond)
+}
+
+// Time returns the start of the unit c was issued in.
+func (l CompactLayout) Time(c CompactID) time.Time {
+	v := ID(c).WithLayout(l.layout())
+	return time.UnixMilli(v.Timestamp() * l.unit())
+}
+
+func (l CompactLayout) NodeId(c CompactID) int64 {
+	return ID(c).WithLayout(l.layout()).NodeId()
+}
+
+func (l CompactLayout) Sequence(c CompactID) int64 {
+	return ID(c).WithLayout(l.layout()).Sequence()
+}
+
+// CompactWorker issues CompactIDs. It accepts the options of NewIdWorker;
+// a clock set with WithClock is read in units of the layout.
+type CompactWorker struct {
+	worker *IdWorker
+	layout CompactLayout
+}
+
+// NewCompactWorker new a compact id generator object.
+func NewCompactWorker(NodeId int64, layout CompactLayout, opts ...Option) (*CompactWorker, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	compact := func(id *IdWorker) error {
+		id.districtId, id.tag = 0, 0
+		if err := WithLayout(layout.layout())(id); err != nil {
+			return err
+		}
+		id.clock = unitClock{clock: id.clock, unit: layout.unit()}
+		return nil
+	}
+	w, err := NewIdWorker(NodeId, append(opts, compact)...)
+	if err != nil {
+		return nil, err
+	}
+	return &CompactWorker{worker: w, layout: layout}, nil
+}
+
+// NextId get a compact id. A unit lasts far longer than a millisecond, so
+// instead of waiting for the next one when every sequence is used, NextId
+// fails with ErrSequenceExhausted.
+func (c *CompactWorker) NextId() (CompactID, error) {
+	c.worker.Lock()
+	defer c.worker.Unlock()
+	id, err := c.worker.nextidAt(c.worker.clock.Millis(), false)
+	if err != nil {
+		return 0, err
+	}
+	if int64(id) > 1<<31-1 {
+		return 0, ErrTimeOutOfRange
+	}
+	return CompactID(id), nil
+}
+
+// Layout returns the layout of the worker.
+func (c *CompactWorker) Layout() CompactLayout {
+	return c.layout
+}
+
+// unitClock reads clock in units of unit milliseconds.
+type unitClock struct {
+	clock Clock
+	unit  int64
+}
+
+func (u unitClock) Millis() int64 {
+	return u.clock.Millis() / u.unit
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompactWorker(t *testing.T) {
+	l := DefaultCompactLayout
+	l.SequenceBits = 2
+	w, err := NewCompactWorker(3, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev CompactID
+	for i := 0; i < 4; i++ {
+		c, err := w.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c <= prev || l.NodeId(c) != 3 {
+			t.Fatalf("id %d after %d, node %d", c, prev, l.NodeId(c))
+		}
+		prev = c
+	}
+	if d := time.Since(l.Time(prev)); d < 0 || d > 2*time.Minute {
+		t.Errorf("time %s is %s ago", l.Time(prev), d)
+	}
+	if _, err := w.NextId(); !errors.Is(err, ErrSequenceExhausted) {
+		t.Errorf("fifth id in a unit: got %v, want ErrSequenceExhausted", err)
+	}
+}
+
+func TestCompactWorkerFail(t *testing.T) {
+	if _, err := NewCompactWorker(4, DefaultCompactLayout); err == nil {
+		t.Error("node 4 does not fit in 2 bits")
+	}
+	l := DefaultCompactLayout
+	l.TimestampBits = 30
+	if _, err := NewCompactWorker(0, l); err == nil {
+		t.Error("layout over 31 bits should fail")
+	}
+}
@@ -0,0 +1,19 @@
+package snowflake
+
+import (
+	"context"
+)
+
+type idKey struct{}
+
+// NewContext returns a copy of ctx carrying id, typically the id of the
+// request being served, so it reaches logs and traces down the call stack.
+func NewContext(ctx context.Context, id ID) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the id stored in ctx by NewContext.
+func FromContext(ctx context.Context) (ID, bool) {
+	id, ok := ctx.Value(idKey{}).(ID)
+	return id, ok
+}
@@ -0,0 +1,16 @@
+package snowflake
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("empty context should hold no id")
+	}
+	ctx := NewContext(context.Background(), 42)
+	if id, ok := FromContext(ctx); !ok || id != 42 {
+		t.Errorf("got %d, %v", id, ok)
+	}
+}
@@ -0,0 +1,95 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// counterClock stands in for an untrusted clock: it holds still and moves
+// one tick forward only when the worker has exhausted the current one. It
+// shares the worker's state and is read with the worker lock held only;
+// anything reading the time outside the lock uses wallClock.
+type counterClock struct {
+	base Clock  // 不可信的时钟, 恢复时换回
+	ts   int64  // 计数器
+	last *int64 // worker 的 lastTimestamp, 计数器不落后于它
+}
+
+func (c *counterClock) Millis() int64 {
+	if *c.last > c.ts {
+		c.ts = *c.last
+	}
+	return c.ts
+}
+
+func (c *counterClock) advance(last int64) int64 {
+	if c.ts <= last {
+		c.ts = last + 1
+	}
+	return c.ts
+}
+
+// wallClock returns the clock behind clock following real time: the base
+// of a counter, otherwise clock itself. Called with the worker lock held.
+func wallClock(clock Clock) Clock {
+	if c, ok := clock.(*counterClock); ok {
+		return c.base
+	}
+	return clock
+}
+
+// waitPast waits for clock to pass last, unless it is a counter, which
+// only moves on as ids are issued.
+func waitPast(clock Clock, last int64) {
+	if _, ok := clock.(*counterClock); !ok {
+		tilNextMillis(clock, last)
+	}
+}
+
+// UntrustClock switches the worker to counter mode, for when its clock
+// cannot be trusted at all, e.g. a VM resumed from a snapshot. The
+// timestamp field then holds a counter continuing from the last issued
+// timestamp, or from the epoch before the first id, and moving on only
+// when a tick's sequence is exhausted. With a store the counter is
+// persisted like a timestamp, so ids stay unique across restarts; they no
+// longer tell when they were issued.
+func (id *IdWorker) UntrustClock() {
+	id.Lock()
+	defer id.Unlock()
+	if _, ok := id.clock.(*counterClock); ok {
+		return
+	}
+	start := id.lastTimestamp + 1 // 不读不可信的时钟
+	if epoch := id.epoch() * id.layout.tick(); start < epoch {
+		start = epoch
+	}
+	id.clock = &counterClock{base: id.clock, ts: start, last: &id.lastTimestamp}
+	id.log(slog.LevelWarn, "snowflake: clock untrusted, issuing from a counter", "counter", start)
+}
+
+// TrustClock leaves counter mode once the clock is validated again. It
+// fails while the clock is not yet past the counter, as every id would
+// then fail with ClockMovedBackwardsError; the worker stays on the counter.
+func (id *IdWorker) TrustClock() error {
+	id.Lock()
+	defer id.Unlock()
+	c, ok := id.clock.(*counterClock)
+	if !ok {
+		return nil
+	}
+	if now := c.base.Millis(); now <= c.ts {
+		return errors.New(fmt.Sprintf("clock is %d ticks behind the counter", c.ts-now+1))
+	}
+	id.clock = c.base
+	id.log(slog.LevelInfo, "snowflake: clock trusted again", "counter", c.ts)
+	return nil
+}
+
+// CounterMode reports whether the worker is in counter mode.
+func (id *IdWorker) CounterMode() bool {
+	id.Lock()
+	defer id.Unlock()
+	_, ok := id.clock.(*counterClock)
+	return ok
+}
@@ -0,0 +1,80 @@
+package snowflake
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type manualClock struct{ ms int64 }
+
+func (c *manualClock) Millis() int64 { return c.ms }
+
+type memStore struct{ last int64 }
+
+func (s *memStore) Load() (int64, error) { return s.last, nil }
+func (s *memStore) Save(ts int64) error  { s.last = ts; return nil }
+
+func TestCounterMode(t *testing.T) {
+	clock := &manualClock{ms: 1_700_000_000_000}
+	store := &memStore{last: -1}
+	idworker, _ := NewIdWorker(1, WithClock(clock), WithStore(store))
+	first, _ := idworker.NextId()
+
+	idworker.UntrustClock()
+	if !idworker.CounterMode() {
+		t.Fatal("not in counter mode")
+	}
+	clock.ms -= 60_000 // 快照恢复, 时钟回到一分钟前
+	prev := first
+	for i := 0; i < 3000; i++ {
+		f, err := idworker.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f <= prev {
+			t.Fatalf("id %d not after %d", f, prev)
+		}
+		prev = f
+	}
+	if store.last <= 1_700_000_000_000 {
+		t.Errorf("counter not persisted: store at %d", store.last)
+	}
+	if err := idworker.TrustClock(); err == nil {
+		t.Error("clock behind the counter should not be trusted")
+	}
+	clock.ms = store.last + 1
+	if err := idworker.TrustClock(); err != nil || idworker.CounterMode() {
+		t.Fatalf("TrustClock: %v", err)
+	}
+	if f, err := idworker.NextId(); err != nil || f <= prev {
+		t.Errorf("after counter mode: %d, %v", f, err)
+	}
+}
+
+func TestCounterModeSeed(t *testing.T) {
+	now := timeGen()
+	clock := &manualClock{ms: now}
+	idworker, _ := NewIdWorker(1, WithClock(clock))
+	idworker.NextId()
+
+	clock.ms += 3_600_000 // 不可信的时钟跳到一小时后
+	idworker.UntrustClock()
+	idworker.NextId()
+	if got := idworker.DumpState().LastTimestamp; got != now+1 {
+		t.Errorf("counter seeded at %d, want %d past the last issued", got, now+1)
+	}
+
+	// 漂移监测比较底层时钟, 不读计数器
+	clock.ms = now
+	m := NewDriftMonitor(idworker, offsetSource(0), time.Minute, nil)
+	if s, err := m.Check(context.Background()); err != nil || s.Skew < -time.Second || s.Skew > time.Second {
+		t.Errorf("drift in counter mode: %s, %v", s.Skew, err)
+	}
+
+	fresh, _ := NewIdWorker(1, WithClock(clock))
+	fresh.UntrustClock()
+	if f, err := fresh.NextId(); err != nil || f.timestamp() != twepoch {
+		t.Errorf("counter before the first id should start at the epoch: %d, %v", f, err)
+	}
+}
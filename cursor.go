@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for a malformed or forged cursor.
+var ErrInvalidCursor = errors.New("snowflake: invalid cursor")
+
+const cursorMACSize = 8 // 截断的 HMAC 长度
+
+// EncodeCursor returns an opaque, URL-safe pagination cursor for id.
+func EncodeCursor(id ID) string {
+	return id.Base64URL()
+}
+
+// DecodeCursor returns the id of a cursor made by EncodeCursor.
+func DecodeCursor(s string) (ID, error) {
+	id, err := ParseBase64URL(s)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// CursorSigner makes cursors signed with an HMAC, so clients cannot craft
+// cursors pointing anywhere they like.
+type CursorSigner struct {
+	key []byte
+}
+
+// NewCursorSigner new a signer keyed by key.
+func NewCursorSigner(key []byte) *CursorSigner {
+	return &CursorSigner{key: key}
+}
+
+func (c *CursorSigner) mac(b []byte) []byte {
+	m := hmac.New(sha256.New, c.key)
+	m.Write(b)
+	return m.Sum(nil)[:cursorMACSize]
+}
+
+// EncodeCursor returns a signed cursor for id.
+func (c *CursorSigner) EncodeCursor(id ID) string {
+	b := id.IntBytes()
+	return base64.RawURLEncoding.EncodeToString(append(b[:], c.mac(b[:])...))
+}
+
+// DecodeCursor verifies a signed cursor and returns its id.
+func (c *CursorSigner) DecodeCursor(s string) (ID, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != 8+cursorMACSize {
+		return 0, ErrInvalidCursor
+	}
+	if !hmac.Equal(b[8:], c.mac(b[:8])) {
+		return 0, ErrInvalidCursor
+	}
+	return ID(binary.BigEndian.Uint64(b)), nil
+}
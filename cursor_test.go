@@ -0,0 +1,35 @@
+package snowflake
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	id := ID(1<<62 + 12345)
+	s := EncodeCursor(id)
+	if url.QueryEscape(s) != s {
+		t.Errorf("cursor %q is not URL-safe", s)
+	}
+	if got, err := DecodeCursor(s); err != nil || got != id {
+		t.Errorf("decode: got %d, %v", got, err)
+	}
+	if _, err := DecodeCursor("!!"); err != ErrInvalidCursor {
+		t.Errorf("garbage: got %v", err)
+	}
+}
+
+func TestSignedCursor(t *testing.T) {
+	signer := NewCursorSigner([]byte("k"))
+	id := ID(987654321)
+	s := signer.EncodeCursor(id)
+	if got, err := signer.DecodeCursor(s); err != nil || got != id {
+		t.Errorf("decode: got %d, %v", got, err)
+	}
+	if _, err := NewCursorSigner([]byte("other")).DecodeCursor(s); err != ErrInvalidCursor {
+		t.Errorf("wrong key: got %v", err)
+	}
+	if _, err := signer.DecodeCursor(EncodeCursor(id)); err != ErrInvalidCursor {
+		t.Errorf("unsigned cursor: got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+type cutover struct {
+	at     int64 // 切换的毫秒
+	layout Layout
+}
+
+// ScheduleCutover makes the worker switch to layout, including its epoch,
+// for every id issued at or after at. It is meant for long-lived services
+// approaching timestamp exhaustion.
+//
+// The cutover is refused unless the first id of the new layout at at is
+// greater than any id the old layout could issue before at, so the id spaces
+// before and after never overlap and ids keep increasing across it. In
+// practice the new layout keeps the epoch and gives the timestamp at least
+// the same shift, or moves the epoch back far enough to clear the old ids.
+func (id *IdWorker) ScheduleCutover(at time.Time, layout Layout) error {
+	if err := id.checkLayout(layout); err != nil {
+		return err
+	}
+	ts := toMillis(at)
+	id.Lock()
+	defer id.Unlock()
+	if layout.Micros || id.layout.Micros {
+		return errors.New("cutover to or from a microsecond layout is not supported")
+	}
+	if ts <= id.lastTimestamp {
+		return errors.New("cutover must be scheduled after the last issued timestamp")
+	}
+	next := layout
+	next.Epoch -= id.offset
+	if ts < next.Epoch || ts-next.Epoch > next.MaxTimestamp() {
+		return ErrTimeOutOfRange
+	}
+	old := id.currentLayout()
+	lastOld := ((ts - 1 - old.Epoch) << old.timestampShift()) | (-1 ^ (-1 << old.timestampShift()))
+	firstNew := (ts - next.Epoch) << next.timestampShift()
+	if firstNew <= lastOld {
+		return errors.New("cutover would overlap the id space of the current layout")
+	}
+	id.cutover = &cutover{at: ts, layout: layout}
+	return nil
+}
+
+// applyCutover switches layout once timestamp reaches the scheduled
+// cutover. Called with the lock held, before packing an id.
+func (id *IdWorker) applyCutover(timestamp int64) {
+	if id.cutover == nil || timestamp < id.cutover.at {
+		return
+	}
+	id.layout = id.cutover.layout
+	id.twepoch = id.cutover.layout.Epoch
+	id.cutover = nil
+	if id.sequence > id.layout.MaxSequence() {
+		id.sequence = id.startSequence()
+	}
+}
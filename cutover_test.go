@@ -0,0 +1,60 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleCutover(t *testing.T) {
+	idworker, _ := NewIdWorker(3)
+	before, _ := idworker.NextId()
+
+	// 纪元重置到现在, 新 ID 会落回旧 ID 的范围
+	at := time.Now().Add(20 * time.Millisecond)
+	next := DefaultLayout
+	next.Epoch = toMillis(at) - 1
+	if err := idworker.ScheduleCutover(at, next); err == nil {
+		t.Fatal("overlapping cutover should be refused")
+	}
+	// 新布局: 节点多 1 位, 序号少 1 位
+	next = DefaultLayout
+	next.SequenceBits--
+	next.NodeBits++
+	if err := idworker.ScheduleCutover(at, next); err != nil {
+		t.Fatal(err)
+	}
+
+	for time.Now().Before(at.Add(5 * time.Millisecond)) {
+		time.Sleep(time.Millisecond)
+	}
+	after, err := idworker.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after <= before {
+		t.Errorf("id %d after cutover not greater than %d", after, before)
+	}
+	if idworker.Layout() != next {
+		t.Errorf("layout after cutover: got %v", idworker.Layout())
+	}
+	if v := after.WithLayout(next); v.NodeId() != 3 || v.Timestamp() < toMillis(at) {
+		t.Errorf("post-cutover id decodes as %+v", v)
+	}
+}
+
+func TestWithLayoutOption(t *testing.T) {
+	small := DefaultLayout
+	small.NodeBits = 2
+	small.TimestampBits += 7
+	if _, err := NewIdWorker(100, WithLayout(small)); err == nil {
+		t.Error("node 100 should not fit 2 node bits")
+	}
+	idworker, err := NewIdWorker(3, WithLayout(small))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := idworker.NextId()
+	if id.WithLayout(small).NodeId() != 3 {
+		t.Errorf("node: got %d", id.WithLayout(small).NodeId())
+	}
+}
@@ -0,0 +1,246 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// The binary protocol starts with the 4 bytes of binaryMagic; a length
+// prefixed frame always starts with a zero byte, so both protocols share
+// the socket. Then, with uvarint integers:
+//
+//	request:  tag, count
+//	response: tag, uint8 status, then count and count 8-byte big-endian ids (status 0)
+//	          or the length and bytes of an error message
+//
+// Requests may be pipelined: the client sends as many as it likes without
+// waiting, and responses come back in request order, echoing the tag.
+const binaryMagic = "SFB2"
+
+// MaxBinaryBatch is the largest count of one binary request.
+const MaxBinaryBatch = 1 << 16
+
+const workerBatch = 100 // IdWorker.NextIds 单次上限
+
+func (s *Server) serveBinary(br *bufio.Reader, conn net.Conn) {
+	bw := bufio.NewWriter(conn)
+	var out []byte
+	for {
+		tag, err := binary.ReadUvarint(br)
+		if err != nil {
+			return
+		}
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return
+		}
+		out = binary.AppendUvarint(out[:0], tag)
+		if ids, err := s.nextIds(count); err != nil {
+			out = append(out, statusError)
+			out = binary.AppendUvarint(out, uint64(len(err.Error())))
+			out = append(out, err.Error()...)
+		} else {
+			out = append(out, statusOK)
+			out = binary.AppendUvarint(out, uint64(len(ids)))
+			for _, id := range ids {
+				out = binary.BigEndian.AppendUint64(out, uint64(id))
+			}
+		}
+		if _, err := bw.Write(out); err != nil {
+			return
+		}
+		// 流水线中还有请求时继续攒, 读空了再一起写出
+		if br.Buffered() == 0 {
+			if err := bw.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) nextIds(count uint64) ([]snowflake.ID, error) {
+	if count > MaxBinaryBatch {
+		return nil, errors.New(fmt.Sprintf("at most %d ids per request", MaxBinaryBatch))
+	}
+	ids := make([]snowflake.ID, 0, count)
+	for uint64(len(ids)) < count {
+		n := count - uint64(len(ids))
+		if n > workerBatch {
+			n = workerBatch
+		}
+		batch, err := s.worker.NextIds(int(n))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, batch...)
+	}
+	return ids, nil
+}
+
+// BinaryClient fetches ids from a local daemon over the binary protocol.
+// Concurrent calls are pipelined on the one connection.
+type BinaryClient struct {
+	wmu  sync.Mutex // 保证请求的写出顺序与 pending 一致
+	conn net.Conn
+	bw   *bufio.Writer
+	tag  uint64
+
+	mu      sync.Mutex
+	pending []*binaryCall
+	err     error // 连接失效的原因
+}
+
+type binaryCall struct {
+	tag  uint64
+	ids  []snowflake.ID
+	err  error
+	done chan struct{}
+}
+
+// DialBinary connects to the daemon listening on the unix socket at path.
+func DialBinary(path string) (*BinaryClient, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(conn, binaryMagic); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &BinaryClient{conn: conn, bw: bufio.NewWriter(conn)}
+	go c.readLoop()
+	return c, nil
+}
+
+// NextId get a snowflake id from the daemon.
+func (c *BinaryClient) NextId() (snowflake.ID, error) {
+	ids, err := c.NextIds(1)
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// NextIds get num snowflake ids from the daemon, up to MaxBinaryBatch.
+func (c *BinaryClient) NextIds(num int) ([]snowflake.ID, error) {
+	if num < 0 || num > MaxBinaryBatch {
+		return nil, errors.New("daemon: num out of range")
+	}
+	call := &binaryCall{done: make(chan struct{})}
+	c.wmu.Lock()
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		c.wmu.Unlock()
+		return nil, c.err
+	}
+	call.tag = c.tag
+	c.tag++
+	c.pending = append(c.pending, call)
+	c.mu.Unlock()
+	req := binary.AppendUvarint(nil, call.tag)
+	req = binary.AppendUvarint(req, uint64(num))
+	_, err := c.bw.Write(req)
+	if err == nil {
+		err = c.bw.Flush()
+	}
+	c.wmu.Unlock()
+	if err != nil {
+		c.fail(err)
+	}
+	<-call.done
+	return call.ids, call.err
+}
+
+func (c *BinaryClient) readLoop() {
+	br := bufio.NewReader(c.conn)
+	for {
+		call, err := c.readResponse(br)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		close(call.done)
+	}
+}
+
+// readResponse reads one response into the oldest pending call.
+func (c *BinaryClient) readResponse(br *bufio.Reader) (*binaryCall, error) {
+	tag, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	status, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if n > 8*MaxBinaryBatch {
+		return nil, errors.New("daemon: response too large")
+	}
+	c.mu.Lock()
+	if len(c.pending) == 0 || c.pending[0].tag != tag {
+		c.mu.Unlock()
+		return nil, errors.New("daemon: response out of order")
+	}
+	call := c.pending[0]
+	c.mu.Unlock()
+
+	// 读完整个响应才出队, 读到一半断开时由 fail 结束这个调用
+	var ids []snowflake.ID
+	var callErr error
+	if status != statusOK {
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(br, msg); err != nil {
+			return nil, err
+		}
+		callErr = errors.New(string(msg))
+	} else {
+		var b [8]byte
+		ids = make([]snowflake.ID, n)
+		for i := range ids {
+			if _, err := io.ReadFull(br, b[:]); err != nil {
+				return nil, err
+			}
+			ids[i] = snowflake.ID(binary.BigEndian.Uint64(b[:]))
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 || c.pending[0] != call {
+		return nil, c.err // 读取期间已被 Close 结束
+	}
+	c.pending = c.pending[1:]
+	call.ids, call.err = ids, callErr
+	return call, nil
+}
+
+// fail ends every pending call with err and refuses later ones.
+func (c *BinaryClient) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	for _, call := range c.pending {
+		call.err = c.err
+		close(call.done)
+	}
+	c.pending = nil
+}
+
+// Close closes the connection to the daemon.
+func (c *BinaryClient) Close() error {
+	c.fail(errors.New("daemon: client closed"))
+	return c.conn.Close()
+}
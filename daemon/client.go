@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Client fetches ids from a local daemon. It is safe for concurrent use.
+type Client struct {
+	sync.Mutex
+	conn net.Conn
+}
+
+// Dial connects to the daemon listening on the unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+// NextId get a snowflake id from the daemon.
+func (c *Client) NextId() (snowflake.ID, error) {
+	ids, err := c.NextIds(1)
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// NextIds get num snowflake ids from the daemon.
+func (c *Client) NextIds(num int) ([]snowflake.ID, error) {
+	if num < 0 || num > 0xffff {
+		return nil, errors.New("daemon: num out of range")
+	}
+	var req [2]byte
+	binary.BigEndian.PutUint16(req[:], uint16(num))
+	c.Lock()
+	defer c.Unlock()
+	if err := writeFrame(c.conn, req[:]); err != nil {
+		return nil, err
+	}
+	body, err := readFrame(c.conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, errors.New("daemon: empty response")
+	}
+	if body[0] != statusOK {
+		return nil, errors.New(string(body[1:]))
+	}
+	body = body[1:]
+	if len(body) != 8*num {
+		return nil, errors.New("daemon: short response")
+	}
+	ids := make([]snowflake.ID, num)
+	for i := range ids {
+		ids[i] = snowflake.ID(binary.BigEndian.Uint64(body[8*i:]))
+	}
+	return ids, nil
+}
+
+// Close closes the connection to the daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
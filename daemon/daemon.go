@@ -0,0 +1,206 @@
+// Package daemon lets one generator process serve ids to the other processes
+// on the same host over a unix domain socket, so many processes share a single
+// node ID instead of exhausting the node space.
+//
+// Every frame is a 4-byte big-endian length followed by the body:
+//
+//	request:  uint16 count
+//	response: uint8 status, then count 8-byte big-endian ids (status 0) or an error message
+//
+// Clients that need more ids or lower overhead use the binary protocol of
+// BinaryClient instead, which supports pipelined batches; see binary.go.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+const (
+	statusOK    = 0
+	statusError = 1
+
+	maxFrameSize = 1 << 16 // 单帧上限
+)
+
+// Server serves ids from one worker to local clients.
+type Server struct {
+	worker *snowflake.IdWorker
+
+	mu    sync.Mutex
+	ln    net.Listener
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// NewServer new a daemon server wrapping worker.
+func NewServer(worker *snowflake.IdWorker) *Server {
+	return &Server{worker: worker, conns: make(map[net.Conn]struct{})}
+}
+
+// ListenAndServe listens on the unix socket at path and serves clients. A
+// stale socket file left by a previous process is removed first.
+func (s *Server) ListenAndServe(path string) error {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts clients on ln until Close is called.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.ln == nil
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		s.mu.Lock()
+		if s.ln == nil {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting clients and closes open connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	ln := s.ln
+	s.ln = nil
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	var err error
+	if ln != nil {
+		err = ln.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// Shutdown stops accepting clients and lets every connection finish the
+// requests it has already received, closing each once it is idle. If ctx
+// ends first, the remaining connections are closed as by Close.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	ln := s.ln
+	s.ln = nil
+	for conn := range s.conns {
+		// 阻塞在读上的空闲连接立即返回, 已读到的请求照常处理并写回
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+	var err error
+	if ln != nil {
+		err = ln.Close()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		s.Close()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+	br := bufio.NewReader(conn)
+	if magic, err := br.Peek(len(binaryMagic)); err == nil && string(magic) == binaryMagic {
+		br.Discard(len(binaryMagic))
+		s.serveBinary(br, conn)
+		return
+	}
+	for {
+		body, err := readFrame(br)
+		if err != nil {
+			return
+		}
+		if err := writeFrame(conn, s.handle(body)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(body []byte) []byte {
+	if len(body) != 2 {
+		return errorBody(errors.New("bad request"))
+	}
+	ids, err := s.worker.NextIds(int(binary.BigEndian.Uint16(body)))
+	if err != nil {
+		return errorBody(err)
+	}
+	out := make([]byte, 1+8*len(ids))
+	out[0] = statusOK
+	for i, id := range ids {
+		binary.BigEndian.PutUint64(out[1+8*i:], uint64(id))
+	}
+	return out
+}
+
+func errorBody(err error) []byte {
+	return append([]byte{statusError}, err.Error()...)
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(head[:])
+	if n > maxFrameSize {
+		return nil, errors.New("frame too large")
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func writeFrame(w io.Writer, body []byte) error {
+	buf := make([]byte, 4+len(body))
+	binary.BigEndian.PutUint32(buf, uint32(len(body)))
+	copy(buf[4:], body)
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,237 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestDaemon(t *testing.T) {
+	worker, err := snowflake.NewIdWorker(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "snowflake.sock")
+	srv := NewServer(worker)
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(path) }()
+
+	var c *Client
+	for i := 0; i < 100 && c == nil; i++ {
+		if c, err = Dial(path); err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ids, err := c.NextIds(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[snowflake.ID]bool)
+	for _, id := range ids {
+		if id.NodeId() != 3 {
+			t.Errorf("node: got %d, want 3", id.NodeId())
+		}
+		if seen[id] {
+			t.Errorf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	if _, err := c.NextIds(1000); err == nil {
+		t.Error("server should reject too many ids")
+	}
+	if _, err := c.NextId(); err != nil {
+		t.Errorf("connection unusable after error: %v", err)
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBinaryClient(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(3)
+	path := filepath.Join(t.TempDir(), "snowflake.sock")
+	srv := NewServer(worker)
+	go srv.ListenAndServe(path)
+	defer srv.Close()
+
+	var c *BinaryClient
+	var err error
+	for i := 0; i < 100 && c == nil; i++ {
+		if c, err = DialBinary(path); err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var mu sync.Mutex
+	seen := make(map[snowflake.ID]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				ids, err := c.NextIds(1 + 50*g)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(ids) != 1+50*g {
+					t.Errorf("got %d ids, want %d", len(ids), 1+50*g)
+				}
+				mu.Lock()
+				for _, id := range ids {
+					if seen[id] {
+						t.Errorf("duplicate id %d", id)
+					}
+					seen[id] = true
+				}
+				mu.Unlock()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if _, err := c.NextIds(MaxBinaryBatch + 1); err == nil {
+		t.Error("client should reject too many ids")
+	}
+	if ids, err := c.NextIds(MaxBinaryBatch); err != nil || len(ids) != MaxBinaryBatch {
+		t.Errorf("largest batch: %d ids, %v", len(ids), err)
+	}
+
+	// 旧协议的客户端仍可连接同一个 socket
+	old, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	if _, err := old.NextId(); err != nil {
+		t.Errorf("length-prefixed client: %v", err)
+	}
+}
+
+func TestBinaryClientTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snowflake.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		io.ReadFull(br, make([]byte, len(binaryMagic)))
+		tag, _ := binary.ReadUvarint(br)
+		binary.ReadUvarint(br)
+		// 声明 2 个 ID, 只写出 1 个就断开
+		resp := binary.AppendUvarint(nil, tag)
+		resp = append(resp, statusOK)
+		resp = binary.AppendUvarint(resp, 2)
+		conn.Write(binary.BigEndian.AppendUint64(resp, 1))
+	}()
+
+	c, err := DialBinary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.NextIds(2)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("truncated response should fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call blocked on a truncated response")
+	}
+}
+
+func BenchmarkBinaryClient(b *testing.B) {
+	worker, _ := snowflake.NewIdWorker(3)
+	path := filepath.Join(b.TempDir(), "snowflake.sock")
+	srv := NewServer(worker)
+	go srv.ListenAndServe(path)
+	defer srv.Close()
+	var c *BinaryClient
+	var err error
+	for i := 0; i < 100 && c == nil; i++ {
+		if c, err = DialBinary(path); err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1000 {
+		if _, err := c.NextIds(1000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(3)
+	path := filepath.Join(t.TempDir(), "snowflake.sock")
+	srv := NewServer(worker)
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(path) }()
+	var c *Client
+	var err error
+	for i := 0; i < 100 && c == nil; i++ {
+		if c, err = Dial(path); err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.NextId(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("idle connection should not hold up shutdown: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.NextId(); err == nil {
+		t.Error("connection should be closed after shutdown")
+	}
+	if _, err := Dial(path); err == nil {
+		t.Error("server should not accept after shutdown")
+	}
+}
@@ -0,0 +1,56 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// String describes the worker's configuration and state for debug dumps and
+// panic logs. It never blocks: while another goroutine holds the worker
+// only busy is reported, since none of its fields can be read safely.
+func (id *IdWorker) String() string {
+	if !id.TryLock() {
+		return "IdWorker{busy}"
+	}
+	defer id.Unlock()
+	return fmt.Sprintf("IdWorker{node=%d district=%d tag=%s epoch=%d layout=%s lastTimestamp=%d sequence=%d}",
+		id.nodeId, id.districtId, Tag(id.tag), id.epoch(), id.currentLayout(), id.lastTimestamp, id.sequence)
+}
+
+// GoString is used by the %#v verb.
+func (id *IdWorker) GoString() string {
+	return id.String()
+}
+
+// WorkerState is a snapshot of a worker for monitoring scripts.
+type WorkerState struct {
+	NodeId        int64       `json:"node_id"`
+	DistrictId    int64       `json:"district_id"`
+	Tag           string      `json:"tag"`
+	Epoch         int64       `json:"epoch"`          // unix 毫秒
+	Layout        string      `json:"layout"`         // 见 Layout.String
+	LastTimestamp int64       `json:"last_timestamp"` // -1 表示尚未生成
+	Sequence      int64       `json:"sequence"`
+	Uptime        float64     `json:"uptime_seconds"`
+	Frozen        bool        `json:"frozen"`
+	Stats         WorkerStats `json:"stats"`
+}
+
+// DumpState returns a snapshot of the worker's configuration, state and
+// counters.
+func (id *IdWorker) DumpState() WorkerState {
+	id.Lock()
+	defer id.Unlock()
+	return WorkerState{
+		NodeId:        id.nodeId,
+		DistrictId:    id.districtId,
+		Tag:           Tag(id.tag).String(),
+		Epoch:         id.epoch(),
+		Layout:        id.currentLayout().String(),
+		LastTimestamp: id.lastTimestamp,
+		Sequence:      id.sequence,
+		Uptime:        time.Since(id.created).Seconds(),
+		Frozen:        id.frozen != nil,
+		Stats:         id.stats,
+	}
+}
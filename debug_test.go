@@ -0,0 +1,33 @@
+package snowflake
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWorkerString(t *testing.T) {
+	idworker, _ := NewOrderIdWorker(12)
+	idworker.NextId()
+	s := fmt.Sprintf("%v %#v", idworker, idworker)
+	for _, want := range []string{"node=12", "tag=order", "layout=39-2-3-9-10", "sequence=0"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q missing %q", s, want)
+		}
+	}
+	idworker.Lock()
+	s = idworker.String()
+	idworker.Unlock()
+	if s != "IdWorker{busy}" {
+		t.Errorf("locked worker: got %q", s)
+	}
+}
+
+func TestDumpState(t *testing.T) {
+	idworker, _ := NewIdWorker(7)
+	idworker.NextIds(3)
+	s := idworker.DumpState()
+	if s.NodeId != 7 || s.DistrictId != 1 || s.Epoch != twepoch || s.Layout != DefaultLayout.String() || s.Stats.Generated != 3 || s.LastTimestamp <= 0 || s.Frozen {
+		t.Errorf("got %+v", s)
+	}
+}
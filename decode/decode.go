@@ -0,0 +1,167 @@
+// Package decode reads snowflake ids without generating them. It depends on
+// nothing but the standard library and has no clock, locking or storage
+// code, for lightweight consumers such as gomobile bindings and WASM
+// frontends that only display what an id contains.
+//
+// Its layouts and encodings mirror those of the snowflake package.
+package decode
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Layout describes how the 63 usable bits of an id are split; see
+// snowflake.Layout. It decodes the JSON descriptor of snowflake.Layout.
+type Layout struct {
+	TimestampBits uint  `json:"timestamp_bits"`
+	TagBits       uint  `json:"tag_bits"`
+	DistrictBits  uint  `json:"district_bits"`
+	NodeBits      uint  `json:"node_bits"`
+	SequenceBits  uint  `json:"sequence_bits"`
+	Epoch         int64 `json:"epoch"`            // 毫秒
+	Micros        bool  `json:"micros,omitempty"` // 时间戳以微秒计
+}
+
+// DefaultLayout, TaggedLayout, LegacyLayout and MicroLayout are the layouts
+// of the same name in the snowflake package.
+var (
+	DefaultLayout = Layout{TimestampBits: 39, DistrictBits: 5, NodeBits: 9, SequenceBits: 10, Epoch: 1542944160000}
+	TaggedLayout  = Layout{TimestampBits: 39, TagBits: 2, DistrictBits: 3, NodeBits: 9, SequenceBits: 10, Epoch: 1542944160000}
+	LegacyLayout  = Layout{TimestampBits: 39, DistrictBits: 5, NodeBits: 9, SequenceBits: 10, Epoch: 1542944160000}
+	MicroLayout   = Layout{TimestampBits: 48, DistrictBits: 1, NodeBits: 6, SequenceBits: 8, Epoch: 1735689600000, Micros: true}
+)
+
+func mask(bits uint) int64 { return -1 ^ (-1 << bits) }
+
+func (l Layout) nodeShift() uint      { return l.SequenceBits }
+func (l Layout) districtShift() uint  { return l.SequenceBits + l.NodeBits }
+func (l Layout) tagShift() uint       { return l.SequenceBits + l.NodeBits + l.DistrictBits }
+func (l Layout) timestampShift() uint { return l.tagShift() + l.TagBits }
+
+// ID is a snowflake id.
+type ID int64
+
+// Parse decodes the decimal form of an id.
+func Parse(s string) (ID, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New(fmt.Sprintf("invalid snowflake id %q", s))
+	}
+	return ID(n), nil
+}
+
+// ParseRawBytes decodes the 8 big-endian bytes of an id.
+func ParseRawBytes(b []byte) (ID, error) {
+	if len(b) != 8 {
+		return 0, errors.New(fmt.Sprintf("raw snowflake id must be 8 bytes, got %d", len(b)))
+	}
+	return ID(binary.BigEndian.Uint64(b)), nil
+}
+
+// ParseBase64URL decodes the 11 character form of snowflake.ID.Base64URL.
+func ParseBase64URL(s string) (ID, error) {
+	if len(s) != 11 {
+		return 0, errors.New(fmt.Sprintf("base64 snowflake id must be 11 characters, got %d", len(s)))
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid base64 snowflake id %q", s))
+	}
+	return ParseRawBytes(b)
+}
+
+func (f ID) String() string {
+	return strconv.FormatInt(int64(f), 10)
+}
+
+// WithLayout returns a view decoding f with layout.
+func (f ID) WithLayout(layout Layout) LayoutID {
+	return LayoutID{ID: f, Layout: layout}
+}
+
+// Timestamp, Time, DistrictId, NodeId and Sequence decode f with the
+// default layout, which has no tag; decode tags with WithLayout.
+func (f ID) Timestamp() int64  { return f.WithLayout(DefaultLayout).Timestamp() }
+func (f ID) Time() time.Time   { return f.WithLayout(DefaultLayout).Time() }
+func (f ID) DistrictId() int64 { return f.WithLayout(DefaultLayout).DistrictId() }
+func (f ID) NodeId() int64     { return f.WithLayout(DefaultLayout).NodeId() }
+func (f ID) Sequence() int64   { return f.WithLayout(DefaultLayout).Sequence() }
+
+// LayoutID is a view of an id decoded with an explicit layout.
+type LayoutID struct {
+	ID     ID
+	Layout Layout
+}
+
+// Micros returns the unix microsecond the id was issued at; for
+// millisecond layouts it is a whole millisecond.
+func (v LayoutID) Micros() int64 {
+	ticks := int64(v.ID) >> v.Layout.timestampShift() & mask(v.Layout.TimestampBits)
+	if v.Layout.Micros {
+		return v.Layout.Epoch*1000 + ticks
+	}
+	return (v.Layout.Epoch + ticks) * 1000
+}
+
+// Timestamp returns the unix millisecond the id was issued at.
+func (v LayoutID) Timestamp() int64 {
+	return floorDiv(v.Micros(), 1000)
+}
+
+// Time returns the time the id was issued at, in UTC.
+func (v LayoutID) Time() time.Time {
+	return time.UnixMicro(v.Micros()).UTC()
+}
+
+func (v LayoutID) Tag() int64 {
+	return int64(v.ID) >> v.Layout.tagShift() & mask(v.Layout.TagBits)
+}
+
+func (v LayoutID) DistrictId() int64 {
+	return int64(v.ID) >> v.Layout.districtShift() & mask(v.Layout.DistrictBits)
+}
+
+func (v LayoutID) NodeId() int64 {
+	return int64(v.ID) >> v.Layout.nodeShift() & mask(v.Layout.NodeBits)
+}
+
+func (v LayoutID) Sequence() int64 {
+	return int64(v.ID) & mask(v.Layout.SequenceBits)
+}
+
+// Fields is every field of an id, ready to be marshaled.
+type Fields struct {
+	ID         string `json:"id"`
+	Timestamp  int64  `json:"timestamp"` // unix 毫秒
+	Time       string `json:"time"`      // RFC 3339, UTC
+	Tag        int64  `json:"tag"`
+	DistrictId int64  `json:"district_id"`
+	NodeId     int64  `json:"node_id"`
+	Sequence   int64  `json:"sequence"`
+}
+
+// Fields decodes every field of the id.
+func (v LayoutID) Fields() Fields {
+	return Fields{
+		ID:         v.ID.String(),
+		Timestamp:  v.Timestamp(),
+		Time:       v.Time().Format(time.RFC3339Nano),
+		Tag:        v.Tag(),
+		DistrictId: v.DistrictId(),
+		NodeId:     v.NodeId(),
+		Sequence:   v.Sequence(),
+	}
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,45 @@
+package decode
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	f, err := Parse("123456789012345")
+	if err != nil || f != 123456789012345 {
+		t.Fatalf("got %d, %v", f, err)
+	}
+	for _, s := range []string{"", "abc", "-1", "99999999999999999999"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) should fail", s)
+		}
+	}
+	if g, err := ParseBase64URL("AAAHBzTOUX8"); err != nil || g.String() != "7727532102015" {
+		t.Errorf("base64: got %d, %v", g, err)
+	}
+}
+
+func TestFields(t *testing.T) {
+	// 2024-01-01T00:00:00Z, tag 1, district 2, node 3, sequence 4
+	ms := int64(1704067200000)
+	f := ID((ms-TaggedLayout.Epoch)<<24 | 1<<22 | 2<<19 | 3<<10 | 4)
+	got := f.WithLayout(TaggedLayout).Fields()
+	want := Fields{ID: f.String(), Timestamp: ms, Time: "2024-01-01T00:00:00Z", Tag: 1, DistrictId: 2, NodeId: 3, Sequence: 4}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	m := ID(1500 << 15).WithLayout(MicroLayout)
+	if m.Micros() != MicroLayout.Epoch*1000+1500 || m.Timestamp() != MicroLayout.Epoch+1 {
+		t.Errorf("micro: got %d us, %d ms", m.Micros(), m.Timestamp())
+	}
+}
+
+func TestLayoutJSON(t *testing.T) {
+	var l Layout
+	desc := `{"version":1,"timestamp_bits":39,"tag_bits":2,"district_bits":3,"node_bits":9,"sequence_bits":10,"epoch":1542944160000,"unit":"ms","timestamp_shift":24,"tag_shift":22,"district_shift":19,"node_shift":10}`
+	if err := json.Unmarshal([]byte(desc), &l); err != nil || l != TaggedLayout {
+		t.Errorf("got %+v, %v", l, err)
+	}
+}
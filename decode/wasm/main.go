@@ -0,0 +1,86 @@
+//go:build js && wasm
+
+// Command wasm exposes the decode package to JavaScript, so web frontends
+// can show what an id contains without asking a server. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o snowflake.wasm ./decode/wasm
+//
+// and load it with the wasm_exec.js of the same Go release. It defines the
+// global functions
+//
+//	snowflakeDecode(id, layout?) -> {id, timestamp, time, tag, district_id, node_id, sequence} or {error}
+//	snowflakeTime(id, layout?)   -> RFC 3339 string, or "" for an invalid id
+//
+// Ids are passed as decimal strings, since JavaScript numbers cannot hold
+// them exactly. layout is "default", "tagged", "legacy", "micro" or the JSON layout
+// descriptor of snowflake.Layout, and defaults to "default".
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/sakishum/go_snowflake/decode"
+)
+
+var layouts = map[string]decode.Layout{
+	"default": decode.DefaultLayout,
+	"legacy":  decode.LegacyLayout,
+	"tagged":  decode.TaggedLayout,
+	"micro":   decode.MicroLayout,
+}
+
+func main() {
+	js.Global().Set("snowflakeDecode", js.FuncOf(decodeFunc))
+	js.Global().Set("snowflakeTime", js.FuncOf(timeFunc))
+	select {} // 保持运行, 供 JS 回调
+}
+
+// parseArgs reads the id and optional layout arguments.
+func parseArgs(args []js.Value) (decode.LayoutID, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return decode.LayoutID{}, errors.New("id must be a decimal string")
+	}
+	f, err := decode.Parse(args[0].String())
+	if err != nil {
+		return decode.LayoutID{}, err
+	}
+	l := decode.DefaultLayout
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		name := args[1].String()
+		var ok bool
+		if l, ok = layouts[name]; !ok {
+			if err := json.Unmarshal([]byte(name), &l); err != nil {
+				return decode.LayoutID{}, errors.New(fmt.Sprintf("unknown layout %q", name))
+			}
+		}
+	}
+	return f.WithLayout(l), nil
+}
+
+func decodeFunc(this js.Value, args []js.Value) any {
+	v, err := parseArgs(args)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	fields := v.Fields()
+	return map[string]any{
+		"id":          fields.ID,
+		"timestamp":   fields.Timestamp,
+		"time":        fields.Time,
+		"tag":         fields.Tag,
+		"district_id": fields.DistrictId,
+		"node_id":     fields.NodeId,
+		"sequence":    fields.Sequence,
+	}
+}
+
+func timeFunc(this js.Value, args []js.Value) any {
+	v, err := parseArgs(args)
+	if err != nil {
+		return ""
+	}
+	return v.Fields().Time
+}
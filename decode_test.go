@@ -0,0 +1,34 @@
+package snowflake
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/sakishum/go_snowflake/decode"
+)
+
+// TestDecodeMirrors checks the decode package agrees with this one.
+func TestDecodeMirrors(t *testing.T) {
+	pairs := []struct {
+		l Layout
+		d decode.Layout
+	}{
+		{DefaultLayout, decode.DefaultLayout},
+		{TaggedLayout, decode.TaggedLayout},
+		{LegacyLayout, decode.LegacyLayout},
+		{MicroLayout, decode.MicroLayout},
+	}
+	r := rand.New(rand.NewSource(1))
+	for _, p := range pairs {
+		if d := (decode.Layout{TimestampBits: p.l.TimestampBits, TagBits: p.l.TagBits, DistrictBits: p.l.DistrictBits, NodeBits: p.l.NodeBits, SequenceBits: p.l.SequenceBits, Epoch: p.l.Epoch, Micros: p.l.Micros}); d != p.d {
+			t.Errorf("layout %v: decode has %+v", p.l, p.d)
+		}
+		for i := 0; i < 1000; i++ {
+			f := ID(r.Int63() & int64(p.l.MaxID()))
+			v, d := f.WithLayout(p.l), decode.ID(f).WithLayout(p.d)
+			if v.Timestamp() != d.Timestamp() || v.Micros() != d.Micros() || int64(v.Tag()) != d.Tag() || v.DistrictId() != d.DistrictId() || v.NodeId() != d.NodeId() || v.Sequence() != d.Sequence() {
+				t.Fatalf("id %d of layout %v decodes differently", f, p.l)
+			}
+		}
+	}
+}
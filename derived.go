@@ -0,0 +1,54 @@
+package snowflake
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DerivedIDWindow is the time window of derived ids: deriving the same
+// content again within one window yields the same id.
+var DerivedIDWindow = time.Minute
+
+// derivedDistrictId is reserved for derived ids: workers refuse it in any
+// layout with district bits, so derived ids cannot collide with generated
+// ones. Layouts without district bits only have district 0.
+const derivedDistrictId = 0
+
+// checkDistrict checks districtId fits in l and is not reserved.
+func checkDistrict(l Layout, districtId int64) error {
+	if districtId > l.MaxDistrictId() || districtId < 0 {
+		return errors.New(fmt.Sprintf("district must be between 0 and %d", l.MaxDistrictId()))
+	}
+	if districtId == derivedDistrictId && l.DistrictBits > 0 {
+		return errors.New(fmt.Sprintf("district %d is reserved for derived ids", derivedDistrictId))
+	}
+	return nil
+}
+
+// NewDerivedID derive an id from namespace and data for idempotent creation
+// flows. The timestamp is the start of the current DerivedIDWindow and the
+// node/sequence bits come from a SHA-256 of namespace and data.
+//
+// Only 19 bits of hash are kept, so two different contents in the same
+// namespace and window collide with probability about n*n/2^20; a retry that
+// straddles a window boundary gets a different id.
+func NewDerivedID(namespace, data []byte) ID {
+	return newDerivedID(namespace, data, time.Now())
+}
+
+func newDerivedID(namespace, data []byte, now time.Time) ID {
+	h := sha256.New()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(namespace)))
+	h.Write(n[:])
+	h.Write(namespace)
+	h.Write(data)
+	sum := h.Sum(nil)
+	low := int64(binary.BigEndian.Uint64(sum)) & (nodeIdMask | sequenceMask)
+
+	timestamp := toMillis(now.Truncate(DerivedIDWindow))
+	return ID(((timestamp - twepoch) << timestampLeftShift) | (derivedDistrictId << districtIdShift) | low)
+}
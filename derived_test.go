@@ -0,0 +1,24 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDerivedID(t *testing.T) {
+	now := time.Now().Truncate(DerivedIDWindow)
+	a := newDerivedID([]byte("order"), []byte("req-1"), now)
+	b := newDerivedID([]byte("order"), []byte("req-1"), now.Add(DerivedIDWindow-time.Millisecond))
+	if a != b {
+		t.Errorf("retry within window: got %d and %d", a, b)
+	}
+	if c := newDerivedID([]byte("user"), []byte("req-1"), now); c == a {
+		t.Error("different namespaces should derive different ids")
+	}
+	if c := newDerivedID([]byte("order"), []byte("req-1"), now.Add(DerivedIDWindow)); c == a {
+		t.Error("next window should derive a different id")
+	}
+	if a.DistrictId() != derivedDistrictId {
+		t.Errorf("district: got %d, want %d", a.DistrictId(), derivedDistrictId)
+	}
+}
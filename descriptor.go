@@ -0,0 +1,211 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DescriptorVersion is the version of the layout descriptor format.
+const DescriptorVersion = 1
+
+// LayoutDescriptor is the canonical machine-readable form of a layout, for
+// implementations in other languages: the bit widths and epoch, plus the
+// shifts and time unit they imply so a consumer never derives them itself.
+type LayoutDescriptor struct {
+	Version        int    `json:"version"`
+	TimestampBits  uint   `json:"timestamp_bits"`
+	TagBits        uint   `json:"tag_bits"`
+	DistrictBits   uint   `json:"district_bits"`
+	NodeBits       uint   `json:"node_bits"`
+	SequenceBits   uint   `json:"sequence_bits"`
+	Epoch          int64  `json:"epoch"` // unix 毫秒
+	Micros         bool   `json:"micros,omitempty"`
+	Unit           string `json:"unit"` // 时间戳单位, "ms" 或 "us"
+	TimestampShift uint   `json:"timestamp_shift"`
+	TagShift       uint   `json:"tag_shift"`
+	DistrictShift  uint   `json:"district_shift"`
+	NodeShift      uint   `json:"node_shift"`
+}
+
+// Descriptor returns the descriptor of l.
+func (l Layout) Descriptor() LayoutDescriptor {
+	unit := "ms"
+	if l.Micros {
+		unit = "us"
+	}
+	return LayoutDescriptor{
+		Version:        DescriptorVersion,
+		TimestampBits:  l.TimestampBits,
+		TagBits:        l.TagBits,
+		DistrictBits:   l.DistrictBits,
+		NodeBits:       l.NodeBits,
+		SequenceBits:   l.SequenceBits,
+		Epoch:          l.Epoch,
+		Micros:         l.Micros,
+		Unit:           unit,
+		TimestampShift: l.timestampShift(),
+		TagShift:       l.tagShift(),
+		DistrictShift:  l.districtShift(),
+		NodeShift:      l.nodeShift(),
+	}
+}
+
+// Layout returns the layout of d, failing when its version is unknown or
+// its derived fields disagree with the bit widths.
+func (d LayoutDescriptor) Layout() (Layout, error) {
+	if d.Version > DescriptorVersion {
+		return Layout{}, errors.New(fmt.Sprintf("unsupported layout descriptor version %d", d.Version))
+	}
+	l := Layout{
+		TimestampBits: d.TimestampBits,
+		TagBits:       d.TagBits,
+		DistrictBits:  d.DistrictBits,
+		NodeBits:      d.NodeBits,
+		SequenceBits:  d.SequenceBits,
+		Epoch:         d.Epoch,
+		Micros:        d.Micros,
+	}
+	// 旧格式没有版本号与派生字段
+	if d.Version == 0 {
+		return l, nil
+	}
+	if want := l.Descriptor(); d != want {
+		return Layout{}, errors.New(fmt.Sprintf("layout descriptor %+v is inconsistent, want %+v", d, want))
+	}
+	return l, nil
+}
+
+// MarshalJSON encodes l as its descriptor.
+func (l Layout) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Descriptor())
+}
+
+// UnmarshalJSON decodes a descriptor, or the plain fields of a layout
+// encoded before descriptors existed.
+func (l *Layout) UnmarshalJSON(b []byte) error {
+	var d LayoutDescriptor
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+	v, err := d.Layout()
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// ToProto encodes the descriptor of l in protobuf wire format, as the
+// message
+//
+//	message LayoutDescriptor {
+//	  uint32 version         = 1;
+//	  uint32 timestamp_bits  = 2;
+//	  uint32 tag_bits        = 3;
+//	  uint32 district_bits   = 4;
+//	  uint32 node_bits       = 5;
+//	  uint32 sequence_bits   = 6;
+//	  int64  epoch           = 7;
+//	  bool   micros          = 8;
+//	  string unit            = 9;
+//	  uint32 timestamp_shift = 10;
+//	  uint32 tag_shift       = 11;
+//	  uint32 district_shift  = 12;
+//	  uint32 node_shift      = 13;
+//	}
+//
+// Fields are written in order and zero values are omitted, so the encoding
+// of a layout is canonical and can be compared byte for byte.
+func (l Layout) ToProto() []byte {
+	d := l.Descriptor()
+	var b []byte
+	varint := func(field int, v uint64) {
+		if v != 0 {
+			b = binary.AppendUvarint(b, uint64(field)<<3)
+			b = binary.AppendUvarint(b, v)
+		}
+	}
+	varint(1, uint64(d.Version))
+	varint(2, uint64(d.TimestampBits))
+	varint(3, uint64(d.TagBits))
+	varint(4, uint64(d.DistrictBits))
+	varint(5, uint64(d.NodeBits))
+	varint(6, uint64(d.SequenceBits))
+	varint(7, uint64(d.Epoch))
+	if d.Micros {
+		varint(8, 1)
+	}
+	b = binary.AppendUvarint(b, 9<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(d.Unit)))
+	b = append(b, d.Unit...)
+	varint(10, uint64(d.TimestampShift))
+	varint(11, uint64(d.TagShift))
+	varint(12, uint64(d.DistrictShift))
+	varint(13, uint64(d.NodeShift))
+	return b
+}
+
+// LayoutFromProto decodes a layout encoded by ToProto. Unknown fields are
+// skipped so newer encoders stay readable.
+func LayoutFromProto(b []byte) (Layout, error) {
+	var d LayoutDescriptor
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return Layout{}, errors.New("invalid layout descriptor")
+		}
+		b = b[n:]
+		field, wire := key>>3, key&7
+		switch wire {
+		case 0:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return Layout{}, errors.New("invalid layout descriptor")
+			}
+			b = b[n:]
+			switch field {
+			case 1:
+				d.Version = int(v)
+			case 2:
+				d.TimestampBits = uint(v)
+			case 3:
+				d.TagBits = uint(v)
+			case 4:
+				d.DistrictBits = uint(v)
+			case 5:
+				d.NodeBits = uint(v)
+			case 6:
+				d.SequenceBits = uint(v)
+			case 7:
+				d.Epoch = int64(v)
+			case 8:
+				d.Micros = v != 0
+			case 10:
+				d.TimestampShift = uint(v)
+			case 11:
+				d.TagShift = uint(v)
+			case 12:
+				d.DistrictShift = uint(v)
+			case 13:
+				d.NodeShift = uint(v)
+			}
+		case 2:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return Layout{}, errors.New("invalid layout descriptor")
+			}
+			if field == 9 {
+				d.Unit = string(b[n : n+int(size)])
+			}
+			b = b[n+int(size):]
+		default:
+			return Layout{}, errors.New(fmt.Sprintf("unsupported wire type %d in layout descriptor", wire))
+		}
+	}
+	if d.Version == 0 {
+		return Layout{}, errors.New("layout descriptor has no version")
+	}
+	return d.Layout()
+}
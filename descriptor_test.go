@@ -0,0 +1,38 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLayoutDescriptor(t *testing.T) {
+	for _, l := range []Layout{DefaultLayout, MicroLayout, {TimestampBits: 40, NodeBits: 8, SequenceBits: 15, Epoch: 1}} {
+		b, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Layout
+		if err := json.Unmarshal(b, &got); err != nil || got != l {
+			t.Errorf("json round trip of %v: got %+v, %v", l, got, err)
+		}
+		if got, err := LayoutFromProto(l.ToProto()); err != nil || got != l {
+			t.Errorf("proto round trip of %v: got %+v, %v", l, got, err)
+		}
+	}
+
+	b, _ := json.Marshal(DefaultLayout)
+	if !strings.Contains(string(b), `"timestamp_shift":24`) || !strings.Contains(string(b), `"unit":"ms"`) {
+		t.Errorf("descriptor misses derived fields: %s", b)
+	}
+
+	// 旧格式仍可解析
+	var old Layout
+	if err := json.Unmarshal([]byte(`{"timestamp_bits":41,"tag_bits":0,"district_bits":5,"node_bits":5,"sequence_bits":12,"epoch":1}`), &old); err != nil || old.NodeBits != 5 || old.Epoch != 1 {
+		t.Errorf("legacy layout: got %+v, %v", old, err)
+	}
+	bad := strings.Replace(string(b), `"node_shift":10`, `"node_shift":11`, 1)
+	if err := json.Unmarshal([]byte(bad), &old); err == nil {
+		t.Error("inconsistent shifts should fail")
+	}
+}
@@ -0,0 +1,36 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NextIdForDistrict get a snowflake id tagged with districtId instead of the
+// worker's own district, so one gateway can serve several regions. Ids of
+// all districts share the worker's sequence, so they never collide. The
+// timestamp counts from the district's epoch when WithDistrictEpochs set one.
+func (id *IdWorker) NextIdForDistrict(districtId int64) (ID, error) {
+	id.Lock()
+	defer id.Unlock()
+	if err := checkDistrict(id.layout, districtId); err != nil {
+		return 0, err
+	}
+	if _, err := id.nextid(); err != nil {
+		return 0, err
+	}
+	return id.packFor(id.lastTimestamp, id.sequence, districtId), nil
+}
+
+// WithDistrictId makes the worker issue ids in districtId instead of the
+// default district 1. Apply it before WithLayout when the layout has fewer
+// district bits than the default. District 0 is only accepted in layouts
+// without district bits; it is reserved for derived ids otherwise.
+func WithDistrictId(districtId int64) Option {
+	return func(id *IdWorker) error {
+		if districtId > id.layout.MaxDistrictId() || districtId < 0 {
+			return errors.New(fmt.Sprintf("district must be between 0 and %d", id.layout.MaxDistrictId()))
+		}
+		id.districtId = districtId
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestNextIdForDistrict(t *testing.T) {
+	idworker, _ := NewIdWorker(3)
+	if _, err := idworker.NextIdForDistrict(derivedDistrictId); err == nil {
+		t.Error("district reserved for derived ids should fail")
+	}
+	for d := int64(1); d <= maxDistrictId; d++ {
+		id, err := idworker.NextIdForDistrict(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.DistrictId() != d || id.NodeId() != 3 {
+			t.Errorf("district %d: got district %d node %d", d, id.DistrictId(), id.NodeId())
+		}
+	}
+	if _, err := idworker.NextIdForDistrict(maxDistrictId + 1); err == nil {
+		t.Error("district out of range should fail")
+	}
+}
+
+func TestWithDistrictId(t *testing.T) {
+	l := DefaultLayout
+	l.DistrictBits = 0
+	if _, err := NewIdWorker(3, WithLayout(l)); err == nil {
+		t.Error("default district should not fit in 0 district bits")
+	}
+	idworker, err := NewIdWorker(3, WithDistrictId(0), WithLayout(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := idworker.NextId()
+	if v := f.WithLayout(l); v.NodeId() != 3 || v.DistrictId() != 0 {
+		t.Errorf("got node %d district %d", v.NodeId(), v.DistrictId())
+	}
+	if _, err := NewIdWorker(3, WithDistrictId(0)); err == nil {
+		t.Error("district reserved for derived ids should fail")
+	}
+	if _, err := NewIdWorker(3, WithDistrictId(maxDistrictId+1)); err == nil {
+		t.Error("district out of range should fail")
+	}
+}
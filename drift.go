@@ -0,0 +1,178 @@
+package snowflake
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// expvarDrift is the last skew measured by any DriftMonitor.
+var expvarDrift = expvar.NewInt("snowflake.drift_ms") // 最近一次测得的时钟偏差
+
+// TimeSource tells the reference time.
+type TimeSource interface {
+	Now(ctx context.Context) (time.Time, error)
+}
+
+// NTPSource asks an NTP server, e.g. "pool.ntp.org:123", with SNTP.
+type NTPSource struct {
+	Addr    string
+	Timeout time.Duration // 默认 2 秒
+}
+
+// ntpEpochOffset is the number of seconds from 1900 to 1970.
+const ntpEpochOffset = 2208988800
+
+// Now returns the server time, corrected for the round trip.
+func (s NTPSource) Now(ctx context.Context) (time.Time, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "udp", s.Addr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	req := make([]byte, 48)
+	req[0] = 0x23 // LI 0, 版本 4, 客户端模式
+	t1 := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return time.Time{}, err
+	}
+	resp := make([]byte, 48)
+	if n, err := conn.Read(resp); err != nil {
+		return time.Time{}, err
+	} else if n < 48 {
+		return time.Time{}, errors.New(fmt.Sprintf("ntp: short response of %d bytes", n))
+	}
+	t4 := time.Now()
+	t2, t3 := ntpTime(resp[32:40]), ntpTime(resp[40:48])
+	offset := (t2.Sub(t1) + t3.Sub(t4)) / 2
+	return t4.Add(offset), nil
+}
+
+// ntpTime decodes a 64-bit NTP timestamp.
+func ntpTime(b []byte) time.Time {
+	sec := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+	frac := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(sec, frac*1e9>>32)
+}
+
+// Quorum is the median of several time sources, e.g. peer generators, and
+// needs a majority of them to answer.
+type Quorum []TimeSource
+
+func (q Quorum) Now(ctx context.Context) (time.Time, error) {
+	var offsets []time.Duration
+	for _, s := range q {
+		if t, err := s.Now(ctx); err == nil {
+			offsets = append(offsets, time.Until(t))
+		}
+	}
+	if len(offsets) <= len(q)/2 {
+		return time.Time{}, errors.New(fmt.Sprintf("quorum: only %d of %d sources answered", len(offsets), len(q)))
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return time.Now().Add(offsets[len(offsets)/2]), nil
+}
+
+// DriftSample is one comparison of a worker's clock to the reference.
+type DriftSample struct {
+	At   time.Time
+	Skew time.Duration // 正数表示 worker 时钟快于参考时间
+}
+
+// DriftMonitor compares the clock of a worker to a reference time source
+// in the background and alerts when they drift apart by more than a
+// threshold, before a correction of the host clock turns into a rollback.
+// While the skew is above half the threshold it checks twice as often.
+type DriftMonitor struct {
+	worker  *IdWorker
+	source  TimeSource
+	onAlert func(DriftSample)
+
+	mu        sync.Mutex
+	last      DriftSample
+	threshold time.Duration
+}
+
+// NewDriftMonitor new a drift monitor of worker against source, calling
+// onAlert for every sample whose skew exceeds threshold either way.
+func NewDriftMonitor(worker *IdWorker, source TimeSource, threshold time.Duration, onAlert func(DriftSample)) *DriftMonitor {
+	return &DriftMonitor{worker: worker, source: source, threshold: threshold, onAlert: onAlert}
+}
+
+// Check takes one sample.
+func (m *DriftMonitor) Check(ctx context.Context) (DriftSample, error) {
+	ref, err := m.source.Now(ctx)
+	if err != nil {
+		return DriftSample{}, err
+	}
+	m.worker.Lock()
+	clock, micros := wallClock(m.worker.clock), m.worker.layout.Micros // 计数模式下比较底层时钟
+	m.worker.Unlock()
+	local := clock.Millis()
+	if micros {
+		local /= 1000
+	}
+	s := DriftSample{At: ref, Skew: time.Duration(local-toMillis(ref)) * time.Millisecond}
+	m.mu.Lock()
+	m.last = s
+	threshold := m.threshold
+	m.mu.Unlock()
+	expvarDrift.Set(int64(s.Skew / time.Millisecond))
+	if (s.Skew > threshold || s.Skew < -threshold) && m.onAlert != nil {
+		m.onAlert(s)
+	}
+	return s, nil
+}
+
+// Last returns the last sample taken.
+func (m *DriftMonitor) Last() DriftSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// Threshold returns the skew above which the monitor alerts.
+func (m *DriftMonitor) Threshold() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.threshold
+}
+
+// SetThreshold changes the alert threshold of a running monitor, e.g. to
+// tighten it after an incident without a restart.
+func (m *DriftMonitor) SetThreshold(threshold time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.threshold = threshold
+}
+
+// Start checks every interval until ctx is done. Failed checks are
+// skipped.
+func (m *DriftMonitor) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		for {
+			wait := interval
+			if s, err := m.Check(ctx); err == nil && (s.Skew > m.Threshold()/2 || s.Skew < -m.Threshold()/2) {
+				wait = interval / 2
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
@@ -0,0 +1,81 @@
+package snowflake
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// offsetSource is a reference running offset ahead of the local clock.
+type offsetSource time.Duration
+
+func (o offsetSource) Now(ctx context.Context) (time.Time, error) {
+	return time.Now().Add(time.Duration(o)), nil
+}
+
+type failingSource struct{}
+
+func (failingSource) Now(ctx context.Context) (time.Time, error) {
+	return time.Time{}, errors.New("unreachable")
+}
+
+func TestDriftMonitor(t *testing.T) {
+	w, _ := NewIdWorker(1)
+	var alerts []DriftSample
+	m := NewDriftMonitor(w, offsetSource(300*time.Millisecond), 100*time.Millisecond, func(s DriftSample) {
+		alerts = append(alerts, s)
+	})
+	s, err := m.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Skew > -250*time.Millisecond || s.Skew < -350*time.Millisecond || len(alerts) != 1 || m.Last() != s {
+		t.Errorf("skew %s, %d alerts", s.Skew, len(alerts))
+	}
+
+	m = NewDriftMonitor(w, Quorum{offsetSource(0), offsetSource(5 * time.Millisecond), offsetSource(time.Hour), failingSource{}}, 100*time.Millisecond, nil)
+	if s, err := m.Check(context.Background()); err != nil || s.Skew < -50*time.Millisecond || s.Skew > 50*time.Millisecond {
+		t.Errorf("quorum should ignore the outlier: %s, %v", s.Skew, err)
+	}
+	m = NewDriftMonitor(w, Quorum{failingSource{}, failingSource{}, offsetSource(0)}, time.Second, nil)
+	if _, err := m.Check(context.Background()); err == nil {
+		t.Error("quorum without majority should fail")
+	}
+}
+
+func TestNTPSource(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	ahead := 2 * time.Second
+	go func() {
+		buf := make([]byte, 48)
+		_, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		now := time.Now().Add(ahead)
+		resp := make([]byte, 48)
+		resp[0] = 0x24 // 服务器模式
+		putNTPTime(resp[32:40], now)
+		putNTPTime(resp[40:48], now)
+		pc.WriteTo(resp, addr)
+	}()
+	got, err := NTPSource{Addr: pc.LocalAddr().String()}.Now(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(got) - ahead; d > 50*time.Millisecond || d < -50*time.Millisecond {
+		t.Errorf("ntp time off by %s", d)
+	}
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32(int64(t.Nanosecond())<<32/1e9))
+}
@@ -0,0 +1,113 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Districts marking ids minted by an EdgeWorker, so the server can tell on
+// sync which ids need validating.
+const (
+	EdgeDistrict          = int64(6) // 边缘设备, 时钟已与服务器同步
+	EdgeUncertainDistrict = int64(7) // 边缘设备, 时钟不可信
+)
+
+// EdgeWorker issues ids on an intermittently connected device. Time is the
+// last server time received by Sync plus the monotonic time elapsed since,
+// so a wrong or jumping device clock does not leak into ids. Ids issued
+// before the first sync, or longer than MaxUnsynced after the last one, are
+// flagged as uncertain.
+type EdgeWorker struct {
+	worker      *IdWorker
+	clock       *edgeClock
+	MaxUnsynced time.Duration
+}
+
+// NewEdgeWorker new an edge worker embedding deviceId as its node ID. Pass
+// WithStore to keep ids unique across device reboots. The layout must have
+// room for EdgeDistrict and EdgeUncertainDistrict.
+func NewEdgeWorker(deviceId int64, maxUnsynced time.Duration, opts ...Option) (*EdgeWorker, error) {
+	clock := &edgeClock{}
+	w, err := NewIdWorker(deviceId, append([]Option{WithClock(clock)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	if max := w.layout.MaxDistrictId(); max < EdgeUncertainDistrict {
+		return nil, errors.New(fmt.Sprintf("edge districts %d and %d do not fit in %d district bits", EdgeDistrict, EdgeUncertainDistrict, w.layout.DistrictBits))
+	}
+	return &EdgeWorker{worker: w, clock: clock, MaxUnsynced: maxUnsynced}, nil
+}
+
+// Sync records the server time in unix milliseconds, received just now.
+func (e *EdgeWorker) Sync(serverMillis int64) {
+	e.clock.sync(serverMillis)
+}
+
+// Certain reports whether ids issued now are flagged as certain.
+func (e *EdgeWorker) Certain() bool {
+	return e.clock.certain(e.MaxUnsynced)
+}
+
+// Layout returns the layout of the edge worker's ids, for IsUncertain.
+func (e *EdgeWorker) Layout() Layout {
+	return e.worker.Layout()
+}
+
+// NextId get a snowflake id, flagged by its district as certain or not.
+func (e *EdgeWorker) NextId() (ID, error) {
+	e.worker.Lock()
+	defer e.worker.Unlock()
+	if e.Certain() {
+		e.worker.districtId = EdgeDistrict
+	} else {
+		e.worker.districtId = EdgeUncertainDistrict
+	}
+	return e.worker.nextid()
+}
+
+// IsUncertain reports whether f, in layout l, was issued by an EdgeWorker
+// whose clock was not in sync with the server.
+func IsUncertain(f ID, l Layout) bool {
+	return l.MaxDistrictId() >= EdgeUncertainDistrict && f.WithLayout(l).DistrictId() == EdgeUncertainDistrict
+}
+
+// edgeClock is server time plus monotonic elapsed time, falling back to the
+// device clock before the first sync. It never goes backwards.
+type edgeClock struct {
+	mu     sync.Mutex
+	synced bool
+	server int64     // 最近一次同步的服务器毫秒
+	anchor time.Time // 同步时的单调时间
+	last   int64
+}
+
+func (c *edgeClock) sync(serverMillis int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.synced = true
+	c.server = serverMillis
+	c.anchor = time.Now()
+}
+
+func (c *edgeClock) certain(maxUnsynced time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.synced && time.Since(c.anchor) <= maxUnsynced
+}
+
+func (c *edgeClock) Millis() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := timeGen()
+	if c.synced {
+		now = c.server + int64(time.Since(c.anchor)/time.Millisecond)
+	}
+	if now < c.last {
+		// 同步后服务器时间早于已用过的毫秒, 停在原处等它追上
+		now = c.last
+	}
+	c.last = now
+	return now
+}
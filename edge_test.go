@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEdgeWorker(t *testing.T) {
+	e, err := NewEdgeWorker(42, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := e.NextId()
+	if !IsUncertain(id, e.Layout()) || id.NodeId() != 42 {
+		t.Errorf("unsynced id: uncertain %v node %d", IsUncertain(id, e.Layout()), id.NodeId())
+	}
+
+	// 设备时钟慢了一小时, 同步后 ID 的时间以服务器为准
+	server := timeGen() + int64(time.Hour/time.Millisecond)
+	e.Sync(server)
+	id, _ = e.NextId()
+	if IsUncertain(id, e.Layout()) {
+		t.Error("synced id flagged uncertain")
+	}
+	if ts := id.timestamp(); ts < server || ts > server+1000 {
+		t.Errorf("timestamp %d, want about %d", ts, server)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if id, _ = e.NextId(); !IsUncertain(id, e.Layout()) {
+		t.Error("id past MaxUnsynced not flagged uncertain")
+	}
+	if IsUncertain(ID(0), DefaultLayout) {
+		t.Error("zero id flagged uncertain")
+	}
+	if _, err := NewEdgeWorker(42, time.Second, WithDistrictId(1), WithLayout(MicroLayout)); err == nil {
+		t.Error("layout without room for the edge districts should fail")
+	}
+}
+
+func TestEdgeClockMonotonic(t *testing.T) {
+	c := &edgeClock{}
+	before := c.Millis()
+	c.sync(before - 10000)
+	if got := c.Millis(); got < before {
+		t.Errorf("went backwards after sync: %d < %d", got, before)
+	}
+}
@@ -0,0 +1,58 @@
+package snowflake
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// RawBytes returns the 8 bytes of f, most significant first. It is the
+// same encoding as IntBytes, as a slice.
+func (f ID) RawBytes() []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(f))
+	return b
+}
+
+// RawBytesLE returns the 8 bytes of f, least significant first.
+func (f ID) RawBytesLE() []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(f))
+	return b
+}
+
+// ParseRawBytes decodes an id encoded by RawBytes.
+func ParseRawBytes(b []byte) (ID, error) {
+	if len(b) != 8 {
+		return 0, errors.New(fmt.Sprintf("raw snowflake id must be 8 bytes, got %d", len(b)))
+	}
+	return ID(binary.BigEndian.Uint64(b)), nil
+}
+
+// ParseRawBytesLE decodes an id encoded by RawBytesLE.
+func ParseRawBytesLE(b []byte) (ID, error) {
+	if len(b) != 8 {
+		return 0, errors.New(fmt.Sprintf("raw snowflake id must be 8 bytes, got %d", len(b)))
+	}
+	return ID(binary.LittleEndian.Uint64(b)), nil
+}
+
+// Base64URL returns the URL-safe, unpadded base64 of the raw bytes of f,
+// always 11 characters. Prefer it to Base64, which encodes the decimal.
+func (f ID) Base64URL() string {
+	b := f.IntBytes()
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// ParseBase64URL decodes an id encoded by Base64URL.
+func ParseBase64URL(s string) (ID, error) {
+	if len(s) != 11 {
+		return 0, errors.New(fmt.Sprintf("base64 snowflake id must be 11 characters, got %d", len(s)))
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid base64 snowflake id %q", s))
+	}
+	return ParseRawBytes(b)
+}
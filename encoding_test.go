@@ -0,0 +1,43 @@
+package snowflake
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRawBytes(t *testing.T) {
+	id := ID(0x0102030405060708)
+	if b := id.RawBytes(); !bytes.Equal(b, []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("RawBytes: got %v", b)
+	}
+	if b := id.RawBytesLE(); !bytes.Equal(b, []byte{8, 7, 6, 5, 4, 3, 2, 1}) {
+		t.Errorf("RawBytesLE: got %v", b)
+	}
+	if got, err := ParseRawBytes(id.RawBytes()); err != nil || got != id {
+		t.Errorf("ParseRawBytes: got %d, %v", got, err)
+	}
+	if got, err := ParseRawBytesLE(id.RawBytesLE()); err != nil || got != id {
+		t.Errorf("ParseRawBytesLE: got %d, %v", got, err)
+	}
+	if _, err := ParseRawBytes(id.Bytes()); err == nil {
+		t.Error("decimal bytes should not parse as raw")
+	}
+}
+
+func TestBase64URL(t *testing.T) {
+	for _, id := range []ID{0, 1, MaxID, 0x0102030405060708} {
+		s := id.Base64URL()
+		if len(s) != 11 {
+			t.Errorf("%d: got %q, want 11 characters", id, s)
+		}
+		if got, err := ParseBase64URL(s); err != nil || got != id {
+			t.Errorf("%d: round trip got %d, %v", id, got, err)
+		}
+	}
+	if _, err := ParseBase64URL(ID(1).Base64()); err == nil {
+		t.Error("decimal base64 should not parse")
+	}
+	if _, err := ParseBase64URL("AAAAAAAAAA+"); err == nil {
+		t.Error("standard alphabet should not parse")
+	}
+}
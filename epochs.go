@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DistrictEpochs gives some districts their own epoch, in unix
+// milliseconds, so a region launched years after the others gets the full
+// timestamp lifetime from its launch. Districts not listed use the epoch of
+// the layout.
+type DistrictEpochs map[int64]int64
+
+// WithDistrictEpochs makes the worker count the timestamp of each district
+// from its epoch in e. Decode its ids with DistrictEpochs.Decode.
+func WithDistrictEpochs(e DistrictEpochs) Option {
+	return func(id *IdWorker) error {
+		now := timeGen()
+		for district, epoch := range e {
+			if district < 0 || district > id.layout.MaxDistrictId() {
+				return errors.New(fmt.Sprintf("district must be between 0 and %d", id.layout.MaxDistrictId()))
+			}
+			if epoch > now {
+				return errors.New(fmt.Sprintf("epoch %d of district %d is in the future", epoch, district))
+			}
+		}
+		id.epochs = e
+		return nil
+	}
+}
+
+// epochFor returns the epoch timestamps of districtId count from, in ticks
+// of the worker's layout.
+func (id *IdWorker) epochFor(districtId int64) int64 {
+	if epoch, ok := id.epochs[districtId]; ok {
+		return (epoch - id.offset) * id.layout.tick()
+	}
+	return id.epoch() * id.layout.tick()
+}
+
+// Decode returns a view of f decoded with l, with the epoch of f's district.
+func (e DistrictEpochs) Decode(f ID, l Layout) LayoutID {
+	v := f.WithLayout(l)
+	if epoch, ok := e[v.DistrictId()]; ok {
+		v.Layout.Epoch = epoch
+	}
+	return v
+}
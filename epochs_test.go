@@ -0,0 +1,38 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDistrictEpochs(t *testing.T) {
+	launch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	epochs := DistrictEpochs{3: launch}
+	w, err := NewIdWorker(1, WithDistrictEpochs(epochs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().Truncate(time.Millisecond)
+	own, _ := w.NextIdAt(at)
+	if own.timestamp() != at.UnixMilli() {
+		t.Errorf("district 1 should keep the default epoch")
+	}
+	late, err := w.NextIdForDistrict(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := epochs.Decode(late, DefaultLayout)
+	if v.DistrictId() != 3 || v.Timestamp() < at.UnixMilli() || v.Timestamp() > at.UnixMilli()+1000 {
+		t.Errorf("district 3 decoded to district %d at %d, want about %d", v.DistrictId(), v.Timestamp(), at.UnixMilli())
+	}
+	if late.WithLayout(DefaultLayout).Timestamp() >= at.UnixMilli()-int64(5*365*24*time.Hour/time.Millisecond) {
+		t.Error("district 3 timestamp should count from its own, later epoch")
+	}
+
+	if _, err := NewIdWorker(1, WithDistrictEpochs(DistrictEpochs{maxDistrictId + 1: launch})); err == nil {
+		t.Error("district out of range should fail")
+	}
+	if _, err := NewIdWorker(1, WithDistrictEpochs(DistrictEpochs{3: timeGen() + 1e6})); err == nil {
+		t.Error("future epoch should fail")
+	}
+}
@@ -0,0 +1,53 @@
+package snowflake
+
+import (
+	"errors"
+)
+
+// EventSourcingWorker issues ids usable as versions of an aggregate: given
+// the last id of the aggregate, the next one is strictly greater, even if
+// it was issued by another process or before a restart behind a clock
+// rollback. When the clock is not past the last id, the timestamp is bumped
+// ahead of it, so ids may run ahead of real time until the clock catches
+// up; the worker never issues an id behind one it issued before.
+//
+// Pass WithStore to carry the high-water timestamp across restarts.
+type EventSourcingWorker struct {
+	worker *IdWorker
+}
+
+// NewEventSourcingWorker new an event sourcing worker on NodeId.
+func NewEventSourcingWorker(NodeId int64, opts ...Option) (*EventSourcingWorker, error) {
+	w, err := NewIdWorker(NodeId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if w.layout.Micros {
+		return nil, errors.New("event sourcing does not support microsecond layouts")
+	}
+	return &EventSourcingWorker{worker: w}, nil
+}
+
+// NextAfter get a snowflake id greater than last, the last id of the
+// aggregate, or 0 for a new aggregate.
+func (e *EventSourcingWorker) NextAfter(last ID) (ID, error) {
+	w := e.worker
+	w.Lock()
+	defer w.Unlock()
+	timestamp := w.clock.Millis()
+	if last > 0 {
+		if after := last.WithLayout(w.currentLayout()).Timestamp() + 1; timestamp < after {
+			timestamp = after
+		}
+	}
+	if timestamp < w.lastTimestamp {
+		timestamp = w.lastTimestamp
+	}
+	for {
+		f, err := w.nextidAt(timestamp, false)
+		if err != ErrSequenceExhausted {
+			return f, err
+		}
+		timestamp++ // 不等时钟, 直接使用下一毫秒
+	}
+}
@@ -0,0 +1,35 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventSourcingWorker(t *testing.T) {
+	w, err := NewEventSourcingWorker(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 另一进程在未来一小时写下的版本
+	other, _ := NewIdWorker(2)
+	last, _ := other.NextIdAt(time.Now().Add(time.Hour))
+
+	for i := 0; i < 3000; i++ {
+		next, err := w.NextAfter(last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next <= last {
+			t.Fatalf("%d is not after %d", next, last)
+		}
+		last = next
+	}
+	// 时钟未追上时, 新聚合的 ID 也不回退
+	fresh, err := w.NextAfter(0)
+	if err != nil || fresh <= last {
+		t.Errorf("new aggregate: %d after %d, %v", fresh, last, err)
+	}
+	if _, err := NewEventSourcingWorker(1, WithLayout(MicroLayout)); err == nil {
+		t.Error("micro layout should be refused")
+	}
+}
@@ -0,0 +1,20 @@
+//go:build go1.23
+
+package snowflake_test
+
+import (
+	"fmt"
+	"slices"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func ExampleMergeSorted() {
+	nodeA := []snowflake.ID{1 << 24, 3 << 24, 5 << 24}
+	nodeB := []snowflake.ID{2 << 24, 4 << 24}
+	for id := range snowflake.MergeSorted(slices.Values(nodeA), slices.Values(nodeB)) {
+		fmt.Print(id>>24, " ")
+	}
+	fmt.Println()
+	// Output: 1 2 3 4 5
+}
@@ -0,0 +1,122 @@
+package snowflake_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func ExampleNewIdWorker() {
+	worker, err := snowflake.NewIdWorker(1)
+	if err != nil {
+		panic(err)
+	}
+	id, err := worker.NextId()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(id.NodeId(), id.DistrictId())
+	// Output: 1 1
+}
+
+func ExampleIdWorker_NextIdAt() {
+	worker, _ := snowflake.NewIdWorker(1)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first, _ := worker.NextIdAt(at)
+	second, _ := worker.NextIdAt(at)
+	fmt.Println(first)
+	fmt.Println(second)
+	// Output:
+	// 2703196044657165312
+	// 2703196044657165313
+}
+
+func ExampleID_WithLayout() {
+	v := snowflake.ID(2703196044657165313).WithLayout(snowflake.DefaultLayout)
+	fmt.Println(time.UnixMilli(v.Timestamp()).UTC())
+	fmt.Println(v.Tag(), v.DistrictId(), v.NodeId(), v.Sequence())
+	// Output:
+	// 2024-01-01 00:00:00 +0000 UTC
+	// none 1 1 1
+}
+
+func ExampleID_Base64URL() {
+	id := snowflake.ID(2703196044657165313)
+	s := id.Base64URL()
+	back, _ := snowflake.ParseBase64URL(s)
+	fmt.Println(s, back == id)
+	// Output: JYOuewAIBAE true
+}
+
+func ExampleParseLayout() {
+	l, err := snowflake.ParseLayout("39-5-9-10")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(l == snowflake.LegacyLayout, l.MaxNodeId())
+	_, err = snowflake.ParseLayout("39-5-x-10")
+	fmt.Println(err)
+	// Output:
+	// true 511
+	// layout "39-5-x-10": segment 3 (node) "x" is not a bit width between 0 and 63
+}
+
+func ExampleNewContext() {
+	ctx := snowflake.NewContext(context.Background(), 2703196044657165313)
+	if id, ok := snowflake.FromContext(ctx); ok {
+		fmt.Println("request", id)
+	}
+	// Output: request 2703196044657165313
+}
+
+func ExampleIdWorker_Freeze() {
+	worker, _ := snowflake.NewIdWorker(1)
+	worker.Freeze("suspected node conflict")
+	_, err := worker.NextId()
+	fmt.Println(errors.Is(err, snowflake.ErrFrozen))
+	worker.Unfreeze()
+	_, err = worker.NextId()
+	fmt.Println(err)
+	// Output:
+	// true
+	// <nil>
+}
+
+func ExampleIdWorker_NextIds() {
+	worker, _ := snowflake.NewIdWorker(1)
+	ids, err := worker.NextIds(10)
+	var be *snowflake.BatchError
+	if errors.As(err, &be) {
+		// ids holds the be.Index ids issued before the failure.
+		fmt.Println("partial batch of", len(ids))
+		return
+	}
+	fmt.Println(len(ids))
+	// Output: 10
+}
+
+func ExampleNewBloomFilter() {
+	seen, _ := snowflake.NewBloomFilter(1000000, 0.001)
+	seen.Add(2703196044657165313)
+	fmt.Println(seen.Contains(2703196044657165313), seen.Contains(2703196044657165314))
+	// Output: true false
+}
+
+func ExampleNewSampler() {
+	worker, _ := snowflake.NewIdWorker(1)
+	sampler, _ := snowflake.NewSampler(snowflake.DefaultLayout, time.Second, 2)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		id, _ := worker.NextIdAt(at.Add(time.Duration(i) * 300 * time.Millisecond))
+		fmt.Println(i, sampler.Offer(id))
+	}
+	// Output:
+	// 0 true
+	// 1 true
+	// 2 false
+	// 3 false
+	// 4 true
+}
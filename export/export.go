@@ -0,0 +1,151 @@
+// Package export decodes files of ids into their components, as CSV, JSON
+// lines or Parquet, for analysis in pandas, BigQuery and the like.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Record is one decoded id.
+type Record struct {
+	ID         snowflake.ID `json:"id"`
+	Timestamp  int64        `json:"timestamp"` // unix 毫秒
+	Time       string       `json:"time"`      // RFC3339, UTC
+	Tag        string       `json:"tag"`
+	DistrictId int64        `json:"district_id"`
+	NodeId     int64        `json:"node_id"`
+	Sequence   int64        `json:"sequence"`
+}
+
+// Decode decodes id with layout.
+func Decode(id snowflake.ID, layout snowflake.Layout) Record {
+	v := id.WithLayout(layout)
+	ts := v.Timestamp()
+	return Record{
+		ID:         id,
+		Timestamp:  ts,
+		Time:       time.Unix(0, ts*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano),
+		Tag:        v.Tag().String(),
+		DistrictId: v.DistrictId(),
+		NodeId:     v.NodeId(),
+		Sequence:   v.Sequence(),
+	}
+}
+
+// Writer writes decoded records in some format.
+type Writer interface {
+	Write(Record) error
+	Flush() error
+}
+
+// ReadIDs calls fn for every id in r, one decimal id per line; blank lines
+// are skipped.
+func ReadIDs(r io.Reader, fn func(snowflake.ID) error) error {
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errors.New(fmt.Sprintf("line %d: invalid id %q", line, s))
+		}
+		if err := fn(snowflake.ID(n)); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// Export decodes every id in r with layout and writes it to w. It returns
+// the number of records written.
+func Export(r io.Reader, w Writer, layout snowflake.Layout) (int, error) {
+	n := 0
+	err := ReadIDs(r, func(id snowflake.ID) error {
+		n++
+		return w.Write(Decode(id, layout))
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, w.Flush()
+}
+
+// NewWriter returns the writer for format, "csv", "jsonl" or "parquet".
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "csv":
+		return NewCSVWriter(w), nil
+	case "jsonl", "json":
+		return NewJSONWriter(w), nil
+	case "parquet":
+		return NewParquetWriter(w), nil
+	}
+	return nil, errors.New(fmt.Sprintf("unknown export format %q", format))
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter writes records as CSV with a header row.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(r Record) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write([]string{"id", "timestamp", "time", "tag", "district_id", "node_id", "sequence"}); err != nil {
+			return err
+		}
+	}
+	return c.w.Write([]string{
+		r.ID.String(),
+		strconv.FormatInt(r.Timestamp, 10),
+		r.Time,
+		r.Tag,
+		strconv.FormatInt(r.DistrictId, 10),
+		strconv.FormatInt(r.NodeId, 10),
+		strconv.FormatInt(r.Sequence, 10),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONWriter writes records as newline delimited JSON, which BigQuery
+// loads directly.
+func NewJSONWriter(w io.Writer) Writer {
+	bw := bufio.NewWriter(w)
+	return &jsonWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *jsonWriter) Write(r Record) error {
+	return j.enc.Encode(r)
+}
+
+func (j *jsonWriter) Flush() error {
+	return j.w.Flush()
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestExportCSV(t *testing.T) {
+	worker, _ := snowflake.NewOrderIdWorker(9)
+	ids, _ := worker.NextIds(3)
+	var in bytes.Buffer
+	for _, id := range ids {
+		in.WriteString(id.String() + "\n\n")
+	}
+	var out bytes.Buffer
+	n, err := Export(&in, NewCSVWriter(&out), worker.Layout())
+	if err != nil || n != 3 {
+		t.Fatalf("export: %d, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "id,") {
+		t.Fatalf("csv: got %q", out.String())
+	}
+	if !strings.HasPrefix(lines[1], ids[0].String()+",") || !strings.HasSuffix(lines[1], ",order,1,9,0") {
+		t.Errorf("csv row: got %q", lines[1])
+	}
+}
+
+func TestExportBadLine(t *testing.T) {
+	var out bytes.Buffer
+	w, _ := NewWriter("jsonl", &out)
+	if _, err := Export(strings.NewReader("1\nxyz\n"), w, snowflake.DefaultLayout); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("bad line: got %v", err)
+	}
+}
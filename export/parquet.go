@@ -0,0 +1,282 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// parquetRowGroup is the number of records buffered per row group.
+const parquetRowGroup = 1 << 16
+
+// Parquet physical types, converted types and encodings, from parquet.thrift
+// of parquet-format.
+const (
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// parquetColumn is one column of the schema, with its values for the
+// current row group.
+type parquetColumn struct {
+	name      string
+	typ       int32
+	converted int32 // -1 表示无
+	value     func(Record) interface{}
+}
+
+var parquetColumns = []parquetColumn{
+	{"id", parquetInt64, -1, func(r Record) interface{} { return int64(r.ID) }},
+	{"timestamp", parquetInt64, parquetTimestampMillis, func(r Record) interface{} { return r.Timestamp }},
+	{"time", parquetByteArray, parquetUTF8, func(r Record) interface{} { return r.Time }},
+	{"tag", parquetByteArray, parquetUTF8, func(r Record) interface{} { return r.Tag }},
+	{"district_id", parquetInt64, -1, func(r Record) interface{} { return r.DistrictId }},
+	{"node_id", parquetInt64, -1, func(r Record) interface{} { return r.NodeId }},
+	{"sequence", parquetInt64, -1, func(r Record) interface{} { return r.Sequence }},
+}
+
+// parquetChunk locates a column chunk written to the file.
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+type parquetRowGroupMeta struct {
+	rows   int64
+	size   int64
+	chunks []parquetChunk
+}
+
+type parquetWriter struct {
+	w      *bufio.Writer
+	offset int64
+	rows   []Record
+	total  int64
+	groups []parquetRowGroupMeta
+	err    error
+}
+
+// NewParquetWriter writes records as a Parquet file, with one required
+// column per field of Record, plain encoded and uncompressed, which Spark,
+// pandas and BigQuery load directly. Records are buffered in row groups of
+// 65536; Flush ends the file, so the writer cannot be used after it.
+func NewParquetWriter(w io.Writer) Writer {
+	p := &parquetWriter{w: bufio.NewWriter(w)}
+	p.write([]byte("PAR1"))
+	return p
+}
+
+func (p *parquetWriter) write(b []byte) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = p.w.Write(b)
+	p.offset += int64(len(b))
+}
+
+func (p *parquetWriter) Write(r Record) error {
+	p.rows = append(p.rows, r)
+	if len(p.rows) == parquetRowGroup {
+		p.writeRowGroup()
+	}
+	return p.err
+}
+
+// writeRowGroup writes the buffered records as a row group, one data page
+// per column.
+func (p *parquetWriter) writeRowGroup() {
+	g := parquetRowGroupMeta{rows: int64(len(p.rows))}
+	for _, c := range parquetColumns {
+		var page []byte
+		for _, r := range p.rows {
+			switch v := c.value(r).(type) {
+			case int64:
+				page = binary.LittleEndian.AppendUint64(page, uint64(v))
+			case string:
+				page = binary.LittleEndian.AppendUint32(page, uint32(len(v)))
+				page = append(page, v...)
+			}
+		}
+		var h thriftWriter
+		h.i32(1, 0) // DATA_PAGE
+		h.i32(2, int32(len(page)))
+		h.i32(3, int32(len(page)))
+		h.beginStruct(5)
+		h.i32(1, int32(len(p.rows)))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.endStruct()
+		h.stop()
+		chunk := parquetChunk{offset: p.offset, size: int64(len(h.buf) + len(page))}
+		p.write(h.buf)
+		p.write(page)
+		g.chunks = append(g.chunks, chunk)
+		g.size += chunk.size
+	}
+	p.groups = append(p.groups, g)
+	p.total += g.rows
+	p.rows = p.rows[:0]
+}
+
+func (p *parquetWriter) Flush() error {
+	if len(p.rows) > 0 {
+		p.writeRowGroup()
+	}
+	var m thriftWriter
+	m.i32(1, 1) // version
+	m.beginList(2, thriftStruct, len(parquetColumns)+1)
+	m.binary(4, "schema")
+	m.i32(5, int32(len(parquetColumns)))
+	m.stop()
+	for _, c := range parquetColumns {
+		m.i32(1, c.typ)
+		m.i32(3, 0) // REQUIRED
+		m.binary(4, c.name)
+		if c.converted >= 0 {
+			m.i32(6, c.converted)
+		}
+		m.stop()
+	}
+	m.endList()
+	m.i64(3, p.total)
+	m.beginList(4, thriftStruct, len(p.groups))
+	for _, g := range p.groups {
+		m.beginList(1, thriftStruct, len(g.chunks))
+		for i, chunk := range g.chunks {
+			c := parquetColumns[i]
+			m.i64(2, chunk.offset)
+			m.beginStruct(3)
+			m.i32(1, c.typ)
+			m.beginList(2, thriftI32, 2)
+			m.elemI32(parquetPlain)
+			m.elemI32(parquetRLE)
+			m.endList()
+			m.beginList(3, thriftBinary, 1)
+			m.elemBinary(c.name)
+			m.endList()
+			m.i32(4, 0) // UNCOMPRESSED
+			m.i64(5, g.rows)
+			m.i64(6, chunk.size)
+			m.i64(7, chunk.size)
+			m.i64(9, chunk.offset)
+			m.endStruct()
+			m.stop()
+		}
+		m.endList()
+		m.i64(2, g.size)
+		m.i64(3, g.rows)
+		m.stop()
+	}
+	m.endList()
+	m.binary(6, "github.com/sakishum/go_snowflake/export")
+	m.stop()
+	p.write(m.buf)
+	p.write(binary.LittleEndian.AppendUint32(nil, uint32(len(m.buf))))
+	p.write([]byte("PAR1"))
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the few thrift compact protocol constructs the
+// Parquet metadata needs. Fields of a struct go in increasing id order, each
+// struct ends with stop; list elements that are structs are written as
+// their fields followed by stop.
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // 外层结构体最后写入的字段 id
+	id   int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if d := id - t.id; d > 0 && d <= 15 {
+		t.buf = append(t.buf, byte(d)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(int64(id))
+	}
+	t.id = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(v<<1^v>>63))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.elemBinary(s)
+}
+
+func (t *thriftWriter) elemI32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) elemBinary(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// beginStruct starts a struct field; endStruct ends it.
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.last = append(t.last, t.id)
+	t.id = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.id = t.last[len(t.last)-1]
+	t.last = t.last[:len(t.last)-1]
+}
+
+// beginList starts a list field of n elements of type elem; endList ends
+// it.
+func (t *thriftWriter) beginList(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+	} else {
+		t.buf = append(t.buf, 0xf0|elem)
+		t.buf = binary.AppendUvarint(t.buf, uint64(n))
+	}
+	t.last = append(t.last, t.id)
+	t.id = 0
+}
+
+func (t *thriftWriter) endList() {
+	t.id = t.last[len(t.last)-1]
+	t.last = t.last[:len(t.last)-1]
+}
+
+// stop ends a struct that is a list element, or the top-level struct.
+func (t *thriftWriter) stop() {
+	t.buf = append(t.buf, 0)
+	t.id = 0
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// thriftReader decodes the thrift compact protocol into maps of field id
+// to value, enough to read back what parquetWriter writes.
+type thriftReader struct {
+	b []byte
+}
+
+func (t *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(t.b)
+	t.b = t.b[n:]
+	return v
+}
+
+func (t *thriftReader) varint() int64 {
+	u := t.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (t *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return t.varint()
+	case thriftBinary:
+		n := t.uvarint()
+		s := string(t.b[:n])
+		t.b = t.b[n:]
+		return s
+	case thriftList:
+		h := t.b[0]
+		t.b = t.b[1:]
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(t.uvarint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = t.value(h & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return t.structure()
+	}
+	panic("unexpected thrift type")
+}
+
+func (t *thriftReader) structure() map[int16]interface{} {
+	m := make(map[int16]interface{})
+	var id int16
+	for {
+		h := t.b[0]
+		t.b = t.b[1:]
+		if h == 0 {
+			return m
+		}
+		if d := int16(h >> 4); d != 0 {
+			id += d
+		} else {
+			id = int16(t.varint())
+		}
+		m[id] = t.value(h & 0x0f)
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	worker, _ := snowflake.NewOrderIdWorker(9)
+	ids, _ := worker.NextIds(3)
+	var in bytes.Buffer
+	for _, id := range ids {
+		in.WriteString(id.String() + "\n")
+	}
+	var out bytes.Buffer
+	w, _ := NewWriter("parquet", &out)
+	if n, err := Export(&in, w, worker.Layout()); err != nil || n != 3 {
+		t.Fatalf("export: %d, %v", n, err)
+	}
+	file := out.Bytes()
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatal("missing magic")
+	}
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	meta := (&thriftReader{file[len(file)-8-size : len(file)-8]}).structure()
+	if meta[3].(int64) != 3 {
+		t.Errorf("num_rows: got %v", meta[3])
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != len(parquetColumns)+1 || schema[1].(map[int16]interface{})[4] != "id" {
+		t.Fatalf("schema: got %v", schema)
+	}
+	chunks := meta[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{})
+
+	column := func(i int) []byte {
+		cm := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		r := &thriftReader{file[cm[9].(int64):]}
+		page := r.structure()
+		if page[5].(map[int16]interface{})[1].(int64) != 3 {
+			t.Fatalf("column %d: page of %v values", i, page[5])
+		}
+		return r.b[:page[3].(int64)]
+	}
+	idPage := column(0)
+	for i, id := range ids {
+		if got := snowflake.ID(binary.LittleEndian.Uint64(idPage[8*i:])); got != id {
+			t.Errorf("id %d: got %d, want %d", i, got, id)
+		}
+	}
+	tagPage := column(3)
+	if n := binary.LittleEndian.Uint32(tagPage); string(tagPage[4:4+n]) != "order" {
+		t.Errorf("tag: got %q", tagPage)
+	}
+	if node := binary.LittleEndian.Uint64(column(5)); node != 9 {
+		t.Errorf("node_id: got %d", node)
+	}
+}
@@ -0,0 +1,13 @@
+package snowflake
+
+import (
+	"expvar"
+)
+
+// Package-wide counters of every worker, published through expvar so they
+// show up under /debug/vars without Prometheus.
+var (
+	expvarGenerated = expvar.NewInt("snowflake.generated") // 已生成的 ID 数
+	expvarErrors    = expvar.NewInt("snowflake.errors")    // 生成失败次数
+	expvarRollovers = expvar.NewInt("snowflake.rollovers") // 序号用尽等待下一毫秒的次数
+)
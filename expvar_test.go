@@ -0,0 +1,20 @@
+package snowflake
+
+import (
+	"expvar"
+	"testing"
+)
+
+func TestExpvar(t *testing.T) {
+	before := expvarGenerated.Value()
+	idworker, _ := NewIdWorker(1)
+	idworker.NextIds(10)
+	if got := expvarGenerated.Value() - before; got != 10 {
+		t.Errorf("snowflake.generated grew by %d, want 10", got)
+	}
+	for _, name := range []string{"snowflake.generated", "snowflake.errors", "snowflake.rollovers"} {
+		if expvar.Get(name) == nil {
+			t.Errorf("%s not published", name)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package snowflake
+
+import (
+	"sync"
+)
+
+// workerMutex is the lock of an IdWorker: a sync.Mutex by default, or a
+// FIFO lock once WithFairLock is applied.
+type workerMutex struct {
+	mu   sync.Mutex
+	fair chan struct{} // 容量为 1, 阻塞的发送方按先来后到排队
+}
+
+func (m *workerMutex) Lock() {
+	if m.fair != nil {
+		m.fair <- struct{}{}
+		return
+	}
+	m.mu.Lock()
+}
+
+func (m *workerMutex) Unlock() {
+	if m.fair != nil {
+		<-m.fair
+		return
+	}
+	m.mu.Unlock()
+}
+
+func (m *workerMutex) TryLock() bool {
+	if m.fair != nil {
+		select {
+		case m.fair <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+	return m.mu.TryLock()
+}
+
+// WithFairLock makes the worker hand its lock to waiting callers in arrival
+// order. A sync.Mutex lets a running goroutine barge ahead of sleeping
+// ones, which is faster on average but under sustained saturation can
+// leave some callers waiting far longer than others; the fair lock bounds
+// every caller's wait by the queue ahead of it, at some throughput cost.
+func WithFairLock() Option {
+	return func(id *IdWorker) error {
+		id.fair = make(chan struct{}, 1)
+		return nil
+	}
+}
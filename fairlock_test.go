@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFairLock(t *testing.T) {
+	w, _ := NewIdWorker(1, WithFairLock())
+	var mu sync.Mutex
+	seen := make(map[ID]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				id, err := w.NextId()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate id %d", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !w.TryLock() {
+		t.Fatal("TryLock on a free lock failed")
+	}
+	if w.TryLock() {
+		t.Fatal("TryLock on a held lock succeeded")
+	}
+	w.Unlock()
+}
+
+func TestFairLockOrder(t *testing.T) {
+	var m workerMutex
+	m.fair = make(chan struct{}, 1)
+	m.Lock()
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			m.Lock()
+			order <- i
+			m.Unlock()
+		}(i)
+		time.Sleep(10 * time.Millisecond) // 依次排队
+	}
+	m.Unlock()
+	for want := 0; want < 3; want++ {
+		if got := <-order; got != want {
+			t.Fatalf("waiter %d got the lock, want %d", got, want)
+		}
+	}
+}
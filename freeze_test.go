@@ -0,0 +1,29 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	idworker.Freeze("node conflict")
+	if _, err := idworker.NextId(); !errors.Is(err, ErrFrozen) {
+		t.Errorf("NextId: want ErrFrozen, got %v", err)
+	}
+	ids, err := idworker.NextIds(10)
+	var fe *FrozenError
+	if len(ids) != 0 || !errors.As(err, &fe) || fe.Reason != "node conflict" {
+		t.Errorf("NextIds: got %d ids, %v", len(ids), err)
+	}
+	if _, err := idworker.LeaseRange(10); !errors.Is(err, ErrFrozen) {
+		t.Errorf("LeaseRange: want ErrFrozen, got %v", err)
+	}
+	if idworker.Frozen() == nil {
+		t.Error("Frozen should report the freeze")
+	}
+	idworker.Unfreeze()
+	if _, err := idworker.NextId(); err != nil || idworker.Frozen() != nil {
+		t.Errorf("after Unfreeze: %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package snowflake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Announcement is what a worker broadcasts to its peers on every heartbeat.
+type Announcement struct {
+	Instance   string `json:"instance"` // 进程实例, 每次启动不同
+	NodeId     int64  `json:"node_id"`
+	DistrictId int64  `json:"district_id"`
+	Tag        int64  `json:"tag"`
+	StartTime  int64  `json:"start_time"` // unix 毫秒
+	SeenAt     int64  `json:"-"`          // 收到的时间, 由 backend 填写
+}
+
+// GossipBackend carries announcements between workers.
+type GossipBackend interface {
+	// Publish broadcasts a to the peers.
+	Publish(a Announcement) error
+	// Peers returns the announcements received within ttl, one per instance.
+	Peers(ttl time.Duration) ([]Announcement, error)
+}
+
+// Conflict is reported when two live workers claim the same node ID, the
+// most common cause of silently duplicated ids.
+type Conflict struct {
+	Self Announcement
+	Peer Announcement
+}
+
+// ConflictDetector heartbeats a worker's node ID over a backend and reports
+// every live peer claiming the same node, district and tag.
+type ConflictDetector struct {
+	worker     *IdWorker
+	self       Announcement // 实例与启动时间, 节点等在每次检查时读取
+	backend    GossipBackend
+	interval   time.Duration
+	onConflict func(Conflict)
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewConflictDetector new a detector for worker. onConflict defaults to
+// logging the conflict.
+func NewConflictDetector(worker *IdWorker, backend GossipBackend, interval time.Duration, onConflict func(Conflict)) *ConflictDetector {
+	if onConflict == nil {
+		onConflict = func(c Conflict) {
+			log.Printf("snowflake: NODE ID CONFLICT: node %d (district %d, tag %d) is also claimed by instance %s started at %s",
+				c.Self.NodeId, c.Self.DistrictId, c.Self.Tag, c.Peer.Instance, time.Unix(0, c.Peer.StartTime*int64(time.Millisecond)))
+		}
+	}
+	return &ConflictDetector{
+		worker: worker,
+		self: Announcement{
+			Instance:  newInstanceId(),
+			StartTime: timeGen(),
+		},
+		backend:    backend,
+		interval:   interval,
+		onConflict: onConflict,
+	}
+}
+
+// Start starts heartbeating in a goroutine.
+func (d *ConflictDetector) Start() {
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			d.Check()
+			select {
+			case <-ticker.C:
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops heartbeating.
+func (d *ConflictDetector) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+// announcement returns the heartbeat of the worker's current node,
+// district and tag, which RotateNode may have changed since the last one.
+func (d *ConflictDetector) announcement() Announcement {
+	a := d.self
+	d.worker.Lock()
+	a.NodeId, a.DistrictId, a.Tag = d.worker.nodeId, d.worker.districtId, d.worker.tag
+	d.worker.Unlock()
+	return a
+}
+
+// Check publishes one heartbeat and returns the conflicts found.
+func (d *ConflictDetector) Check() []Conflict {
+	self := d.announcement()
+	if err := d.backend.Publish(self); err != nil {
+		log.Printf("snowflake: gossip publish: %v", err)
+	}
+	peers, err := d.backend.Peers(3 * d.interval)
+	if err != nil {
+		log.Printf("snowflake: gossip peers: %v", err)
+		return nil
+	}
+	var conflicts []Conflict
+	for _, p := range peers {
+		if p.Instance != self.Instance && p.NodeId == self.NodeId && p.DistrictId == self.DistrictId && p.Tag == self.Tag {
+			c := Conflict{Self: self, Peer: p}
+			conflicts = append(conflicts, c)
+			d.onConflict(c)
+		}
+	}
+	return conflicts
+}
+
+func newInstanceId() string {
+	var b [8]byte
+	rand.Read(b[:])
+	host, _ := os.Hostname()
+	return host + "-" + hex.EncodeToString(b[:])
+}
+
+// MemoryGossip is an in-process backend, for tests and for workers sharing
+// one process.
+type MemoryGossip struct {
+	sync.Mutex
+	seen map[string]Announcement
+}
+
+// NewMemoryGossip new an in-process gossip backend.
+func NewMemoryGossip() *MemoryGossip {
+	return &MemoryGossip{seen: make(map[string]Announcement)}
+}
+
+func (m *MemoryGossip) Publish(a Announcement) error {
+	m.Lock()
+	defer m.Unlock()
+	a.SeenAt = timeGen()
+	m.seen[a.Instance] = a
+	return nil
+}
+
+func (m *MemoryGossip) Peers(ttl time.Duration) ([]Announcement, error) {
+	m.Lock()
+	defer m.Unlock()
+	cutoff := timeGen() - ttl.Milliseconds()
+	var out []Announcement
+	for k, a := range m.seen {
+		if a.SeenAt < cutoff {
+			delete(m.seen, k)
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// UDPGossip sends announcements as JSON datagrams to a fixed list of peer
+// addresses and collects the ones it receives.
+type UDPGossip struct {
+	conn  *net.UDPConn
+	peers []*net.UDPAddr
+	mem   *MemoryGossip
+}
+
+// NewUDPGossip listens on addr and sends to peers ("host:port").
+func NewUDPGossip(addr string, peers []string) (*UDPGossip, error) {
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	g := &UDPGossip{mem: NewMemoryGossip()}
+	for _, p := range peers {
+		paddr, err := net.ResolveUDPAddr("udp", p)
+		if err != nil {
+			return nil, err
+		}
+		g.peers = append(g.peers, paddr)
+	}
+	if g.conn, err = net.ListenUDP("udp", laddr); err != nil {
+		return nil, err
+	}
+	go g.receive()
+	return g, nil
+}
+
+// Addr returns the local address the backend listens on.
+func (g *UDPGossip) Addr() net.Addr {
+	return g.conn.LocalAddr()
+}
+
+func (g *UDPGossip) receive() {
+	buf := make([]byte, 1024)
+	for {
+		n, _, err := g.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		var a Announcement
+		if json.Unmarshal(buf[:n], &a) == nil && a.Instance != "" {
+			g.mem.Publish(a)
+		}
+	}
+}
+
+func (g *UDPGossip) Publish(a Announcement) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, p := range g.peers {
+		if _, err := g.conn.WriteToUDP(b, p); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (g *UDPGossip) Peers(ttl time.Duration) ([]Announcement, error) {
+	return g.mem.Peers(ttl)
+}
+
+// Close stops listening.
+func (g *UDPGossip) Close() error {
+	return g.conn.Close()
+}
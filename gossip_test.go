@@ -0,0 +1,66 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConflictDetector(t *testing.T) {
+	backend := NewMemoryGossip()
+	a, _ := NewIdWorker(5)
+	b, _ := NewIdWorker(5)
+	c, _ := NewUserIdWorker(5)
+	da := NewConflictDetector(a, backend, time.Second, func(Conflict) {})
+	db := NewConflictDetector(b, backend, time.Second, func(Conflict) {})
+	dc := NewConflictDetector(c, backend, time.Second, func(Conflict) {})
+
+	if got := da.Check(); len(got) != 0 {
+		t.Errorf("alone: got %d conflicts", len(got))
+	}
+	dc.Check()
+	got := db.Check()
+	if len(got) != 1 || got[0].Peer.Instance != da.self.Instance {
+		t.Errorf("shared node: got %+v", got)
+	}
+	if _, err := b.RotateNode(NewMemoryNodeAllocator(6, 6)); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Check(); len(got) != 0 {
+		t.Errorf("after rotating away: got %+v", got)
+	}
+}
+
+func TestUDPGossip(t *testing.T) {
+	ga, err := NewUDPGossip("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ga.Close()
+	gb, err := NewUDPGossip("127.0.0.1:0", []string{ga.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gb.Close()
+
+	w, _ := NewIdWorker(8)
+	found := make(chan Conflict, 1)
+	da := NewConflictDetector(w, ga, 10*time.Millisecond, func(c Conflict) {
+		select {
+		case found <- c:
+		default:
+		}
+	})
+	db := NewConflictDetector(w, gb, 10*time.Millisecond, func(Conflict) {})
+	da.Start()
+	db.Start()
+	defer da.Stop()
+	defer db.Stop()
+	select {
+	case c := <-found:
+		if c.Peer.NodeId != 8 {
+			t.Errorf("conflict: got %+v", c)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("conflict not detected over UDP")
+	}
+}
@@ -0,0 +1,89 @@
+package snowflake
+
+import (
+	"sync"
+	"time"
+)
+
+// ClockGranularity returns the smallest step of the system wall clock,
+// measured once per process. It is about a microsecond on most hosts, but
+// 1ms or more on Windows and some virtualised container hosts.
+func ClockGranularity() time.Duration {
+	granularityOnce.Do(func() {
+		granularity = measureGranularity(time.Now, 20)
+	})
+	return granularity
+}
+
+var (
+	granularityOnce sync.Once
+	granularity     time.Duration
+)
+
+// measureGranularity spins on now until it has seen samples steps of the
+// wall clock and returns the smallest one.
+func measureGranularity(now func() time.Time, samples int) time.Duration {
+	var min time.Duration
+	prev := now().UnixNano()
+	for seen := 0; seen < samples; {
+		cur := now().UnixNano()
+		if cur == prev {
+			continue
+		}
+		if d := time.Duration(cur - prev); d > 0 && (min == 0 || d < min) {
+			min = d
+		}
+		prev = cur
+		seen++
+	}
+	return min
+}
+
+// HybridClock reads milliseconds from the monotonic clock, which is precise
+// even where the wall clock is coarse, anchored to the wall clock and
+// re-anchored every resync. Millis never goes backwards.
+type HybridClock struct {
+	mu     sync.Mutex
+	anchor time.Time // 同时带有墙上时间与单调时间
+	resync time.Duration
+	last   int64
+}
+
+// NewHybridClock new a hybrid clock re-anchored to the wall clock every
+// resync; resync defaults to one second.
+func NewHybridClock(resync time.Duration) *HybridClock {
+	if resync <= 0 {
+		resync = time.Second
+	}
+	return &HybridClock{anchor: time.Now(), resync: resync}
+}
+
+// Millis returns the current unix millisecond.
+func (c *HybridClock) Millis() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elapsed := time.Since(c.anchor)
+	if elapsed >= c.resync {
+		c.anchor = time.Now()
+		elapsed = 0
+	}
+	now := c.anchor.UnixNano()/int64(time.Millisecond) + int64(elapsed/time.Millisecond)
+	if now < c.last {
+		// 重新锚定时墙上时钟可能落后一个粒度, 停在上次的值等它追上
+		now = c.last
+	}
+	c.last = now
+	return now
+}
+
+// WithClockCompensation measures the granularity of the system clock and,
+// when it is coarser than a millisecond, makes the worker read time from a
+// HybridClock, so every millisecond is usable instead of only one per tick.
+func WithClockCompensation() Option {
+	return func(id *IdWorker) error {
+		if ClockGranularity() > time.Millisecond {
+			id.clock = NewHybridClock(0)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeasureGranularity(t *testing.T) {
+	// 模拟 Windows 默认的 15.6ms 时钟
+	tick := 15600 * time.Microsecond
+	base := time.Unix(1700000000, 0)
+	calls := 0
+	now := func() time.Time {
+		calls++
+		return base.Add(time.Duration(calls/50) * tick)
+	}
+	if got := measureGranularity(now, 5); got != tick {
+		t.Errorf("got %s, want %s", got, tick)
+	}
+	if g := ClockGranularity(); g <= 0 || g > time.Second {
+		t.Errorf("system clock granularity %s", g)
+	}
+}
+
+func TestHybridClock(t *testing.T) {
+	c := NewHybridClock(5 * time.Millisecond)
+	prev := c.Millis()
+	if d := prev - timeGen(); d > 20 || d < -20 {
+		t.Errorf("hybrid clock off by %dms", d)
+	}
+	distinct := 0
+	deadline := time.Now().Add(30 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		cur := c.Millis()
+		if cur < prev {
+			t.Fatalf("went backwards: %d after %d", cur, prev)
+		}
+		if cur > prev {
+			distinct++
+		}
+		prev = cur
+	}
+	if distinct < 10 {
+		t.Errorf("only %d distinct milliseconds in 30ms", distinct)
+	}
+}
+
+func TestWithClockCompensation(t *testing.T) {
+	w, err := NewIdWorker(1, WithClockCompensation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := w.clock.(*HybridClock); ok != (ClockGranularity() > time.Millisecond) {
+		t.Errorf("hybrid clock chosen %v for granularity %s", ok, ClockGranularity())
+	}
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// NewAdminHandler new an http.Handler for the admin endpoints of worker:
+//
+//	POST /admin/rotate-node   {"node_id": ...}
+//	POST /admin/freeze        {"reason": "..."} in, the freeze out
+//	POST /admin/unfreeze      {"confirm": true} in
+//	GET  /admin/freeze        the freeze, or {"frozen": false}
+//
+// alloc may be nil when no spare node IDs are reserved; rotate-node then
+// fails. It does no authentication of its own; mount it behind auth.HTTP
+// and auth.RequireAdmin.
+func NewAdminHandler(worker *snowflake.IdWorker, alloc snowflake.NodeAllocator) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/rotate-node", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			Error(w, http.StatusMethodNotAllowed, errors.New("rotate-node needs POST"))
+			return
+		}
+		if alloc == nil {
+			Error(w, http.StatusConflict, errors.New("no spare node IDs configured"))
+			return
+		}
+		nodeId, err := worker.RotateNode(alloc)
+		if err != nil {
+			Error(w, http.StatusConflict, err)
+			return
+		}
+		JSON(w, http.StatusOK, map[string]int64{"node_id": nodeId})
+	})
+	mux.HandleFunc("/admin/freeze", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var req struct {
+				Reason string `json:"reason"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Reason == "" {
+				Error(w, http.StatusBadRequest, errors.New("freeze needs a reason"))
+				return
+			}
+			worker.Freeze(req.Reason)
+		default:
+			Error(w, http.StatusMethodNotAllowed, errors.New("freeze needs GET or POST"))
+			return
+		}
+		freezeStatus(w, worker)
+	})
+	mux.HandleFunc("/admin/unfreeze", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			Error(w, http.StatusMethodNotAllowed, errors.New("unfreeze needs POST"))
+			return
+		}
+		var req struct {
+			Confirm bool `json:"confirm"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Confirm {
+			Error(w, http.StatusBadRequest, errors.New("unfreeze needs {\"confirm\": true}"))
+			return
+		}
+		worker.Unfreeze()
+		freezeStatus(w, worker)
+	})
+	return mux
+}
+
+func freezeStatus(w http.ResponseWriter, worker *snowflake.IdWorker) {
+	status := map[string]interface{}{"frozen": false}
+	if f := worker.Frozen(); f != nil {
+		status = map[string]interface{}{"frozen": true, "reason": f.Reason, "since": f.Since}
+	}
+	JSON(w, http.StatusOK, status)
+}
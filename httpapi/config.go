@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Tunables are the settings /admin/config may change at runtime. Nil
+// fields are not configured and cannot be changed.
+type Tunables struct {
+	Drift  *snowflake.DriftMonitor
+	Quotas *Quotas
+}
+
+// Config is the body of /admin/config. GET returns the current settings;
+// POST changes the fields it sets and leaves the others. Quotas, when set,
+// replaces every per-principal quota.
+type Config struct {
+	DriftThresholdMillis *int64           `json:"drift_threshold_ms,omitempty"`
+	DefaultQuota         *int64           `json:"default_quota,omitempty"`
+	Quotas               map[string]int64 `json:"quotas,omitempty"`
+}
+
+// NewConfigHandler new an http.Handler serving GET and POST /admin/config
+// for t. Like NewAdminHandler it does no authentication of its own.
+func NewConfigHandler(t Tunables) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var c Config
+			if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+				Error(w, http.StatusBadRequest, err)
+				return
+			}
+			if err := t.apply(c); err != nil {
+				Error(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			Error(w, http.StatusMethodNotAllowed, errors.New("config needs GET or POST"))
+			return
+		}
+		JSON(w, http.StatusOK, t.current())
+	})
+}
+
+func (t Tunables) current() Config {
+	var c Config
+	if t.Drift != nil {
+		ms := int64(t.Drift.Threshold() / time.Millisecond)
+		c.DriftThresholdMillis = &ms
+	}
+	if t.Quotas != nil {
+		def, perMinute := t.Quotas.Limits()
+		c.DefaultQuota, c.Quotas = &def, perMinute
+	}
+	return c
+}
+
+// apply checks all of c before changing anything, so a bad request leaves
+// the settings as they were.
+func (t Tunables) apply(c Config) error {
+	if c.DriftThresholdMillis != nil {
+		if t.Drift == nil {
+			return errors.New("no drift monitor configured")
+		}
+		if *c.DriftThresholdMillis <= 0 {
+			return errors.New("drift threshold must be positive")
+		}
+	}
+	if c.DefaultQuota != nil || c.Quotas != nil {
+		if t.Quotas == nil {
+			return errors.New("no quotas configured")
+		}
+		if c.DefaultQuota != nil && *c.DefaultQuota < 0 {
+			return errors.New("quota must not be negative")
+		}
+		for _, n := range c.Quotas {
+			if n < 0 {
+				return errors.New("quota must not be negative")
+			}
+		}
+	}
+
+	if c.DriftThresholdMillis != nil {
+		t.Drift.SetThreshold(time.Duration(*c.DriftThresholdMillis) * time.Millisecond)
+	}
+	if c.DefaultQuota != nil || c.Quotas != nil {
+		def, perMinute := t.Quotas.Limits()
+		if c.DefaultQuota != nil {
+			def = *c.DefaultQuota
+		}
+		if c.Quotas != nil {
+			perMinute = c.Quotas
+		}
+		t.Quotas.Reconfigure(def, perMinute)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package httpapi_test
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/httpapi"
+)
+
+func ExampleNewHandler() {
+	worker, _ := snowflake.NewIdWorker(1)
+	srv := httptest.NewServer(httpapi.NewHandler(worker))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/decode?id=2703196044657165313")
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Print(string(body))
+	// Output: {"id":2703196044657165313,"time":1704067200,"district_id":1,"node_id":1,"tag":"none"}
+}
@@ -0,0 +1,177 @@
+// Package httpapi serves a snowflake IdWorker over HTTP.
+//
+//	GET /next?n=10       {"ids": [...]}
+//	GET /decode?id=...   {"id": ..., "time": ..., ...}
+//	POST /lease?n=1000   a snowflake.Lease the client mints ids from
+//	POST /decode-batch   {"ids": [...], "layout": ...} in, one Decoded per line out
+//	GET /debug/snowflake the snowflake.WorkerState of the worker
+//
+// Ids are encoded the way snowflake.StringIDs says, so setting it makes the
+// whole API string-based for JavaScript clients.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Decoded is the response of /decode.
+type Decoded struct {
+	ID         snowflake.ID `json:"id"`
+	Time       int64        `json:"time"`
+	DistrictId int64        `json:"district_id"`
+	NodeId     int64        `json:"node_id"`
+	Tag        string       `json:"tag"`
+}
+
+type handler struct {
+	worker *snowflake.IdWorker
+	mux    *http.ServeMux
+}
+
+// NewHandler new an http.Handler serving ids from worker.
+func NewHandler(worker *snowflake.IdWorker) http.Handler {
+	h := &handler{worker: worker, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/next", h.next)
+	h.mux.HandleFunc("/decode", h.validatedDecode)
+	h.mux.HandleFunc("/lease", h.lease)
+	h.mux.HandleFunc("/decode-batch", h.decodeBatch)
+	h.mux.HandleFunc("/debug/snowflake", func(w http.ResponseWriter, r *http.Request) {
+		JSON(w, http.StatusOK, h.worker.DumpState())
+	})
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) next(w http.ResponseWriter, r *http.Request) {
+	num := 1
+	if s := r.URL.Query().Get("n"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			Error(w, http.StatusBadRequest, err)
+			return
+		}
+		num = n
+	}
+	ids, err := h.worker.NextIds(num)
+	if errors.Is(err, snowflake.ErrFrozen) {
+		Error(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusBadRequest, err)
+		return
+	}
+	JSON(w, http.StatusOK, map[string][]snowflake.ID{"ids": ids})
+}
+
+func (h *handler) lease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, errors.New("lease needs POST"))
+		return
+	}
+	n, err := strconv.ParseInt(r.URL.Query().Get("n"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err)
+		return
+	}
+	l, err := h.worker.LeaseRange(n)
+	if errors.Is(err, snowflake.ErrFrozen) {
+		Error(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		Error(w, http.StatusBadRequest, err)
+		return
+	}
+	JSON(w, http.StatusOK, l)
+}
+
+// validatedDecode validates the id against the worker's current layout,
+// which a cutover may change, before decoding it.
+func (h *handler) validatedDecode(w http.ResponseWriter, r *http.Request) {
+	limits := IDLimits{Layout: h.worker.Layout(), Skew: time.Minute}
+	ValidateIDs(http.HandlerFunc(h.decode), limits, "id").ServeHTTP(w, r)
+}
+
+func (h *handler) decode(w http.ResponseWriter, r *http.Request) {
+	n, _ := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	JSON(w, http.StatusOK, decode(snowflake.ID(n), h.worker.Layout()))
+}
+
+// MaxDecodeBatch is the largest number of ids /decode-batch accepts.
+const MaxDecodeBatch = 10000
+
+// decodeBatch streams the decoded ids as JSON lines, flushing as it goes,
+// so large batches start arriving before the last id is decoded. Ids are
+// decoded with the worker's layout unless the request names another.
+func (h *handler) decodeBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, errors.New("decode-batch needs POST"))
+		return
+	}
+	var req struct {
+		Ids    []snowflake.ID `json:"ids"`
+		Layout string         `json:"layout"` // 如 "39-2-3-9-10", 空表示 worker 的布局
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Ids) > MaxDecodeBatch {
+		Error(w, http.StatusBadRequest, errors.New(fmt.Sprintf("at most %d ids per batch", MaxDecodeBatch)))
+		return
+	}
+	layout := h.worker.Layout()
+	if req.Layout != "" {
+		var err error
+		if layout, err = snowflake.ParseLayout(req.Layout); err != nil {
+			Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for i, id := range req.Ids {
+		if err := enc.Encode(decode(id, layout)); err != nil {
+			return
+		}
+		if flusher != nil && i%1000 == 999 {
+			flusher.Flush()
+		}
+	}
+}
+
+// decode decodes id with layout.
+func decode(id snowflake.ID, layout snowflake.Layout) Decoded {
+	v := id.WithLayout(layout)
+	return Decoded{
+		ID:         id,
+		Time:       v.Time(),
+		DistrictId: v.DistrictId(),
+		NodeId:     v.NodeId(),
+		Tag:        v.Tag().String(),
+	}
+}
+
+// JSON writes v as a JSON response with status code.
+func JSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Error writes err as a JSON error response with status code.
+func Error(w http.ResponseWriter, code int, err error) {
+	JSON(w, code, map[string]string{"error": err.Error()})
+}
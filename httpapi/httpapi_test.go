@@ -0,0 +1,364 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/auth"
+)
+
+func TestHandler(t *testing.T) {
+	snowflake.StringIDs = true
+	defer func() { snowflake.StringIDs = false }()
+	worker, _ := snowflake.NewIdWorker(4)
+	srv := httptest.NewServer(NewHandler(worker))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/next?n=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var next struct{ Ids []json.RawMessage }
+	json.NewDecoder(resp.Body).Decode(&next)
+	resp.Body.Close()
+	if len(next.Ids) != 3 || next.Ids[0][0] != '"' {
+		t.Fatalf("next: got %s", next.Ids)
+	}
+	var id snowflake.ID
+	json.Unmarshal(next.Ids[0], &id)
+
+	resp, err = http.Get(srv.URL + "/decode?id=" + id.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Decoded
+	json.NewDecoder(resp.Body).Decode(&d)
+	resp.Body.Close()
+	if d.ID != id || d.NodeId != 4 {
+		t.Errorf("decode: got %+v", d)
+	}
+
+	resp, err = http.Get(srv.URL + "/decode?id=1.2345678901234568e%2B18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("float id: got status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestDecodeTaggedLayout(t *testing.T) {
+	worker, _ := snowflake.NewOrderIdWorker(6)
+	id, _ := worker.NextId()
+	srv := httptest.NewServer(NewHandler(worker))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/decode?id=" + strconv.FormatInt(int64(id), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Decoded
+	json.NewDecoder(resp.Body).Decode(&d)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || d.Tag != "order" || d.NodeId != 6 || d.Time != id.WithLayout(snowflake.TaggedLayout).Time() {
+		t.Errorf("decode: %d %+v", resp.StatusCode, d)
+	}
+}
+
+func TestDecodeBatchLayout(t *testing.T) {
+	worker, _ := snowflake.NewOrderIdWorker(6)
+	srv := httptest.NewServer(NewHandler(worker))
+	defer srv.Close()
+	ids, _ := worker.NextIds(2)
+
+	post := func(req map[string]interface{}) []Decoded {
+		body, _ := json.Marshal(req)
+		resp, err := http.Post(srv.URL+"/decode-batch", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got []Decoded
+		for dec := json.NewDecoder(resp.Body); dec.More(); {
+			var d Decoded
+			if err := dec.Decode(&d); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, d)
+		}
+		return got
+	}
+	if got := post(map[string]interface{}{"ids": ids}); len(got) != 2 || got[1].Tag != "order" || got[1].NodeId != 6 {
+		t.Errorf("worker layout: got %+v", got)
+	}
+	want := ids[0].WithLayout(snowflake.DefaultLayout)
+	if got := post(map[string]interface{}{"ids": ids, "layout": "default"}); len(got) != 2 || got[0].Tag != "none" || got[0].NodeId != want.NodeId() || got[0].DistrictId != want.DistrictId() {
+		t.Errorf("requested layout: got %+v", got)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"ids": ids, "layout": "bogus"})
+	resp, err := http.Post(srv.URL+"/decode-batch", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad layout: got status %d", resp.StatusCode)
+	}
+}
+
+func TestValidateIDs(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := ValidateIDs(ok, IDLimits{Layout: snowflake.DefaultLayout, MaxDistrict: 3, MaxNode: 15}, "id")
+	get := func(v string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/?id="+v, nil))
+		return w.Code
+	}
+	// 区域 2 节点 0 序号 0 的 ID, JavaScript 舍入后借位成区域 1 节点 511
+	if code := get("2703161819170603008"); code != http.StatusOK {
+		t.Errorf("valid id: %d", code)
+	}
+	if code := get("2703161819170603000"); code != http.StatusBadRequest {
+		t.Errorf("rounded id: %d", code)
+	}
+	future := snowflake.ID((time.Now().Add(time.Hour).UnixMilli() - snowflake.DefaultLayout.Epoch) << 24)
+	if code := get(future.String()); code != http.StatusBadRequest {
+		t.Errorf("future id: %d", code)
+	}
+	if code := get("-1"); code != http.StatusBadRequest {
+		t.Errorf("negative id: %d", code)
+	}
+	other, _ := snowflake.NewIdWorker(1, snowflake.WithDistrictId(9))
+	if f, _ := other.NextId(); get(f.String()) != http.StatusBadRequest {
+		t.Error("district above the limit should be rejected")
+	}
+}
+
+func TestLease(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(4)
+	srv := httptest.NewServer(NewHandler(worker))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/lease?n=50", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l snowflake.Lease
+	json.NewDecoder(resp.Body).Decode(&l)
+	resp.Body.Close()
+	ids := l.IDs()
+	if len(ids) != 50 || ids[0].NodeId() != 4 {
+		t.Fatalf("lease: got %+v", l)
+	}
+	next, _ := worker.NextId()
+	if next <= ids[49] {
+		t.Errorf("worker id %d not after leased %d", next, ids[49])
+	}
+
+	resp, err = http.Get(srv.URL + "/lease?n=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET lease: got status %d", resp.StatusCode)
+	}
+}
+
+func TestAdminRotateNode(t *testing.T) {
+	alloc := snowflake.NewMemoryNodeAllocator(7, 8)
+	nodeId, _ := alloc.Acquire()
+	worker, _ := snowflake.NewIdWorker(nodeId)
+	srv := httptest.NewServer(NewAdminHandler(worker, alloc))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/admin/rotate-node", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		NodeId int64 `json:"node_id"`
+	}
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if id, _ := worker.NextId(); got.NodeId != 8 || id.NodeId() != 8 {
+		t.Errorf("rotated to %d, worker issues node %d", got.NodeId, id.NodeId())
+	}
+}
+
+func TestDecodeBatch(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(4)
+	srv := httptest.NewServer(NewHandler(worker))
+	defer srv.Close()
+
+	ids, _ := worker.NextIds(3)
+	body, _ := json.Marshal(map[string][]snowflake.ID{"ids": ids})
+	resp, err := http.Post(srv.URL+"/decode-batch", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(resp.Body)
+	var got []Decoded
+	for dec.More() {
+		var d Decoded
+		if err := dec.Decode(&d); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, d)
+	}
+	resp.Body.Close()
+	if len(got) != 3 || got[2].ID != ids[2] || got[2].NodeId != 4 {
+		t.Errorf("got %+v", got)
+	}
+
+	body, _ = json.Marshal(map[string][]snowflake.ID{"ids": make([]snowflake.ID, MaxDecodeBatch+1)})
+	resp, err = http.Post(srv.URL+"/decode-batch", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("oversized batch: got status %d", resp.StatusCode)
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	q, err := ReadQuotas(strings.NewReader("# quotas\nbatch 100\n* 10\nfree 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	q.now = func() time.Time { return now }
+	worker, _ := snowflake.NewIdWorker(1)
+	h := q.Middleware(NewHandler(worker))
+
+	get := func(name, url string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", url, nil)
+		r = r.WithContext(auth.NewContext(r.Context(), auth.Principal{Name: name}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	if w := get("batch", "/next?n=100"); w.Code != http.StatusOK {
+		t.Fatalf("within quota: %d", w.Code)
+	}
+	w := get("batch", "/next?n=1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("over quota: %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get("other", "/next?n=10"); w.Code != http.StatusOK {
+		t.Errorf("default quota: %d", w.Code)
+	}
+	if w := get("other", "/next?n=1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("default quota spent: %d", w.Code)
+	}
+	w = get("batch", "/next?n=101")
+	if w.Code != http.StatusBadRequest || w.Header().Get("Retry-After") != "" {
+		t.Errorf("larger than the quota: %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get("free", "/next?n=100"); w.Code != http.StatusOK {
+		t.Errorf("unlimited: %d", w.Code)
+	}
+	if w := get("batch", "/decode?id=1"); w.Code != http.StatusOK {
+		t.Errorf("decode is not charged: %d", w.Code)
+	}
+	now = now.Add(30 * time.Second)
+	if w := get("batch", "/next?n=50"); w.Code != http.StatusOK {
+		t.Errorf("refilled quota: %d", w.Code)
+	}
+	if _, err := ReadQuotas(strings.NewReader("batch -1\n")); err == nil {
+		t.Error("negative quota should fail")
+	}
+}
+
+func TestAdminFreeze(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(1)
+	mux := http.NewServeMux()
+	mux.Handle("/", NewHandler(worker))
+	mux.Handle("/admin/", NewAdminHandler(worker, nil))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	post := func(path, body string) int {
+		resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("/admin/freeze", `{"reason": "node conflict"}`); code != http.StatusOK {
+		t.Fatalf("freeze: %d", code)
+	}
+	resp, _ := http.Get(srv.URL + "/next")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("next while frozen: %d", resp.StatusCode)
+	}
+	if code := post("/admin/unfreeze", `{}`); code != http.StatusBadRequest || worker.Frozen() == nil {
+		t.Errorf("unconfirmed unfreeze: %d", code)
+	}
+	if code := post("/admin/unfreeze", `{"confirm": true}`); code != http.StatusOK || worker.Frozen() != nil {
+		t.Errorf("unfreeze: %d", code)
+	}
+	if code := post("/admin/rotate-node", ``); code != http.StatusConflict {
+		t.Errorf("rotate without spare nodes: %d", code)
+	}
+}
+
+func TestDebugState(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(5)
+	w := httptest.NewRecorder()
+	NewHandler(worker).ServeHTTP(w, httptest.NewRequest("GET", "/debug/snowflake", nil))
+	var s snowflake.WorkerState
+	if err := json.NewDecoder(w.Body).Decode(&s); err != nil || s.NodeId != 5 || s.LastTimestamp != -1 {
+		t.Errorf("got %+v, %v", s, err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(1)
+	drift := snowflake.NewDriftMonitor(worker, nil, time.Second, nil)
+	q := NewQuotas(10, map[string]int64{"batch": 100})
+	srv := httptest.NewServer(NewConfigHandler(Tunables{Drift: drift, Quotas: q}))
+	defer srv.Close()
+
+	post := func(body string) (int, Config) {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var c Config
+		json.NewDecoder(resp.Body).Decode(&c)
+		return resp.StatusCode, c
+	}
+	code, c := post(`{"drift_threshold_ms": 250, "default_quota": 5}`)
+	if code != http.StatusOK || *c.DriftThresholdMillis != 250 || *c.DefaultQuota != 5 || c.Quotas["batch"] != 100 {
+		t.Errorf("partial update: %d %+v", code, c)
+	}
+	if drift.Threshold() != 250*time.Millisecond {
+		t.Errorf("threshold: got %s", drift.Threshold())
+	}
+	if _, _, err := q.Allow("other", 6); err == nil {
+		t.Error("new default quota not applied")
+	}
+	if code, _ := post(`{"drift_threshold_ms": 100, "quotas": {"batch": -1}}`); code != http.StatusBadRequest || drift.Threshold() != 250*time.Millisecond {
+		t.Errorf("bad update should change nothing: %d, threshold %s", code, drift.Threshold())
+	}
+
+	srv2 := httptest.NewServer(NewConfigHandler(Tunables{Quotas: q}))
+	defer srv2.Close()
+	resp, _ := http.Post(srv2.URL, "application/json", strings.NewReader(`{"drift_threshold_ms": 100}`))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("drift without monitor: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,189 @@
+package httpapi
+
+import (
+	"bufio"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sakishum/go_snowflake/auth"
+)
+
+// Ids granted and requests refused by quotas, per principal.
+var (
+	quotaGranted  = expvar.NewMap("snowflake.quota_granted")
+	quotaRejected = expvar.NewMap("snowflake.quota_rejected")
+)
+
+// Quotas limits how many ids per minute each authenticated principal may
+// take from /next and /lease, so one misbehaving client of a shared
+// service cannot use up the sequence space of everyone else. A principal
+// may burst up to a minute's worth at once.
+type Quotas struct {
+	mu      sync.Mutex
+	limits  map[string]float64 // 每个调用方每分钟的 ID 数
+	def     float64            // 未列出的调用方, 0 表示不限
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewQuotas new quotas of perMinute ids for each named principal, and def
+// for the others; 0 means unlimited.
+func NewQuotas(def int64, perMinute map[string]int64) *Quotas {
+	q := &Quotas{
+		limits:  make(map[string]float64),
+		def:     float64(def),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+	for name, n := range perMinute {
+		q.limits[name] = float64(n)
+	}
+	return q
+}
+
+// ReadQuotas reads lines of "name ids-per-minute", where the name * sets
+// the quota of principals not listed; blank lines and lines starting with #
+// are skipped.
+func ReadQuotas(r io.Reader) (*Quotas, error) {
+	var def int64
+	perMinute := make(map[string]int64)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		var n int64
+		var err error
+		if len(f) == 2 {
+			n, err = strconv.ParseInt(f[1], 10, 64)
+		}
+		if len(f) != 2 || err != nil || n < 0 {
+			return nil, errors.New(fmt.Sprintf("quota: line %d: want \"name ids-per-minute\"", line))
+		}
+		if f[0] == "*" {
+			def = n
+		} else {
+			perMinute[f[0]] = n
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return NewQuotas(def, perMinute), nil
+}
+
+// Limits returns the quota of the principals not listed and the quotas of
+// the listed ones.
+func (q *Quotas) Limits() (def int64, perMinute map[string]int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	perMinute = make(map[string]int64, len(q.limits))
+	for name, n := range q.limits {
+		perMinute[name] = int64(n)
+	}
+	return int64(q.def), perMinute
+}
+
+// Reconfigure replaces the quotas as NewQuotas would set them, keeping
+// the ids each principal has left in its bucket up to its new limit.
+func (q *Quotas) Reconfigure(def int64, perMinute map[string]int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.def = float64(def)
+	q.limits = make(map[string]float64, len(perMinute))
+	for name, n := range perMinute {
+		q.limits[name] = float64(n)
+	}
+}
+
+// Allow takes n ids from the quota of name, or reports how long to wait
+// until they are available. A request for more ids than the bucket holds
+// can never be granted and fails with an error instead.
+func (q *Quotas) Allow(name string, n int64) (bool, time.Duration, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	limit, ok := q.limits[name]
+	if !ok {
+		limit = q.def
+	}
+	if limit == 0 {
+		return true, 0, nil
+	}
+	if float64(n) > limit {
+		return false, 0, errors.New(fmt.Sprintf("request of %d ids exceeds the quota of %s of %d ids per minute", n, name, int64(limit)))
+	}
+	now := q.now()
+	b := q.buckets[name]
+	if b == nil {
+		b = &bucket{tokens: limit, last: now}
+		q.buckets[name] = b
+	}
+	b.tokens = math.Min(limit, b.tokens+now.Sub(b.last).Minutes()*limit)
+	b.last = now
+	if float64(n) > b.tokens {
+		wait := time.Duration((float64(n) - b.tokens) / limit * float64(time.Minute))
+		return false, wait, nil
+	}
+	b.tokens -= float64(n)
+	return true, 0, nil
+}
+
+// Middleware charges the ids requested from /next and /lease to the
+// principal stored by auth.HTTP, answering 429 Too Many Requests with a
+// Retry-After header once the quota is spent, and 400 Bad Request to a
+// request larger than the whole quota. Mount it inside auth.HTTP.
+func (q *Quotas) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requestedIds(r)
+		if n == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		p, _ := auth.FromContext(r.Context())
+		ok, wait, err := q.Allow(p.Name, n)
+		if err != nil {
+			quotaRejected.Add(p.Name, 1)
+			Error(w, http.StatusBadRequest, err)
+			return
+		}
+		if !ok {
+			quotaRejected.Add(p.Name, 1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			Error(w, http.StatusTooManyRequests, errors.New(fmt.Sprintf("quota of %s exceeded", p.Name)))
+			return
+		}
+		quotaGranted.Add(p.Name, n)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestedIds returns how many ids r asks for; invalid counts are charged
+// as one and left to the handler to reject.
+func requestedIds(r *http.Request) int64 {
+	switch r.URL.Path {
+	case "/next", "/lease":
+	default:
+		return 0
+	}
+	n, err := strconv.ParseInt(r.URL.Query().Get("n"), 10, 64)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
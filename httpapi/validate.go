@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// IDLimits bounds the ids ValidateIDs accepts beyond parsing as an int64.
+type IDLimits struct {
+	Layout      snowflake.Layout // 解析 ID 的布局
+	MaxDistrict int64            // 最大区域 ID, 0 表示布局允许的最大值
+	MaxNode     int64            // 最大节点 ID, 0 表示布局允许的最大值
+	Skew        time.Duration    // 允许的未来时间, 容忍节点间的时钟偏差
+}
+
+// check checks the fields of f decoded with the layout are within the
+// limits, with a timestamp between the epoch and now.
+func (l IDLimits) check(f snowflake.ID, now time.Time) error {
+	if f < l.Layout.MinID() || f > l.Layout.MaxID() {
+		return errors.New(fmt.Sprintf("does not fit in layout %s", l.Layout))
+	}
+	v := f.WithLayout(l.Layout)
+	if ts := v.Timestamp(); ts > now.Add(l.Skew).UnixMilli() {
+		return errors.New(fmt.Sprintf("timestamp %d is in the future", ts))
+	}
+	maxDistrict, maxNode := l.Layout.MaxDistrictId(), l.Layout.MaxNodeId()
+	if l.MaxDistrict > 0 && l.MaxDistrict < maxDistrict {
+		maxDistrict = l.MaxDistrict
+	}
+	if l.MaxNode > 0 && l.MaxNode < maxNode {
+		maxNode = l.MaxNode
+	}
+	if d := v.DistrictId(); d > maxDistrict {
+		return errors.New(fmt.Sprintf("district %d is above %d", d, maxDistrict))
+	}
+	if n := v.NodeId(); n > maxNode {
+		return errors.New(fmt.Sprintf("node %d is above %d", n, maxNode))
+	}
+	return nil
+}
+
+// ValidateIDs rejects requests whose query parameters params are not plain
+// decimal int64 ids, such as "1.2345678901234568e+18" or out of range values
+// produced by JavaScript clients that held an id in a number.
+//
+// Ids a client rounded to a float64 still parse, so each id is also decoded
+// with limits: its timestamp must not be in the future, and its district and
+// node must be within the limits. Rounding only changes the low bits, so
+// this catches the rounded ids whose sequence borrowed from or carried into
+// the node, which is likelier the tighter MaxNode is.
+func ValidateIDs(next http.Handler, limits IDLimits, params ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		for _, p := range params {
+			for _, v := range q[p] {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					Error(w, http.StatusBadRequest, errors.New(fmt.Sprintf("%s: %q is not a snowflake id, send ids as strings", p, v)))
+					return
+				}
+				if err := limits.check(snowflake.ID(n), time.Now()); err != nil {
+					Error(w, http.StatusBadRequest, errors.New(fmt.Sprintf("%s: %d is not a valid snowflake id: %v, send ids as strings", p, n, err)))
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package snowflake
+
+import (
+	"context"
+	"iter"
+)
+
+// Iter returns an endless sequence of ids for range-over-func loops:
+//
+//	for id, err := range worker.Iter(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The sequence ends when ctx is done or the loop breaks. Failures, e.g.
+// during a clock rollback, are yielded with a zero id; a loop going on
+// after one retries at once.
+func (id *IdWorker) Iter(ctx context.Context) iter.Seq2[ID, error] {
+	return func(yield func(ID, error) bool) {
+		for ctx.Err() == nil {
+			if !yield(id.NextId()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+//go:build go1.23
+
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIter(t *testing.T) {
+	w, _ := NewIdWorker(1)
+	var prev ID
+	n := 0
+	for id, err := range w.Iter(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d after %d", id, prev)
+		}
+		prev = id
+		if n++; n == 100 {
+			break
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n = 0
+	for range w.Iter(ctx) {
+		if n++; n == 10 {
+			cancel()
+		}
+	}
+	if n != 10 {
+		t.Errorf("got %d ids after cancel, want 10", n)
+	}
+
+	w.Freeze("test")
+	for id, err := range w.Iter(context.Background()) {
+		if !errors.Is(err, ErrFrozen) || id != 0 {
+			t.Errorf("frozen worker: got %d, %v", id, err)
+		}
+		break
+	}
+}
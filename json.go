@@ -0,0 +1,45 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// StringIDs switches the package to string-encoded ids on every external
+// surface: ID marshals to a JSON string and, when decoding, JSON numbers
+// beyond MaxSafeInteger are rejected since a JavaScript client has already
+// rounded them. Set it once at startup.
+var StringIDs = false
+
+// MaxSafeInteger is the largest integer a JavaScript number holds exactly.
+const MaxSafeInteger = 1<<53 - 1
+
+// MarshalJSON encodes f as a JSON number, or as a string in StringIDs mode.
+func (f ID) MarshalJSON() ([]byte, error) {
+	if StringIDs {
+		return []byte(strconv.Quote(f.String())), nil
+	}
+	return []byte(f.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a JSON string.
+func (f *ID) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	quoted := len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"'
+	if quoted {
+		s = s[1 : len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid snowflake id %s", b))
+	}
+	if StringIDs && !quoted && (n > MaxSafeInteger || n < -MaxSafeInteger) {
+		return errors.New(fmt.Sprintf("snowflake id %s exceeds the JavaScript safe integer range, send it as a string", b))
+	}
+	*f = ID(n)
+	return nil
+}
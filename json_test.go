@@ -0,0 +1,41 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIDJSON(t *testing.T) {
+	id := ID(1<<60 + 1)
+	b, _ := json.Marshal(id)
+	if string(b) != "1152921504606846977" {
+		t.Errorf("marshal: got %s", b)
+	}
+	var got ID
+	if err := json.Unmarshal([]byte(`"1152921504606846977"`), &got); err != nil || got != id {
+		t.Errorf("unmarshal string: got %d, %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(`1152921504606846977`), &got); err != nil || got != id {
+		t.Errorf("unmarshal number: got %d, %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(`"abc"`), &got); err == nil {
+		t.Error("garbage should be rejected")
+	}
+}
+
+func TestIDJSONStringMode(t *testing.T) {
+	StringIDs = true
+	defer func() { StringIDs = false }()
+	id := ID(1<<60 + 1)
+	b, _ := json.Marshal(id)
+	if string(b) != `"1152921504606846977"` {
+		t.Errorf("marshal: got %s", b)
+	}
+	var got ID
+	if err := json.Unmarshal([]byte(`1152921504606847000`), &got); err == nil {
+		t.Error("unsafe number should be rejected")
+	}
+	if err := json.Unmarshal([]byte(`12345`), &got); err != nil || got != 12345 {
+		t.Errorf("safe number: got %d, %v", got, err)
+	}
+}
@@ -0,0 +1,176 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Layout describes how the 63 usable bits of an id are split, from the most
+// significant field to the least, and the epoch the timestamp counts from.
+type Layout struct {
+	TimestampBits uint  `json:"timestamp_bits"`
+	TagBits       uint  `json:"tag_bits"`
+	DistrictBits  uint  `json:"district_bits"`
+	NodeBits      uint  `json:"node_bits"`
+	SequenceBits  uint  `json:"sequence_bits"`
+	Epoch         int64 `json:"epoch"`            // 毫秒
+	Micros        bool  `json:"micros,omitempty"` // 时间戳以微秒计
+}
+
+// DefaultLayout is the layout used by NewIdWorker, without a tag.
+var DefaultLayout = Layout{
+	TimestampBits: 63 - DistrictIdBits - NodeIdBits - sequenceBits,
+	DistrictBits:  DistrictIdBits,
+	NodeBits:      NodeIdBits,
+	SequenceBits:  sequenceBits,
+	Epoch:         twepoch,
+}
+
+// TaggedLayout is the default layout with the two high district bits
+// given to a type tag, as used by NewTaggedIdWorker. Its untagged ids of
+// districts 0 to 7 are the same as in the default layout.
+var TaggedLayout = Layout{
+	TimestampBits: DefaultLayout.TimestampBits,
+	TagBits:       TagBits,
+	DistrictBits:  DistrictIdBits - TagBits,
+	NodeBits:      NodeIdBits,
+	SequenceBits:  sequenceBits,
+	Epoch:         twepoch,
+}
+
+// Validate checks the fields fit in 63 bits.
+func (l Layout) Validate() error {
+	if l.TimestampBits == 0 || l.SequenceBits == 0 {
+		return errors.New("layout needs timestamp and sequence bits")
+	}
+	if total := l.TimestampBits + l.TagBits + l.DistrictBits + l.NodeBits + l.SequenceBits; total > 63 {
+		return errors.New(fmt.Sprintf("layout uses %d bits, at most 63 allowed", total))
+	}
+	return nil
+}
+
+// MaxNodeId, MaxDistrictId, MaxTag, MaxSequence and MaxTimestamp return the
+// largest value each field can hold.
+func (l Layout) MaxNodeId() int64     { return -1 ^ (-1 << l.NodeBits) }
+func (l Layout) MaxDistrictId() int64 { return -1 ^ (-1 << l.DistrictBits) }
+func (l Layout) MaxTag() int64        { return -1 ^ (-1 << l.TagBits) }
+func (l Layout) MaxSequence() int64   { return -1 ^ (-1 << l.SequenceBits) }
+func (l Layout) MaxTimestamp() int64  { return -1 ^ (-1 << l.TimestampBits) }
+
+func (l Layout) nodeShift() uint     { return l.SequenceBits }
+func (l Layout) districtShift() uint { return l.SequenceBits + l.NodeBits }
+func (l Layout) tagShift() uint      { return l.SequenceBits + l.NodeBits + l.DistrictBits }
+func (l Layout) timestampShift() uint {
+	return l.SequenceBits + l.NodeBits + l.DistrictBits + l.TagBits
+}
+
+// Epoch returns the epoch of the worker in unix milliseconds.
+func (id *IdWorker) Epoch() int64 {
+	id.Lock()
+	defer id.Unlock()
+	return id.epoch()
+}
+
+// epoch returns the epoch timestamps are packed from, moved back by the
+// offset of WithEpochOffset.
+func (id *IdWorker) epoch() int64 {
+	return id.twepoch - id.offset
+}
+
+// Layout returns the layout of the ids issued by the worker.
+func (id *IdWorker) Layout() Layout {
+	id.Lock()
+	defer id.Unlock()
+	return id.currentLayout()
+}
+
+func (id *IdWorker) currentLayout() Layout {
+	l := id.layout
+	l.Epoch = id.epoch()
+	return l
+}
+
+// checkLayout checks the worker's node, district and tag fit in l.
+func (id *IdWorker) checkLayout(l Layout) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	if id.nodeId > l.MaxNodeId() {
+		return errors.New(fmt.Sprintf("node %d does not fit in %d node bits", id.nodeId, l.NodeBits))
+	}
+	if id.districtId > l.MaxDistrictId() {
+		return errors.New(fmt.Sprintf("district %d does not fit in %d district bits", id.districtId, l.DistrictBits))
+	}
+	if err := checkDistrict(l, id.districtId); err != nil {
+		return err
+	}
+	if id.tag > l.MaxTag() {
+		return errors.New(fmt.Sprintf("tag %d does not fit in %d tag bits", id.tag, l.TagBits))
+	}
+	return nil
+}
+
+// WithLayout makes the worker issue ids in layout l, including its epoch.
+// Ids of a non-default layout must be decoded with ID.WithLayout.
+func WithLayout(l Layout) Option {
+	return func(id *IdWorker) error {
+		if err := id.checkLayout(l); err != nil {
+			return err
+		}
+		id.layout = l
+		id.twepoch = l.Epoch
+		return nil
+	}
+}
+
+// LayoutID is a view of an id decoded with an explicit layout.
+type LayoutID struct {
+	ID     ID
+	Layout Layout
+}
+
+// WithLayout returns a view decoding f with layout.
+func (f ID) WithLayout(layout Layout) LayoutID {
+	return LayoutID{ID: f, Layout: layout}
+}
+
+// Timestamp returns the unix millisecond the id was issued at.
+func (v LayoutID) Timestamp() int64 {
+	return (int64(v.ID)>>v.Layout.timestampShift()&v.Layout.MaxTimestamp())/v.Layout.tick() + v.Layout.Epoch
+}
+
+// Time returns the unix second the id was issued at.
+func (v LayoutID) Time() int64 {
+	return v.Timestamp() / 1e3
+}
+
+func (v LayoutID) Tag() Tag {
+	return Tag(int64(v.ID) >> v.Layout.tagShift() & v.Layout.MaxTag())
+}
+
+func (v LayoutID) DistrictId() int64 {
+	return int64(v.ID) >> v.Layout.districtShift() & v.Layout.MaxDistrictId()
+}
+
+func (v LayoutID) NodeId() int64 {
+	return int64(v.ID) >> v.Layout.nodeShift() & v.Layout.MaxNodeId()
+}
+
+func (v LayoutID) Sequence() int64 {
+	return int64(v.ID) & v.Layout.MaxSequence()
+}
+
+// MinID returns the smallest id of the layout.
+func (l Layout) MinID() ID {
+	return 0
+}
+
+// MaxID returns the largest id of the layout.
+func (l Layout) MaxID() ID {
+	return ID(-1 ^ (-1 << (l.TimestampBits + l.timestampShift())))
+}
+
+// String returns the bit widths as "timestamp-tag-district-node-sequence".
+func (l Layout) String() string {
+	return fmt.Sprintf("%d-%d-%d-%d-%d", l.TimestampBits, l.TagBits, l.DistrictBits, l.NodeBits, l.SequenceBits)
+}
@@ -0,0 +1,46 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestWorkerLayout(t *testing.T) {
+	idworker, _ := NewIdWorker(7)
+	if idworker.Epoch() != twepoch {
+		t.Errorf("epoch: got %d, want %d", idworker.Epoch(), twepoch)
+	}
+	layout := idworker.Layout()
+	if err := layout.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if layout.TimestampBits != 39 {
+		t.Errorf("timestamp bits: got %d, want 39", layout.TimestampBits)
+	}
+	id, _ := idworker.NextId()
+	v := id.WithLayout(layout)
+	if v.NodeId() != id.NodeId() || v.DistrictId() != id.DistrictId() || v.Tag() != TagNone || v.Time() != id.Time() {
+		t.Errorf("layout view %+v disagrees with id %d", v, id)
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	l := DefaultLayout
+	l.NodeBits++
+	if err := l.Validate(); err == nil {
+		t.Error("64 bit layout should be rejected")
+	}
+}
+
+func TestMinMaxID(t *testing.T) {
+	if DefaultLayout.MinID() != MinID || DefaultLayout.MaxID() != MaxID {
+		t.Errorf("default layout bounds: got [%d, %d]", DefaultLayout.MinID(), DefaultLayout.MaxID())
+	}
+	if !ID(0).IsZero() || ID(0).IsValid() || ID(-1).IsValid() {
+		t.Error("zero and negative ids are not valid")
+	}
+	idworker, _ := NewIdWorker(1)
+	id, _ := idworker.NextId()
+	if id.IsZero() || !id.IsValid() {
+		t.Errorf("generated id %d should be valid", id)
+	}
+}
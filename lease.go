@@ -0,0 +1,99 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Lease is a block of sequences of one millisecond reserved for a client,
+// which mints the ids itself, offline, without asking the worker for each.
+type Lease struct {
+	Layout     Layout `json:"layout"`    // 起始时间戳为所在区域的, 见 WithDistrictEpochs
+	Timestamp  int64  `json:"timestamp"` // unix 毫秒, 微秒布局下为微秒
+	NodeId     int64  `json:"node_id"`
+	DistrictId int64  `json:"district_id"`
+	Tag        int64  `json:"tag"`
+	First      int64  `json:"first"` // 第一个序号
+	Last       int64  `json:"last"`  // 最后一个序号, 含
+}
+
+// LeaseRange reserves n sequences of a fresh millisecond. The worker never
+// issues ids in that millisecond itself, so the lease holder owns all of
+// them.
+func (id *IdWorker) LeaseRange(n int64) (Lease, error) {
+	id.Lock()
+	defer id.Unlock()
+	if max := id.layout.MaxSequence(); n < 1 || n > max+1 {
+		return Lease{}, errors.New(fmt.Sprintf("lease size must be between 1 and %d", max+1))
+	}
+	if id.frozen != nil {
+		return Lease{}, id.frozen
+	}
+	if id.lease != nil && id.lease.lost {
+		return Lease{}, ErrNodeLeaseLost
+	}
+	if _, ok := id.sequencer.(*SteppedSequencer); ok {
+		return Lease{}, errors.New("a worker sharing its node through sequence partitions cannot lease ranges")
+	}
+	timestamp := id.clock.Millis()
+	if timestamp < id.lastTimestamp {
+		id.stats.Errors++
+		expvarErrors.Add(1)
+		return Lease{}, &ClockMovedBackwardsError{Millis: id.lastTimestamp - timestamp}
+	}
+	if timestamp == id.lastTimestamp {
+		timestamp = id.tilNextMillis(id.lastTimestamp)
+	}
+	if id.store != nil {
+		if err := id.store.Save(timestamp); err != nil {
+			id.stats.Errors++
+			expvarErrors.Add(1)
+			return Lease{}, err
+		}
+	}
+	id.applyCutover(timestamp)
+	if id.onTick != nil {
+		id.onTick(id.lastTimestamp, timestamp)
+	}
+	id.lastTimestamp = timestamp
+	id.sequence = -1 // 本毫秒剩下的序号也不再使用
+	id.stats.Generated += n
+	expvarGenerated.Add(n)
+	layout := id.currentLayout()
+	layout.Epoch = id.epochFor(id.districtId) / layout.tick()
+	l := Lease{
+		Layout:     layout,
+		Timestamp:  timestamp,
+		NodeId:     id.nodeId,
+		DistrictId: id.districtId,
+		Tag:        id.tag,
+		First:      0,
+		Last:       n - 1,
+	}
+	if id.audit != nil {
+		id.audit.record(id.nodeId, timestamp, l.ID(0), l.ID(l.Len()-1), n)
+	}
+	id.log(slog.LevelDebug, "snowflake: id range leased", "timestamp", timestamp, "ids", n)
+	return l, nil
+}
+
+// Len returns the number of ids in the lease.
+func (l Lease) Len() int {
+	return int(l.Last - l.First + 1)
+}
+
+// ID returns the i-th id of the lease, 0 <= i < Len().
+func (l Lease) ID(i int) ID {
+	y := l.Layout
+	return ID(((l.Timestamp - y.Epoch*y.tick()) << y.timestampShift()) | (l.Tag << y.tagShift()) | (l.DistrictId << y.districtShift()) | (l.NodeId << y.nodeShift()) | (l.First + int64(i)))
+}
+
+// IDs returns every id of the lease.
+func (l Lease) IDs() []ID {
+	ids := make([]ID, l.Len())
+	for i := range ids {
+		ids[i] = l.ID(i)
+	}
+	return ids
+}
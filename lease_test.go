@@ -0,0 +1,60 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLeaseRange(t *testing.T) {
+	w, _ := NewTaggedIdWorker(3, TagOrder)
+	before, _ := w.NextId()
+	l, err := w.LeaseRange(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _ := w.NextId()
+
+	b, _ := json.Marshal(l)
+	var got Lease
+	if err := json.Unmarshal(b, &got); err != nil || got != l {
+		t.Fatalf("json round trip: got %+v, %v", got, err)
+	}
+	ids := got.IDs()
+	if len(ids) != 100 {
+		t.Fatalf("got %d ids", len(ids))
+	}
+	for i, id := range ids {
+		if id <= before || id >= after {
+			t.Fatalf("id %d: %d not between %d and %d", i, id, before, after)
+		}
+		if id.NodeId() != 3 || id.WithLayout(TaggedLayout).Tag() != TagOrder || id.timestamp() != l.Timestamp {
+			t.Fatalf("id %d: %d decodes wrongly", i, id)
+		}
+	}
+	if after.timestamp() == l.Timestamp {
+		t.Error("worker reused the leased millisecond")
+	}
+	if _, err := w.LeaseRange(0); err == nil {
+		t.Error("empty lease should fail")
+	}
+	if _, err := w.LeaseRange(w.Layout().MaxSequence() + 2); err == nil {
+		t.Error("oversized lease should fail")
+	}
+}
+
+func TestLeaseRangeDistrictEpoch(t *testing.T) {
+	epoch := twepoch + 86_400_000
+	w, _ := NewIdWorker(3, WithDistrictEpochs(DistrictEpochs{1: epoch}))
+	l, err := w.LeaseRange(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := w.NextId()
+	got := l.ID(0)
+	if v := (DistrictEpochs{1: epoch}).Decode(got, DefaultLayout); v.Timestamp() != l.Timestamp {
+		t.Errorf("leased id decodes to %d, want %d", v.Timestamp(), l.Timestamp)
+	}
+	if got >= want {
+		t.Errorf("leased id %d not before the next id %d", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package snowflake
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ErrNodeLeaseLost is returned while the worker's node ID lease is lost
+// under LeaseLossFailFast, and by LeaseRange under any policy.
+var ErrNodeLeaseLost = errors.New("snowflake: node ID lease lost")
+
+// LeaseLossPolicy says what a worker does between losing the lease on its
+// node ID, e.g. in Redis or etcd, and acquiring a new one. Without a
+// policy a worker knows nothing of leases and keeps its node.
+type LeaseLossPolicy int
+
+const (
+	LeaseLossFailFast   LeaseLossPolicy = iota // 返回 ErrNodeLeaseLost
+	LeaseLossRandomFill                        // 临时改为随机填充, 见 WithRandomFill
+	LeaseLossBlock                             // 阻塞直到重新获得租约
+)
+
+type nodeLease struct {
+	policy     LeaseLossPolicy
+	notify     func(lost bool, nodeId int64)
+	lost       bool
+	back       chan struct{} // 重新获得租约时关闭
+	randomFill bool          // 丢失前的设置
+	sequencer  Sequencer
+}
+
+// WithLeaseLossPolicy makes the worker apply policy between LeaseLost and
+// LeaseRestored. notify, if not nil, is called with the lock held on both,
+// with the node ID lost or restored.
+func WithLeaseLossPolicy(policy LeaseLossPolicy, notify func(lost bool, nodeId int64)) Option {
+	return func(id *IdWorker) error {
+		if policy < LeaseLossFailFast || policy > LeaseLossBlock {
+			return errors.New("unknown lease loss policy")
+		}
+		id.lease = &nodeLease{policy: policy, notify: notify}
+		return nil
+	}
+}
+
+// LeaseLost tells the worker its node ID lease is gone: another worker may
+// own the node from now on. The keeper of the lease calls it.
+func (id *IdWorker) LeaseLost() {
+	id.Lock()
+	defer id.Unlock()
+	l := id.lease
+	if l == nil || l.lost {
+		return
+	}
+	l.lost = true
+	l.back = make(chan struct{})
+	if l.policy == LeaseLossRandomFill {
+		l.randomFill, l.sequencer = id.randomFill, id.sequencer
+		id.randomFill, id.sequencer = true, &cryptoStartSequencer{}
+	}
+	id.sequence = -1 // 本毫秒不再用原节点
+	id.log(slog.LevelWarn, "snowflake: node lease lost", "policy", int(l.policy))
+	if l.notify != nil {
+		l.notify(true, id.nodeId)
+	}
+}
+
+// LeaseRestored tells the worker it holds the lease on nodeId, which may
+// differ from the node lost, and resumes normal issuance from the next
+// millisecond.
+func (id *IdWorker) LeaseRestored(nodeId int64) error {
+	id.Lock()
+	defer id.Unlock()
+	l := id.lease
+	if l == nil || !l.lost {
+		return nil
+	}
+	old := id.nodeId
+	id.nodeId = nodeId
+	if err := id.checkLayout(id.layout); err != nil {
+		id.nodeId = old
+		return err
+	}
+	if l.policy == LeaseLossRandomFill {
+		id.randomFill, id.sequencer = l.randomFill, l.sequencer
+	}
+	id.sequence = -1
+	l.lost = false
+	close(l.back)
+	id.log(slog.LevelInfo, "snowflake: node lease restored", "restored_node_id", nodeId)
+	if l.notify != nil {
+		l.notify(false, nodeId)
+	}
+	return nil
+}
+
+// LeaseRenewed tells the worker the keeper renewed the lease on its node
+// ID. It only logs the renewal; a lost lease comes back with LeaseRestored.
+func (id *IdWorker) LeaseRenewed() {
+	id.Lock()
+	defer id.Unlock()
+	if id.lease != nil && !id.lease.lost {
+		id.log(slog.LevelDebug, "snowflake: node lease renewed")
+	}
+}
+
+// checkLease applies the lease loss policy before issuing an id. Called
+// with the lock held; under LeaseLossBlock it releases the lock while it
+// waits and reports true once the lease is back, so the caller rereads the
+// clock.
+func (id *IdWorker) checkLease() (waited bool, err error) {
+	for id.lease != nil && id.lease.lost {
+		switch id.lease.policy {
+		case LeaseLossFailFast:
+			return waited, ErrNodeLeaseLost
+		case LeaseLossRandomFill:
+			return waited, nil
+		}
+		back := id.lease.back
+		id.Unlock()
+		<-back
+		id.Lock()
+		waited = true
+	}
+	return waited, nil
+}
@@ -0,0 +1,73 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeaseLossFailFast(t *testing.T) {
+	var events []bool
+	idworker, _ := NewIdWorker(3, WithLeaseLossPolicy(LeaseLossFailFast, func(lost bool, nodeId int64) {
+		events = append(events, lost)
+	}))
+	idworker.LeaseLost()
+	if _, err := idworker.NextId(); !errors.Is(err, ErrNodeLeaseLost) {
+		t.Errorf("want ErrNodeLeaseLost, got %v", err)
+	}
+	if _, err := idworker.LeaseRange(1); !errors.Is(err, ErrNodeLeaseLost) {
+		t.Errorf("LeaseRange: want ErrNodeLeaseLost, got %v", err)
+	}
+	if err := idworker.LeaseRestored(4); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := idworker.NextId(); err != nil || id.NodeId() != 4 {
+		t.Errorf("after restore: %d, %v", id.NodeId(), err)
+	}
+	if len(events) != 2 || !events[0] || events[1] {
+		t.Errorf("events: %v", events)
+	}
+	if err := idworker.LeaseRestored(maxNodeId + 1); err != nil {
+		t.Errorf("restore without loss should be a no-op, got %v", err)
+	}
+}
+
+func TestLeaseLossRandomFill(t *testing.T) {
+	idworker, _ := NewIdWorker(3, WithLeaseLossPolicy(LeaseLossRandomFill, nil))
+	idworker.NextId()
+	idworker.LeaseLost()
+	seen := make(map[ID]bool)
+	for i := 0; i < 2000; i++ {
+		id, err := idworker.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	idworker.LeaseRestored(3)
+	if id, _ := idworker.NextId(); id.NodeId() != 3 || idworker.randomFill {
+		t.Errorf("after restore: node %d, random fill %v", id.NodeId(), idworker.randomFill)
+	}
+}
+
+func TestLeaseLossBlock(t *testing.T) {
+	idworker, _ := NewIdWorker(3, WithLeaseLossPolicy(LeaseLossBlock, nil))
+	idworker.LeaseLost()
+	done := make(chan ID)
+	go func() {
+		id, _ := idworker.NextId()
+		done <- id
+	}()
+	select {
+	case <-done:
+		t.Fatal("NextId should block while the lease is lost")
+	case <-time.After(20 * time.Millisecond):
+	}
+	idworker.LeaseRestored(5)
+	if id := <-done; id.NodeId() != 5 {
+		t.Errorf("got node %d, want 5", id.NodeId())
+	}
+}
@@ -0,0 +1,85 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// exhaustedStreakLog is the shortest run of exhausted ticks worth a log.
+const exhaustedStreakLog = 5
+
+// workerLog is the logging state of a worker set up by WithLogger.
+type workerLog struct {
+	logger      *slog.Logger
+	rollback    int64 // 当前回拨的毫秒数, 0 表示未回拨
+	streak      int64 // 连续耗尽序号的 tick 数
+	exhaustedAt int64 // 最近一次耗尽的 tick
+}
+
+// WithLogger makes the worker log notable events to logger instead of
+// staying silent: a clock rollback at warn level and its recovery at info,
+// loss, renewal and restoration of the node ID lease, leased id ranges,
+// freezes, counter mode, and streaks of exhausted ticks, which mean the
+// worker is saturated. Events are logged with the worker's lock held, so
+// the handler should be quick.
+func WithLogger(logger *slog.Logger) Option {
+	return func(id *IdWorker) error {
+		if logger == nil {
+			return errors.New("WithLogger needs a logger")
+		}
+		id.logs = &workerLog{logger: logger}
+		return nil
+	}
+}
+
+// log logs an event when the worker has a logger. Called with the lock
+// held.
+func (id *IdWorker) log(level slog.Level, msg string, args ...interface{}) {
+	if id.logs != nil {
+		id.logs.logger.Log(context.Background(), level, msg, append([]interface{}{"node_id", id.nodeId}, args...)...)
+	}
+}
+
+// logRollback records a refused id during a clock rollback, logging the
+// start of the rollback only.
+func (id *IdWorker) logRollback(millis int64) {
+	if id.logs == nil {
+		return
+	}
+	if id.logs.rollback == 0 {
+		id.log(slog.LevelWarn, "snowflake: clock moved backwards, refusing ids", "millis", millis)
+	}
+	if millis > id.logs.rollback {
+		id.logs.rollback = millis
+	}
+}
+
+// logIssued notes an id issued at timestamp, or the exhaustion of the
+// tick: an id ends a rollback, and an id on a tick after the last
+// exhausted one ends the streak of exhausted ticks.
+func (id *IdWorker) logIssued(timestamp int64, exhausted bool) {
+	w := id.logs
+	if w == nil {
+		return
+	}
+	if w.rollback > 0 {
+		id.log(slog.LevelInfo, "snowflake: clock rollback recovered", "millis", w.rollback)
+		w.rollback = 0
+	}
+	if exhausted {
+		if w.exhaustedAt == timestamp-1 {
+			w.streak++
+		} else if w.exhaustedAt != timestamp {
+			w.streak = 1
+		}
+		w.exhaustedAt = timestamp
+		return
+	}
+	if timestamp > w.exhaustedAt+1 && w.streak > 0 {
+		if w.streak >= exhaustedStreakLog {
+			id.log(slog.LevelWarn, "snowflake: sequence exhausted on consecutive ticks", "ticks", w.streak)
+		}
+		w.streak = 0
+	}
+}
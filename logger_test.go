@@ -0,0 +1,65 @@
+package snowflake
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	clock := &manualClock{ms: 1_700_000_000_000}
+	idworker, _ := NewIdWorker(2, WithClock(clock), WithLogger(logger), WithLeaseLossPolicy(LeaseLossFailFast, nil))
+
+	idworker.NextId()
+	clock.ms -= 5
+	idworker.NextId()
+	idworker.NextId()
+	clock.ms += 10
+	idworker.NextId()
+
+	// 连续 exhaustedStreakLog 个 tick 用尽序号, 再到一个未用尽的 tick;
+	// 手动时钟不会自己前进, 所以不等待下一毫秒
+	idworker.Lock()
+	for i := 0; i < exhaustedStreakLog; i++ {
+		clock.ms++
+		for {
+			if _, err := idworker.nextidAt(clock.ms, false); err == ErrSequenceExhausted {
+				break
+			}
+		}
+	}
+	idworker.Unlock()
+	clock.ms += 2
+	idworker.NextId()
+
+	idworker.LeaseRenewed()
+	clock.ms++
+	idworker.LeaseRange(10)
+	idworker.LeaseLost()
+	idworker.LeaseRestored(3)
+	idworker.Freeze("test")
+	idworker.Unfreeze()
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=WARN msg="snowflake: clock moved backwards, refusing ids" node_id=2 millis=5`,
+		`level=INFO msg="snowflake: clock rollback recovered" node_id=2 millis=5`,
+		`level=WARN msg="snowflake: sequence exhausted on consecutive ticks" node_id=2 ticks=`,
+		`level=DEBUG msg="snowflake: node lease renewed" node_id=2`,
+		`level=DEBUG msg="snowflake: id range leased" node_id=2`,
+		`level=WARN msg="snowflake: node lease lost" node_id=2`,
+		`level=INFO msg="snowflake: node lease restored" node_id=3 restored_node_id=3`,
+		`level=WARN msg="snowflake: worker frozen" node_id=3 reason=test`,
+		`level=INFO msg="snowflake: worker unfrozen"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "clock moved backwards") != 1 {
+		t.Errorf("rollback should be logged once:\n%s", out)
+	}
+}
@@ -0,0 +1,113 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Manager holds named workers, e.g. one per tenant or per id type, and
+// refuses workers whose ids could collide with an existing one.
+type Manager struct {
+	sync.RWMutex
+	workers map[string]*IdWorker
+}
+
+// ManagerStats aggregates the stats of every worker of a manager.
+type ManagerStats struct {
+	WorkerStats
+	Workers   int                    `json:"workers"`
+	PerWorker map[string]WorkerStats `json:"per_worker"`
+}
+
+// NewManager new an empty worker manager.
+func NewManager() *Manager {
+	return &Manager{workers: make(map[string]*IdWorker)}
+}
+
+// slot is the part of the id space a worker issues in.
+type slot struct {
+	layout     Layout // 含起始时间戳
+	nodeId     int64
+	districtId int64
+	tag        int64
+}
+
+// slot returns the worker's slot, read under its lock.
+func (id *IdWorker) slot() slot {
+	id.Lock()
+	defer id.Unlock()
+	return slot{layout: id.currentLayout(), nodeId: id.nodeId, districtId: id.districtId, tag: id.tag}
+}
+
+// Add registers worker under name. Two workers collide when they share
+// layout, epoch, node, district and tag, since their ids would then
+// overlap. Workers of different layouts are not compared.
+func (m *Manager) Add(name string, worker *IdWorker) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.workers[name]; ok {
+		return errors.New(fmt.Sprintf("worker %q already exists", name))
+	}
+	s := worker.slot()
+	for other, w := range m.workers {
+		if w.slot() == s {
+			return errors.New(fmt.Sprintf("worker %q collides with %q on node %d", name, other, s.nodeId))
+		}
+	}
+	m.workers[name] = worker
+	return nil
+}
+
+// Remove unregisters the worker named name.
+func (m *Manager) Remove(name string) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.workers, name)
+}
+
+// Get returns the worker named name.
+func (m *Manager) Get(name string) (*IdWorker, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	w, ok := m.workers[name]
+	return w, ok
+}
+
+// Names returns the sorted names of the registered workers.
+func (m *Manager) Names() []string {
+	m.RLock()
+	defer m.RUnlock()
+	names := make([]string, 0, len(m.workers))
+	for name := range m.workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NextId get a snowflake id from the worker named name.
+func (m *Manager) NextId(name string) (ID, error) {
+	w, ok := m.Get(name)
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("worker %q not found", name))
+	}
+	return w.NextId()
+}
+
+// Stats returns the aggregate stats of all workers.
+func (m *Manager) Stats() ManagerStats {
+	m.RLock()
+	defer m.RUnlock()
+	stats := ManagerStats{
+		Workers:   len(m.workers),
+		PerWorker: make(map[string]WorkerStats, len(m.workers)),
+	}
+	for name, w := range m.workers {
+		s := w.Stats()
+		stats.PerWorker[name] = s
+		stats.add(s)
+	}
+	return stats
+}
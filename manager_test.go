@@ -0,0 +1,60 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+	orders, _ := NewOrderIdWorker(1)
+	users, _ := NewUserIdWorker(1)
+	if err := m.Add("order", orders); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add("user", users); err != nil {
+		t.Fatal(err)
+	}
+	dup, _ := NewOrderIdWorker(1)
+	if err := m.Add("order2", dup); err == nil {
+		t.Error("node collision should be rejected")
+	}
+	if err := m.Add("order", users); err == nil {
+		t.Error("duplicate name should be rejected")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := m.NextId("order"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.NextId("missing"); err == nil {
+		t.Error("unknown worker should fail")
+	}
+	stats := m.Stats()
+	if stats.Workers != 2 || stats.Generated != 3 || stats.PerWorker["order"].Generated != 3 {
+		t.Errorf("stats: got %+v", stats)
+	}
+
+	// 同一节点, 不同布局或起始时间戳的 ID 不会重叠
+	plain, _ := NewIdWorker(1)
+	if err := m.Add("plain", plain); err != nil {
+		t.Errorf("different layout: %v", err)
+	}
+	later := DefaultLayout
+	later.Epoch += 1000
+	shifted, _ := NewIdWorker(1, WithLayout(later))
+	if err := m.Add("shifted", shifted); err != nil {
+		t.Errorf("different epoch: %v", err)
+	}
+	again, _ := NewIdWorker(1)
+	if err := m.Add("again", again); err == nil {
+		t.Error("same layout and node should be rejected")
+	}
+	m.Remove("plain")
+	m.Remove("shifted")
+
+	m.Remove("order")
+	if err := m.Add("order2", dup); err != nil {
+		t.Errorf("add after remove: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.23
+
+package snowflake
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// MergeSorted merges id streams, each in increasing order such as the ids
+// of one node, into one stream in increasing order. The timestamp takes the
+// most significant bits of an id, so the result is ordered by time, ties
+// broken by the lower fields.
+//
+// Streams are read lazily, one id ahead each; an out of order id in a
+// stream is passed through where it is met.
+func MergeSorted(seqs ...iter.Seq[ID]) iter.Seq[ID] {
+	return func(yield func(ID) bool) {
+		h := make(mergeHeap, 0, len(seqs))
+		for _, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			if f, ok := next(); ok {
+				h = append(h, mergeHead{f, next})
+			}
+		}
+		heap.Init(&h)
+		for len(h) > 0 {
+			if !yield(h[0].id) {
+				return
+			}
+			if f, ok := h[0].next(); ok {
+				h[0].id = f
+				heap.Fix(&h, 0)
+			} else {
+				heap.Pop(&h)
+			}
+		}
+	}
+}
+
+type mergeHead struct {
+	id   ID
+	next func() (ID, bool)
+}
+
+type mergeHeap []mergeHead
+
+func (h mergeHeap) Len() int            { return len(h) }
+func (h mergeHeap) Less(i, j int) bool  { return h[i].id < h[j].id }
+func (h mergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeHead)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package snowflake
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMergeSorted(t *testing.T) {
+	start := time.Now()
+	var streams [][]ID
+	var all []ID
+	for node := int64(1); node <= 3; node++ {
+		w, _ := NewIdWorker(node)
+		var ids []ID
+		for i := 0; i < 50; i++ {
+			f, _ := w.NextIdAt(start.Add(time.Duration(i*int(node)) * time.Millisecond))
+			ids = append(ids, f)
+		}
+		streams = append(streams, ids)
+		all = append(all, ids...)
+	}
+	slices.Sort(all)
+
+	got := slices.Collect(MergeSorted(slices.Values(streams[0]), slices.Values(streams[1]), slices.Values(streams[2]), slices.Values([]ID(nil))))
+	if !slices.Equal(got, all) {
+		t.Fatalf("merged %d ids out of order", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].WithLayout(DefaultLayout).Timestamp() < got[i-1].WithLayout(DefaultLayout).Timestamp() {
+			t.Fatalf("time goes backwards at %d", i)
+		}
+	}
+
+	n := 0
+	for range MergeSorted(slices.Values(streams[0]), slices.Values(streams[1])) {
+		if n++; n == 10 {
+			break
+		}
+	}
+	if n != 10 {
+		t.Errorf("break after %d ids", n)
+	}
+}
@@ -0,0 +1,42 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMicroLayout(t *testing.T) {
+	w, err := NewIdWorker(5, WithLayout(MicroLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now().UnixMicro()
+	id, _ := w.NextId()
+	after := time.Now().UnixMicro()
+	v := id.WithLayout(MicroLayout)
+	if v.Micros() < before || v.Micros() > after || v.NodeId() != 5 {
+		t.Errorf("decoded %d node %d, want between %d and %d", v.Micros(), v.NodeId(), before, after)
+	}
+	if v.Timestamp() != v.Micros()/1000 {
+		t.Errorf("Timestamp %d, want %d", v.Timestamp(), v.Micros()/1000)
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 678901000, time.UTC)
+	w, _ = NewIdWorker(5, WithLayout(MicroLayout))
+	a, _ := w.NextIdAt(at)
+	b, _ := w.NextIdAt(at.Add(time.Microsecond))
+	if got := a.WithLayout(MicroLayout).Micros(); got != at.UnixMicro() {
+		t.Errorf("NextIdAt: got %d, want %d", got, at.UnixMicro())
+	}
+	if b <= a || b.WithLayout(MicroLayout).Sequence() != 0 {
+		t.Error("the next microsecond should start a new sequence")
+	}
+
+	l, _ := w.LeaseRange(3)
+	if got := l.ID(0).WithLayout(MicroLayout).Micros(); got != l.Timestamp {
+		t.Errorf("lease id at %d, want %d", got, l.Timestamp)
+	}
+	if err := w.ScheduleCutover(time.Now().Add(time.Hour), DefaultLayout); err == nil {
+		t.Error("cutover from a microsecond layout should fail")
+	}
+}
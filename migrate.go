@@ -0,0 +1,100 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MigrationState is the state a replacement process needs to take over a
+// live worker, e.g. in a blue-green switchover, without waiting for the
+// clock to pass anything.
+type MigrationState struct {
+	NodeId        int64  `json:"node_id"`
+	DistrictId    int64  `json:"district_id"`
+	Tag           int64  `json:"tag"`
+	Layout        Layout `json:"layout"`
+	LeaseToken    string `json:"lease_token,omitempty"` // 节点租约的凭证, 由租约持有方解释
+	LastTimestamp int64  `json:"last_timestamp"`        // 已用过的最大时间戳
+	Sequence      int64  `json:"sequence"`              // 该时间戳内最后的序号
+	ExportedAt    int64  `json:"exported_at"`           // 导出时间, unix 毫秒
+}
+
+// ExportState freezes the worker for good and returns its state, so that
+// the worker issues nothing past the state exported. leaseToken is the
+// token of the node lease the caller holds, if any, handed over as is.
+func (id *IdWorker) ExportState(leaseToken string) (MigrationState, error) {
+	id.Lock()
+	defer id.Unlock()
+	if id.cutover != nil || id.epochs != nil || id.randomFill {
+		return MigrationState{}, errors.New("cannot export a worker with a pending cutover, district epochs or random fill")
+	}
+	if id.lease != nil && id.lease.lost {
+		return MigrationState{}, ErrNodeLeaseLost
+	}
+	if id.frozen == nil {
+		id.frozen = &FrozenError{Reason: "state exported for migration", Since: time.Now()}
+	}
+	return MigrationState{
+		NodeId:        id.nodeId,
+		DistrictId:    id.districtId,
+		Tag:           id.tag,
+		Layout:        id.currentLayout(),
+		LeaseToken:    leaseToken,
+		LastTimestamp: id.lastTimestamp,
+		Sequence:      id.sequence,
+		ExportedAt:    timeGen(),
+	}, nil
+}
+
+// StoppedCheck returns nil once the process a state was exported from has
+// stopped, and an error while it may still issue ids.
+type StoppedCheck func(s MigrationState) error
+
+// CoordinatorStopped checks the heartbeats of the exporting process, see
+// StartHeartbeat: it must have missed them for quiet, and its last
+// high-water mark must not be past the state exported.
+func CoordinatorStopped(coord Coordinator, quiet time.Duration) StoppedCheck {
+	return func(s MigrationState) error {
+		beat, hw, err := coord.Status(s.NodeId)
+		if err != nil {
+			return err
+		}
+		if hw > s.LastTimestamp {
+			return errors.New(fmt.Sprintf("source of node %d used timestamp %d after exporting %d", s.NodeId, hw, s.LastTimestamp))
+		}
+		if since := time.Since(beat); since < quiet {
+			return errors.New(fmt.Sprintf("source of node %d heartbeat %s ago", s.NodeId, since.Round(time.Millisecond)))
+		}
+		return nil
+	}
+}
+
+// ImportState returns a worker resuming s right after its last id, once
+// stopped confirms the source has stopped. opts apply on top of the
+// exported node, district, tag and layout; the clock of the new process
+// must not be behind the source's, or the worker reports it moved
+// backwards.
+func ImportState(s MigrationState, stopped StoppedCheck, opts ...Option) (*IdWorker, error) {
+	if stopped == nil {
+		return nil, errors.New("importing state needs a check that the source has stopped")
+	}
+	if err := stopped(s); err != nil {
+		return nil, errors.New(fmt.Sprintf("source may still be running: %v", err))
+	}
+	if max := s.Layout.MaxSequence(); s.Sequence < -1 || s.Sequence > max {
+		return nil, errors.New(fmt.Sprintf("sequence must be between -1 and %d", max))
+	}
+	if s.Tag < 0 || s.Tag > s.Layout.MaxTag() {
+		return nil, errors.New(fmt.Sprintf("tag %d does not fit in %d tag bits", s.Tag, s.Layout.TagBits))
+	}
+	base := []Option{WithDistrictId(s.DistrictId), WithLayout(s.Layout)}
+	worker, err := NewIdWorker(s.NodeId, append(base, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	worker.tag = s.Tag
+	worker.lastTimestamp = s.LastTimestamp
+	worker.sequence = s.Sequence
+	return worker, nil
+}
@@ -0,0 +1,59 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMigrateState(t *testing.T) {
+	clock := &manualClock{ms: twepoch + 1000}
+	source, _ := NewIdWorker(21, WithClock(clock), WithDistrictId(2))
+	last, _ := source.NextId()
+
+	coord := NewMemoryCoordinator()
+	coord.Heartbeat(21, clock.ms)
+	s, err := source.ExportState("token-21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.NextId(); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("source after export: got %v, want ErrFrozen", err)
+	}
+
+	if _, err := ImportState(s, CoordinatorStopped(coord, time.Hour)); err == nil {
+		t.Fatal("import should fail while the source heartbeats")
+	}
+	if _, err := ImportState(s, nil); err == nil {
+		t.Fatal("import should need a stopped check")
+	}
+
+	coord.Heartbeat(21, clock.ms)
+	target, err := ImportState(s, CoordinatorStopped(coord, 0), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := target.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next <= last || next.NodeId() != 21 || next.DistrictId() != 2 {
+		t.Errorf("imported worker: got %d after %d", next, last)
+	}
+
+	coord.Heartbeat(21, clock.ms+1)
+	if _, err := ImportState(s, CoordinatorStopped(coord, 0)); err == nil {
+		t.Error("import should fail when the source issued past the export")
+	}
+}
+
+func TestMigrateStateCompactLayout(t *testing.T) {
+	l := DefaultLayout
+	l.DistrictBits = 0
+	source, _ := NewIdWorker(4, WithDistrictId(0), WithLayout(l))
+	source.NextId()
+	s, _ := source.ExportState("")
+	if _, err := ImportState(s, func(MigrationState) error { return nil }); err != nil {
+		t.Fatalf("import into a layout without districts: %v", err)
+	}
+}
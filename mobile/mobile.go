@@ -0,0 +1,111 @@
+// Package mobile wraps the device-local EdgeWorker for gomobile, so iOS and
+// Android apps issue and read ids in exactly the backend's layout:
+//
+//	gomobile bind -target=android github.com/sakishum/go_snowflake/mobile
+//	gomobile bind -target=ios github.com/sakishum/go_snowflake/mobile
+//
+// Only types gomobile can bind are exposed: int64, string, bool and
+// pointers to structs of those.
+package mobile
+
+import (
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/decode"
+)
+
+// Generator issues ids on the device; see snowflake.EdgeWorker.
+type Generator struct {
+	worker *snowflake.EdgeWorker
+	store  *snowflake.MmapStore
+}
+
+// NewGenerator new a generator embedding deviceId as its node ID. Ids are
+// flagged uncertain before the first Sync and maxUnsyncedMillis after the
+// last. statePath names a file, in the app's private storage, keeping ids
+// unique across restarts; leave it empty to keep no state.
+func NewGenerator(deviceId int64, maxUnsyncedMillis int64, statePath string) (*Generator, error) {
+	g := &Generator{}
+	var opts []snowflake.Option
+	if statePath != "" {
+		store, err := snowflake.NewMmapStore(statePath)
+		if err != nil {
+			return nil, err
+		}
+		g.store = store
+		opts = append(opts, snowflake.WithStore(store))
+	}
+	w, err := snowflake.NewEdgeWorker(deviceId, time.Duration(maxUnsyncedMillis)*time.Millisecond, opts...)
+	if err != nil {
+		g.Close()
+		return nil, err
+	}
+	g.worker = w
+	return g, nil
+}
+
+// Sync records the server time in unix milliseconds, received just now.
+func (g *Generator) Sync(serverMillis int64) {
+	g.worker.Sync(serverMillis)
+}
+
+// Certain reports whether ids issued now are flagged as certain.
+func (g *Generator) Certain() bool {
+	return g.worker.Certain()
+}
+
+// NextId get a snowflake id.
+func (g *Generator) NextId() (int64, error) {
+	f, err := g.worker.NextId()
+	return int64(f), err
+}
+
+// NextIdString get a snowflake id as a decimal string, for JSON payloads.
+func (g *Generator) NextIdString() (string, error) {
+	f, err := g.worker.NextId()
+	if err != nil {
+		return "", err
+	}
+	return f.String(), nil
+}
+
+// Close releases the state file, if any.
+func (g *Generator) Close() error {
+	if g.store == nil {
+		return nil
+	}
+	return g.store.Close()
+}
+
+// Decoded is every field of an id.
+type Decoded struct {
+	Id         int64
+	Timestamp  int64 // unix 毫秒
+	Tag        int64
+	DistrictId int64
+	NodeId     int64
+	Sequence   int64
+	Uncertain  bool // 由时钟未同步的设备生成
+}
+
+// Decode returns the fields of id.
+func Decode(id int64) *Decoded {
+	f := snowflake.ID(id)
+	v := f.WithLayout(snowflake.DefaultLayout)
+	return &Decoded{
+		Id:         id,
+		Timestamp:  v.Timestamp(),
+		Tag:        int64(v.Tag()),
+		DistrictId: v.DistrictId(),
+		NodeId:     v.NodeId(),
+		Sequence:   v.Sequence(),
+		Uncertain:  snowflake.IsUncertain(f, snowflake.DefaultLayout),
+	}
+}
+
+// Parse decodes the decimal form of an id.
+func Parse(s string) (int64, error) {
+	f, err := decode.Parse(s)
+	return int64(f), err
+}
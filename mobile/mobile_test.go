@@ -0,0 +1,39 @@
+package mobile
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGenerator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	g, err := NewGenerator(5, 60000, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	if g.Certain() {
+		t.Error("certain before the first sync")
+	}
+	g.Sync(time.Now().UnixMilli())
+	id, err := g.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Decode(id)
+	if d.NodeId != 5 || d.Uncertain {
+		t.Errorf("got %+v", d)
+	}
+	s, err := g.NextIdString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, err := Parse(s); err != nil || next <= id {
+		t.Errorf("parse %q: got %d, %v", s, next, err)
+	}
+	if _, err := Parse(strconv.Itoa(-1)); err == nil {
+		t.Error("negative id should fail")
+	}
+}
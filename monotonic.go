@@ -0,0 +1,79 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Violation is an id that did not sort after the previous id of its
+// stream.
+type Violation struct {
+	Stream string
+	Prev   LayoutID
+	ID     LayoutID
+}
+
+// Duplicate reports whether the id repeats the previous one.
+func (v Violation) Duplicate() bool {
+	return v.ID.ID == v.Prev.ID
+}
+
+// String describes the violation with both ids decoded, which usually
+// shows the cause: two nodes sharing a stream, a clock behind the others,
+// or two workers configured with the same node.
+func (v Violation) String() string {
+	what := "went backwards"
+	if v.Duplicate() {
+		what = "repeated"
+	}
+	return fmt.Sprintf("stream %q %s: %s after %s", v.Stream, what, describe(v.ID), describe(v.Prev))
+}
+
+func describe(v LayoutID) string {
+	return fmt.Sprintf("%d (time=%s tag=%s district=%d node=%d sequence=%d)",
+		v.ID, time.UnixMilli(v.Timestamp()).UTC().Format("2006-01-02T15:04:05.000Z"),
+		v.Tag(), v.DistrictId(), v.NodeId(), v.Sequence())
+}
+
+// MonotonicChecker checks that the ids of each stream strictly increase,
+// to catch multi-worker setups that interleave ids out of order, e.g. in
+// staging.
+type MonotonicChecker struct {
+	sync.Mutex
+	layout      Layout
+	last        map[string]ID
+	violations  int64
+	onViolation func(Violation)
+}
+
+// NewMonotonicChecker new a checker decoding ids with layout and calling
+// onViolation, if not nil, for every violation.
+func NewMonotonicChecker(layout Layout, onViolation func(Violation)) *MonotonicChecker {
+	return &MonotonicChecker{layout: layout, last: make(map[string]ID), onViolation: onViolation}
+}
+
+// Observe feeds the next id of stream to the checker and reports whether
+// it was in order.
+func (m *MonotonicChecker) Observe(stream string, id ID) (Violation, bool) {
+	m.Lock()
+	defer m.Unlock()
+	prev, seen := m.last[stream]
+	m.last[stream] = id
+	if !seen || id > prev {
+		return Violation{}, true
+	}
+	v := Violation{Stream: stream, Prev: prev.WithLayout(m.layout), ID: id.WithLayout(m.layout)}
+	m.violations++
+	if m.onViolation != nil {
+		m.onViolation(v)
+	}
+	return v, false
+}
+
+// Violations returns the number of violations seen.
+func (m *MonotonicChecker) Violations() int64 {
+	m.Lock()
+	defer m.Unlock()
+	return m.violations
+}
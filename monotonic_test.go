@@ -0,0 +1,37 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonotonicChecker(t *testing.T) {
+	var got []Violation
+	m := NewMonotonicChecker(DefaultLayout, func(v Violation) { got = append(got, v) })
+	a, _ := NewIdWorker(1)
+	b, _ := NewIdWorker(2)
+	at := time.Now()
+	x, _ := b.NextIdAt(at)
+	y, _ := a.NextIdAt(at)
+
+	if _, ok := m.Observe("orders", x); !ok {
+		t.Error("first id reported")
+	}
+	if _, ok := m.Observe("users", y); !ok {
+		t.Error("streams are not independent")
+	}
+	v, ok := m.Observe("orders", y)
+	if ok || v.Duplicate() {
+		t.Fatalf("out of order id: ok %v, duplicate %v", ok, v.Duplicate())
+	}
+	if s := v.String(); !strings.Contains(s, "went backwards") || !strings.Contains(s, "node=1") || !strings.Contains(s, "node=2") {
+		t.Errorf("description: %s", s)
+	}
+	if v, ok := m.Observe("orders", y); ok || !v.Duplicate() {
+		t.Error("repeated id not reported as duplicate")
+	}
+	if m.Violations() != 2 || len(got) != 2 {
+		t.Errorf("violations: %d, callbacks %d", m.Violations(), len(got))
+	}
+}
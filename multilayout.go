@@ -0,0 +1,108 @@
+package snowflake
+
+import (
+	"errors"
+	"time"
+)
+
+// LegacyLayout is the original 39-5-9-10 layout, which DefaultLayout still
+// is; it names the untagged generation next to TaggedLayout.
+var LegacyLayout = Layout{
+	TimestampBits: 39,
+	DistrictBits:  5,
+	NodeBits:      9,
+	SequenceBits:  10,
+	Epoch:         twepoch,
+}
+
+// LayoutGeneration is a layout that issued ids during some period.
+type LayoutGeneration struct {
+	Name      string
+	Layout    Layout
+	From      time.Time // 开始使用的时间, 零值表示不限
+	Until     time.Time // 停止使用的时间, 零值表示不限
+	Districts []int64   // 使用过的区域, 为空表示不限
+}
+
+// Decoding is the best-fit decomposition of an id.
+type Decoding struct {
+	Generation LayoutGeneration
+	View       LayoutID
+	Confidence float64 // 0..1, 通过的检查所占比例
+	Ambiguous  bool    // 另一个同分的布局给出了不同的分解
+}
+
+// MultiLayoutDecoder decodes ids from tables holding several generations
+// of ids by trying every registered layout and keeping the most plausible.
+type MultiLayoutDecoder struct {
+	generations []LayoutGeneration
+	Now         func() time.Time
+}
+
+// NewMultiLayoutDecoder new a decoder trying generations in order.
+func NewMultiLayoutDecoder(generations ...LayoutGeneration) *MultiLayoutDecoder {
+	return &MultiLayoutDecoder{generations: generations, Now: time.Now}
+}
+
+// Register adds a generation.
+func (m *MultiLayoutDecoder) Register(g LayoutGeneration) {
+	m.generations = append(m.generations, g)
+}
+
+// Decode returns the best-fit decomposition of f. A layout whose timestamp
+// lands in the future is never chosen; the others are scored on whether the
+// timestamp falls in the generation's period and the district is known.
+func (m *MultiLayoutDecoder) Decode(f ID) (Decoding, error) {
+	var best Decoding
+	found := false
+	now := toMillis(m.Now())
+	for _, g := range m.generations {
+		v := f.WithLayout(g.Layout)
+		ts := v.Timestamp()
+		if ts > now || int64(f) > int64(g.Layout.MaxID()) {
+			continue
+		}
+		passed, total := 0, 0
+		if !g.From.IsZero() {
+			total++
+			if ts >= toMillis(g.From) {
+				passed++
+			}
+		}
+		if !g.Until.IsZero() {
+			total++
+			if ts < toMillis(g.Until) {
+				passed++
+			}
+		}
+		if len(g.Districts) > 0 {
+			total++
+			for _, d := range g.Districts {
+				if v.DistrictId() == d {
+					passed++
+					break
+				}
+			}
+		}
+		confidence := 1.0
+		if total > 0 {
+			confidence = float64(passed) / float64(total)
+		}
+		switch {
+		case !found || confidence > best.Confidence:
+			best = Decoding{Generation: g, View: v, Confidence: confidence}
+			found = true
+		case confidence == best.Confidence && !sameDecomposition(v, best.View):
+			best.Ambiguous = true
+		}
+	}
+	if !found {
+		return Decoding{}, errors.New("no registered layout fits the id")
+	}
+	return best, nil
+}
+
+func sameDecomposition(a, b LayoutID) bool {
+	return a.Timestamp() == b.Timestamp() && a.Tag() == b.Tag() && a.DistrictId() == b.DistrictId() &&
+		a.NodeId() == b.NodeId() && a.Sequence() == b.Sequence()
+}
@@ -0,0 +1,41 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMultiLayoutDecoder(t *testing.T) {
+	cutover := time.Now().Add(-time.Hour)
+	m := NewMultiLayoutDecoder(
+		LayoutGeneration{Name: "legacy", Layout: LegacyLayout, Until: cutover, Districts: []int64{1}},
+		LayoutGeneration{Name: "tagged", Layout: TaggedLayout, From: cutover, Districts: []int64{1}},
+	)
+
+	idworker, _ := NewUserIdWorker(6)
+	id, _ := idworker.NextId()
+	d, err := m.Decode(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Generation.Name != "tagged" || d.Confidence != 1 || d.Ambiguous {
+		t.Errorf("tagged id: got %s %.2f %v", d.Generation.Name, d.Confidence, d.Ambiguous)
+	}
+	if d.View.Tag() != TagUser || d.View.NodeId() != 6 {
+		t.Errorf("tagged id decoded as %+v", d.View)
+	}
+
+	// 旧版 ID: 区域 1, 无标签, 两种布局分解一致
+	old := ID(((toMillis(cutover.Add(-time.Hour)) - twepoch) << 24) | 1<<19 | 6<<10)
+	d, err = m.Decode(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Generation.Name != "legacy" || d.View.DistrictId() != 1 || d.View.NodeId() != 6 {
+		t.Errorf("legacy id: got %s %+v", d.Generation.Name, d.View)
+	}
+
+	if _, err := NewMultiLayoutDecoder().Decode(id); err == nil {
+		t.Error("no layouts should fail")
+	}
+}
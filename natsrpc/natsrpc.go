@@ -0,0 +1,123 @@
+// Package natsrpc serves a snowflake IdWorker over NATS request/reply, so
+// services built on NATS can get ids without a gRPC or HTTP dependency.
+//
+//	snowflake.next   body empty or a count n; replies n newline separated decimal ids
+//	snowflake.decode body a decimal id; replies its components as JSON
+//
+// A failed snowflake.next replies "-ERR " followed by the error; a failed
+// snowflake.decode replies JSON with the error field set.
+package natsrpc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+const (
+	NextSubject   = "snowflake.next"
+	DecodeSubject = "snowflake.decode"
+	QueueGroup    = "snowflake" // 多个 responder 组成队列, 由 NATS 负载均衡
+)
+
+// Decoded is the reply of snowflake.decode.
+type Decoded struct {
+	ID         string `json:"id"`
+	Time       int64  `json:"time"`
+	DistrictId int64  `json:"district_id"`
+	NodeId     int64  `json:"node_id"`
+	Tag        string `json:"tag"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Responder answers id requests with its worker.
+type Responder struct {
+	worker *snowflake.IdWorker
+	subs   []*nats.Subscription
+}
+
+// NewResponder new a responder wrapping worker.
+func NewResponder(worker *snowflake.IdWorker) *Responder {
+	return &Responder{worker: worker}
+}
+
+// Subscribe registers the responder on nc under the queue group.
+func (r *Responder) Subscribe(nc *nats.Conn) error {
+	next, err := nc.QueueSubscribe(NextSubject, QueueGroup, r.handleNext)
+	if err != nil {
+		return err
+	}
+	decode, err := nc.QueueSubscribe(DecodeSubject, QueueGroup, r.handleDecode)
+	if err != nil {
+		next.Unsubscribe()
+		return err
+	}
+	r.subs = append(r.subs, next, decode)
+	return nil
+}
+
+// Unsubscribe removes the responder's subscriptions.
+func (r *Responder) Unsubscribe() error {
+	var first error
+	for _, sub := range r.subs {
+		if err := sub.Unsubscribe(); err != nil && first == nil {
+			first = err
+		}
+	}
+	r.subs = nil
+	return first
+}
+
+func (r *Responder) handleNext(msg *nats.Msg) {
+	msg.Respond(r.next(msg.Data))
+}
+
+func (r *Responder) handleDecode(msg *nats.Msg) {
+	msg.Respond(r.decode(msg.Data))
+}
+
+// next builds the reply to a snowflake.next request.
+func (r *Responder) next(data []byte) []byte {
+	num := 1
+	if body := strings.TrimSpace(string(data)); body != "" {
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return []byte("-ERR " + err.Error())
+		}
+		num = n
+	}
+	ids, err := r.worker.NextIds(num)
+	if err != nil {
+		return []byte("-ERR " + err.Error())
+	}
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// decode builds the reply to a snowflake.decode request, decoding the id
+// with the worker's layout.
+func (r *Responder) decode(data []byte) []byte {
+	var d Decoded
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		d.Error = err.Error()
+	} else {
+		id := snowflake.ID(n)
+		v := id.WithLayout(r.worker.Layout())
+		d = Decoded{
+			ID:         id.String(),
+			Time:       v.Time(),
+			DistrictId: v.DistrictId(),
+			NodeId:     v.NodeId(),
+			Tag:        v.Tag().String(),
+		}
+	}
+	b, _ := json.Marshal(d)
+	return b
+}
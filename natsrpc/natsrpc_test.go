@@ -0,0 +1,67 @@
+package natsrpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestNext(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(1)
+	r := NewResponder(worker)
+	if got := strings.Split(string(r.next(nil)), "\n"); len(got) != 1 {
+		t.Errorf("empty body: got %q", got)
+	}
+	got := strings.Split(string(r.next([]byte(" 3\n"))), "\n")
+	if len(got) != 3 {
+		t.Fatalf("n=3: got %q", got)
+	}
+	for _, s := range got {
+		if _, err := snowflake.ParseString(s); err != nil {
+			t.Errorf("id %q: %v", s, err)
+		}
+	}
+}
+
+func TestNextError(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(1)
+	r := NewResponder(worker)
+	for _, body := range []string{"abc", "-1"} {
+		if got := string(r.next([]byte(body))); !strings.HasPrefix(got, "-ERR ") {
+			t.Errorf("%q: got %q", body, got)
+		}
+	}
+	worker.Freeze("test")
+	if got := string(r.next(nil)); !strings.HasPrefix(got, "-ERR ") {
+		t.Errorf("frozen: got %q", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(7)
+	f, _ := worker.NextId()
+	r := NewResponder(worker)
+	var d Decoded
+	if err := json.Unmarshal(r.decode([]byte(f.String())), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.ID != f.String() || d.NodeId != 7 || d.Time != f.Time() || d.Error != "" {
+		t.Errorf("got %+v", d)
+	}
+	tagged, _ := snowflake.NewOrderIdWorker(7)
+	f, _ = tagged.NextId()
+	if err := json.Unmarshal(NewResponder(tagged).decode([]byte(f.String())), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Tag != "order" || d.NodeId != 7 || d.Time != f.WithLayout(snowflake.TaggedLayout).Time() {
+		t.Errorf("tagged: got %+v", d)
+	}
+	if err := json.Unmarshal(r.decode([]byte("abc")), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Error == "" || d.ID != "" {
+		t.Errorf("bad id: got %+v", d)
+	}
+}
@@ -0,0 +1,111 @@
+package snowflake
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// NodeOverrides pins the node ID of some IPv6 addresses, to settle the
+// conflicts of NodeIdFromIPv6. Keys are addresses in canonical form.
+type NodeOverrides map[string]int64
+
+// ReadNodeOverrides reads lines of "address node"; blank lines and lines
+// starting with # are skipped.
+func ReadNodeOverrides(r io.Reader) (NodeOverrides, error) {
+	o := make(NodeOverrides)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		if len(f) != 2 {
+			return nil, errors.New(fmt.Sprintf("node overrides: line %d: want \"address node\"", line))
+		}
+		ip := net.ParseIP(f[0])
+		n, err := strconv.ParseInt(f[1], 10, 64)
+		if ip == nil || err != nil || n < 0 {
+			return nil, errors.New(fmt.Sprintf("node overrides: line %d: invalid address or node", line))
+		}
+		o[ip.String()] = n
+	}
+	return o, sc.Err()
+}
+
+// NodeIdFromIPv6 derives a node ID in layout l from ip, for IPv6-only
+// hosts where the usual "last bits of the IPv4 address" trick does not
+// apply. An address in overrides gets its pinned node. Any other address
+// is hashed, all 128 bits with FNV-1a, into the node space, skipping the
+// nodes pinned in overrides, so an override never collides with a hashed
+// host.
+//
+// Hashing cannot rule out collisions: with n hosts and N = MaxNodeId+1
+// nodes, two hosts share a node with probability about 1-exp(-n(n-1)/2N).
+// For the 512 nodes of the default layout that is 8% for 10 hosts, 50% for
+// 27 and 98% for 64. Check fleets for duplicates at deploy time and pin
+// the losers in overrides; beyond a few dozen hosts, use a NodeAllocator.
+func NodeIdFromIPv6(ip net.IP, l Layout, overrides NodeOverrides) (int64, error) {
+	if ip.To4() != nil || len(ip) != net.IPv6len {
+		return 0, errors.New(fmt.Sprintf("%s is not an IPv6 address", ip))
+	}
+	size := l.MaxNodeId() + 1
+	pinned := make(map[int64]bool, len(overrides))
+	for addr, n := range overrides {
+		if n > l.MaxNodeId() {
+			return 0, errors.New(fmt.Sprintf("override node %d of %s does not fit in %d node bits", n, addr, l.NodeBits))
+		}
+		pinned[n] = true
+	}
+	if n, ok := overrides[ip.String()]; ok {
+		return n, nil
+	}
+	if int64(len(pinned)) >= size {
+		return 0, errors.New("every node ID is pinned by an override")
+	}
+	h := fnv.New64a()
+	h.Write(ip)
+	n := int64(h.Sum64() % uint64(size))
+	for pinned[n] {
+		n = (n + 1) % size
+	}
+	return n, nil
+}
+
+// LocalIPv6NodeId derives the node ID of this host with NodeIdFromIPv6 from
+// its global unicast IPv6 addresses. With several, the lowest is used, so
+// the choice does not depend on interface order; hosts with temporary
+// (privacy) addresses should pin their node in overrides instead.
+func LocalIPv6NodeId(l Layout, overrides NodeOverrides) (int64, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return 0, err
+	}
+	ip := lowestGlobalIPv6(addrs)
+	if ip == nil {
+		return 0, errors.New("no global unicast IPv6 address")
+	}
+	return NodeIdFromIPv6(ip, l, overrides)
+}
+
+func lowestGlobalIPv6(addrs []net.Addr) net.IP {
+	var best net.IP
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.To4() != nil || !ipnet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if best == nil || bytes.Compare(ipnet.IP, best) < 0 {
+			best = ipnet.IP
+		}
+	}
+	return best
+}
@@ -0,0 +1,51 @@
+package snowflake
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNodeIdFromIPv6(t *testing.T) {
+	ip := net.ParseIP("2001:db8::1")
+	n, err := NodeIdFromIPv6(ip, DefaultLayout, nil)
+	if err != nil || n < 0 || n > maxNodeId {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if again, _ := NodeIdFromIPv6(net.ParseIP("2001:db8:0::1"), DefaultLayout, nil); again != n {
+		t.Errorf("same address: got %d, want %d", again, n)
+	}
+	if _, err := NodeIdFromIPv6(net.ParseIP("10.0.0.1"), DefaultLayout, nil); err == nil {
+		t.Error("IPv4 address should fail")
+	}
+
+	o, err := ReadNodeOverrides(strings.NewReader("# pinned\n2001:db8::2 7\n2001:db8::3 " + strconv.FormatInt(n, 10) + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := NodeIdFromIPv6(net.ParseIP("2001:db8::2"), DefaultLayout, o); got != 7 {
+		t.Errorf("override: got %d, want 7", got)
+	}
+	if got, _ := NodeIdFromIPv6(ip, DefaultLayout, o); got == n {
+		t.Errorf("hashed node %d is pinned to another host and should be skipped", n)
+	}
+	small := DefaultLayout
+	small.NodeBits = 2
+	if _, err := NodeIdFromIPv6(ip, small, o); err == nil {
+		t.Error("override outside the layout should fail")
+	}
+	if _, err := ReadNodeOverrides(strings.NewReader("not-an-ip 3\n")); err == nil {
+		t.Error("invalid address should fail")
+	}
+
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("fe80::1")},
+		&net.IPNet{IP: net.ParseIP("2001:db8::9")},
+		&net.IPNet{IP: net.ParseIP("192.0.2.1")},
+		&net.IPNet{IP: net.ParseIP("2001:db8::4")},
+	}
+	if got := lowestGlobalIPv6(addrs); !got.Equal(net.ParseIP("2001:db8::4")) {
+		t.Errorf("lowest global address: got %s", got)
+	}
+}
@@ -0,0 +1,52 @@
+package snowflake
+
+import (
+	"database/sql"
+	"database/sql/driver"
+)
+
+// NullID is an ID that may be NULL, like sql.NullInt64, for optional
+// foreign keys. It marshals to JSON null when not Valid.
+type NullID struct {
+	ID    ID
+	Valid bool // ID 非 NULL 时为 true
+}
+
+// Scan implements sql.Scanner.
+func (n *NullID) Scan(value interface{}) error {
+	var v sql.NullInt64
+	if err := v.Scan(value); err != nil {
+		return err
+	}
+	n.ID, n.Valid = ID(v.Int64), v.Valid
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n NullID) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return int64(n.ID), nil
+}
+
+// MarshalJSON encodes n like its ID, or as null.
+func (n NullID) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.ID.MarshalJSON()
+}
+
+// UnmarshalJSON accepts what ID does, and null.
+func (n *NullID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullID{}
+		return nil
+	}
+	if err := n.ID.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,42 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNullID(t *testing.T) {
+	var n NullID
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("scan NULL: %+v, %v", n, err)
+	}
+	if err := n.Scan(int64(42)); err != nil || !n.Valid || n.ID != 42 {
+		t.Errorf("scan 42: %+v, %v", n, err)
+	}
+	if err := n.Scan([]byte("43")); err != nil || n.ID != 43 {
+		t.Errorf("scan bytes: %+v, %v", n, err)
+	}
+	if v, _ := (NullID{}).Value(); v != nil {
+		t.Errorf("value of NULL: %v", v)
+	}
+	if v, _ := (NullID{ID: 7, Valid: true}).Value(); v != int64(7) {
+		t.Errorf("value: %v", v)
+	}
+
+	var s struct {
+		Parent NullID `json:"parent"`
+		Owner  NullID `json:"owner"`
+	}
+	s.Owner = NullID{ID: 9, Valid: true}
+	b, _ := json.Marshal(s)
+	if string(b) != `{"parent":null,"owner":9}` {
+		t.Errorf("marshal: %s", b)
+	}
+	s.Parent = NullID{ID: 1, Valid: true}
+	if err := json.Unmarshal([]byte(`{"parent":null,"owner":"10"}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Parent.Valid || s.Owner != (NullID{ID: 10, Valid: true}) {
+		t.Errorf("unmarshal: %+v", s)
+	}
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// EpochOffset derives from key the secret offset WithEpochOffset adds to
+// timestamps, in milliseconds between 0 and max.
+func EpochOffset(key []byte, max time.Duration) int64 {
+	n := max.Milliseconds()
+	if n <= 0 {
+		return 0
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("snowflake epoch offset"))
+	return int64(binary.BigEndian.Uint64(mac.Sum(nil)) % uint64(n))
+}
+
+// WithEpochOffset makes the worker pack every timestamp a secret offset
+// later, derived from key by EpochOffset, so outsiders decoding its ids
+// see creation times shifted by up to max. Ids still sort by time, and
+// workers sharing key and max stay ordered among themselves. Decode the
+// true time with the worker's Layout, or elsewhere with Layout.Deobfuscate.
+// The offset also applies to layouts set later, by WithLayout or a cutover.
+func WithEpochOffset(key []byte, max time.Duration) Option {
+	return func(id *IdWorker) error {
+		if len(key) == 0 || max < time.Millisecond {
+			return errors.New("epoch offset needs a key and a maximum of at least 1ms")
+		}
+		id.offset = EpochOffset(key, max)
+		return nil
+	}
+}
+
+// Deobfuscate returns the layout decoding the true times of ids issued
+// with l and WithEpochOffset(key, max).
+func (l Layout) Deobfuscate(key []byte, max time.Duration) Layout {
+	l.Epoch -= EpochOffset(key, max)
+	return l
+}
@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEpochOffset(t *testing.T) {
+	key := []byte("secret")
+	off := EpochOffset(key, 30*24*time.Hour)
+	if off < 0 || off >= (30*24*time.Hour).Milliseconds() || off == EpochOffset([]byte("other"), 30*24*time.Hour) {
+		t.Fatalf("offset %d", off)
+	}
+	plain, _ := NewIdWorker(1)
+	idworker, err := NewIdWorker(1, WithEpochOffset(key, 30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := timeGen()
+	a, _ := idworker.NextId()
+	b, _ := idworker.NextId()
+	after := timeGen()
+	if b <= a {
+		t.Errorf("ids not increasing: %d after %d", b, a)
+	}
+	public := a.WithLayout(DefaultLayout).Timestamp()
+	if public < before+off || public > after+off {
+		t.Errorf("public time %d, want %d shifted by %d", public, before, off)
+	}
+	for _, l := range []Layout{idworker.Layout(), DefaultLayout.Deobfuscate(key, 30*24*time.Hour)} {
+		if ts := a.WithLayout(l).Timestamp(); ts < before || ts > after {
+			t.Errorf("true time %d, want between %d and %d", ts, before, after)
+		}
+	}
+	if p, _ := plain.NextId(); p.WithLayout(DefaultLayout).Timestamp() > public {
+		t.Error("offset should move timestamps later")
+	}
+	if _, err := NewIdWorker(1, WithEpochOffset(nil, time.Hour)); err == nil {
+		t.Error("empty key should fail")
+	}
+}
+
+func TestEpochOffsetKept(t *testing.T) {
+	key := []byte("secret")
+	off := EpochOffset(key, time.Hour)
+	l := DefaultLayout
+	l.Epoch = twepoch + 1000
+	idworker, err := NewIdWorker(1, WithEpochOffset(key, time.Hour), WithLayout(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := idworker.Layout().Epoch; got != l.Epoch-off {
+		t.Errorf("WithLayout after the offset: epoch %d, want %d", got, l.Epoch-off)
+	}
+
+	clock := &manualClock{ms: timeGen()}
+	idworker, _ = NewIdWorker(1, WithClock(clock), WithEpochOffset(key, time.Hour))
+	next := DefaultLayout
+	next.SequenceBits--
+	next.NodeBits++
+	if err := idworker.ScheduleCutover(time.UnixMilli(clock.ms+10), next); err != nil {
+		t.Fatal(err)
+	}
+	clock.ms += 10
+	f, _ := idworker.NextId()
+	if ts := f.WithLayout(next.Deobfuscate(key, time.Hour)).Timestamp(); ts != clock.ms {
+		t.Errorf("after cutover: true time %d, want %d", ts, clock.ms)
+	}
+	if got := idworker.Layout().Epoch; got != next.Epoch-off {
+		t.Errorf("after cutover: epoch %d, want %d", got, next.Epoch-off)
+	}
+}
@@ -0,0 +1,41 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseString decodes the decimal form of an id, accepting any
+// non-negative int64; see ParseStrict to check it is a plausible id.
+func ParseString(s string) (ID, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New(fmt.Sprintf("invalid snowflake id %q", s))
+	}
+	return ID(n), nil
+}
+
+// ParseStrict decodes the decimal form of an id of layout l and checks it
+// could have been issued: no bits are set above the layout's fields and the
+// timestamp lies after the epoch and before now plus maxSkew. It catches ids
+// pasted from another scheme, such as UUID-derived or auto-increment
+// numbers, which ParseString accepts.
+func ParseStrict(s string, l Layout, maxSkew time.Duration) (ID, error) {
+	f, err := ParseString(s)
+	if err != nil {
+		return 0, err
+	}
+	if f > l.MaxID() {
+		return 0, errors.New(fmt.Sprintf("snowflake id %s has bits above the %d bits of layout %s", s, l.TimestampBits+l.timestampShift(), l))
+	}
+	if int64(f)>>l.timestampShift() == 0 {
+		return 0, errors.New(fmt.Sprintf("snowflake id %s has no timestamp, it is not from layout %s", s, l))
+	}
+	ts := f.WithLayout(l).Timestamp()
+	if limit := toMillis(time.Now().Add(maxSkew)); ts > limit {
+		return 0, errors.New(fmt.Sprintf("snowflake id %s is from %s, in the future", s, time.UnixMilli(ts).UTC().Format(time.RFC3339)))
+	}
+	return f, nil
+}
@@ -0,0 +1,36 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStrict(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	id, _ := idworker.NextId()
+	if got, err := ParseStrict(id.String(), DefaultLayout, time.Second); err != nil || got != id {
+		t.Errorf("fresh id: got %d, %v", got, err)
+	}
+	if got, err := ParseString("12345"); err != nil || got != 12345 {
+		t.Errorf("ParseString: got %d, %v", got, err)
+	}
+
+	future := ID((toMillis(time.Now().Add(time.Hour)) - twepoch) << timestampLeftShift)
+	narrow := Layout{TimestampBits: 30, NodeBits: 5, SequenceBits: 10, Epoch: twepoch}
+	for s, want := range map[string]string{
+		"12345":                         "no timestamp",
+		future.String():                 "in the future",
+		"-5":                            "invalid",
+		"x":                             "invalid",
+		ID(narrow.MaxID() + 1).String(): "bits above",
+	} {
+		l := DefaultLayout
+		if strings.HasPrefix(want, "bits") {
+			l = narrow
+		}
+		if _, err := ParseStrict(s, l, time.Second); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: want an error mentioning %q, got %v", s, want, err)
+		}
+	}
+}
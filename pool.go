@@ -0,0 +1,184 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NodeAllocator hands out node IDs that no other generator is using.
+type NodeAllocator interface {
+	Acquire() (int64, error)
+	Release(nodeId int64) error
+}
+
+// WorkerPool spreads NextId over several workers, each with its own node
+// ID, to issue more ids per millisecond than a single worker can.
+type WorkerPool struct {
+	mu      sync.RWMutex
+	alloc   NodeAllocator
+	opts    []Option
+	workers []*IdWorker
+	next    uint64
+
+	exhausted int64     // 上次检查时的序号用尽总数
+	busyAt    time.Time // 最近一次出现序号用尽的时间
+}
+
+// NewWorkerPool new a pool holding a single worker on a node acquired from
+// alloc. opts are applied to every worker of the pool.
+func NewWorkerPool(alloc NodeAllocator, opts ...Option) (*WorkerPool, error) {
+	p := &WorkerPool{alloc: alloc, opts: opts, busyAt: time.Now()}
+	if err := p.Grow(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NextId get a snowflake id from the next worker of the pool.
+func (p *WorkerPool) NextId() (ID, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	w := p.workers[atomic.AddUint64(&p.next, 1)%uint64(len(p.workers))]
+	return w.NextId()
+}
+
+// Size returns the number of workers, and so of node IDs, in the pool.
+func (p *WorkerPool) Size() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.workers)
+}
+
+// Grow adds a worker on a newly acquired node ID.
+func (p *WorkerPool) Grow() error {
+	nodeId, err := p.alloc.Acquire()
+	if err != nil {
+		return err
+	}
+	w, err := NewIdWorker(nodeId, p.opts...)
+	if err != nil {
+		p.alloc.Release(nodeId)
+		return err
+	}
+	p.mu.Lock()
+	p.workers = append(p.workers, w)
+	p.mu.Unlock()
+	return nil
+}
+
+// Shrink removes the newest worker and releases its node ID. The last
+// worker is never removed.
+func (p *WorkerPool) Shrink() error {
+	p.mu.Lock()
+	if len(p.workers) <= 1 {
+		p.mu.Unlock()
+		return errors.New("worker pool cannot shrink below one worker")
+	}
+	w := p.workers[len(p.workers)-1]
+	p.workers = p.workers[:len(p.workers)-1]
+	p.mu.Unlock()
+
+	// 归还前越过该节点用过的最后一毫秒, 下一个拿到此节点的生成器不会重复
+	w.Lock()
+	waitPast(w.clock, w.lastTimestamp)
+	w.Unlock()
+	return p.alloc.Release(w.nodeId)
+}
+
+// Stats returns the summed counters of the workers of the pool.
+func (p *WorkerPool) Stats() WorkerStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var s WorkerStats
+	for _, w := range p.workers {
+		s.add(w.Stats())
+	}
+	return s
+}
+
+// AutoscalePolicy says when a pool grows and shrinks.
+type AutoscalePolicy struct {
+	Interval  time.Duration // 检查间隔
+	GrowAt    int64         // 一个间隔内序号用尽次数达到此值时扩容
+	IdleAfter time.Duration // 多久没有序号用尽时缩容
+	Max       int           // 最多的 worker 数, 0 表示不限
+}
+
+// Autoscale checks the pool every policy.Interval until ctx is done. It
+// acquires another node ID when sequence exhaustion within an interval
+// reaches GrowAt, and releases one when there has been no exhaustion for
+// IdleAfter.
+func (p *WorkerPool) Autoscale(ctx context.Context, policy AutoscalePolicy) error {
+	if policy.Interval <= 0 || policy.GrowAt <= 0 {
+		return errors.New(fmt.Sprintf("autoscale needs a positive interval and threshold, got %s and %d", policy.Interval, policy.GrowAt))
+	}
+	p.exhausted = p.Stats().Exhausted
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				p.scale(policy, now)
+			}
+		}
+	}()
+	return nil
+}
+
+// scale makes one autoscaling decision. Workers removed by Shrink take
+// their counters with them, so the baseline is re-read after every change.
+func (p *WorkerPool) scale(policy AutoscalePolicy, now time.Time) {
+	delta := p.Stats().Exhausted - p.exhausted
+	if delta > 0 {
+		p.busyAt = now
+	}
+	switch {
+	case delta >= policy.GrowAt && (policy.Max == 0 || p.Size() < policy.Max):
+		p.Grow()
+	case delta == 0 && policy.IdleAfter > 0 && now.Sub(p.busyAt) >= policy.IdleAfter && p.Size() > 1:
+		p.Shrink()
+		p.busyAt = now
+	}
+	p.exhausted = p.Stats().Exhausted
+}
+
+// MemoryNodeAllocator hands out node IDs from a fixed range, for tests and
+// for pools sharing a process.
+type MemoryNodeAllocator struct {
+	sync.Mutex
+	free []int64
+}
+
+// NewMemoryNodeAllocator new an allocator of the node IDs from first to last.
+func NewMemoryNodeAllocator(first, last int64) *MemoryNodeAllocator {
+	a := &MemoryNodeAllocator{}
+	for n := last; n >= first; n-- {
+		a.free = append(a.free, n)
+	}
+	return a
+}
+
+func (a *MemoryNodeAllocator) Acquire() (int64, error) {
+	a.Lock()
+	defer a.Unlock()
+	if len(a.free) == 0 {
+		return 0, errors.New("no free node id")
+	}
+	n := a.free[len(a.free)-1]
+	a.free = a.free[:len(a.free)-1]
+	return n, nil
+}
+
+func (a *MemoryNodeAllocator) Release(nodeId int64) error {
+	a.Lock()
+	defer a.Unlock()
+	a.free = append(a.free, nodeId)
+	return nil
+}
@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	alloc := NewMemoryNodeAllocator(1, 2)
+	p, err := NewWorkerPool(alloc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Grow(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Grow(); err == nil {
+		t.Error("grow past the allocator range should fail")
+	}
+	seen := make(map[ID]bool)
+	nodes := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		id, err := p.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+		nodes[id.NodeId()] = true
+	}
+	if len(nodes) != 2 {
+		t.Errorf("ids came from %d nodes, want 2", len(nodes))
+	}
+	if err := p.Shrink(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Shrink(); err == nil {
+		t.Error("shrinking the last worker should fail")
+	}
+}
+
+func TestWorkerPoolScale(t *testing.T) {
+	p, _ := NewWorkerPool(NewMemoryNodeAllocator(0, 9))
+	policy := AutoscalePolicy{Interval: time.Second, GrowAt: 5, IdleAfter: time.Minute, Max: 2}
+	now := time.Now()
+
+	p.workers[0].stats.Exhausted += 5
+	p.scale(policy, now)
+	if p.Size() != 2 {
+		t.Fatalf("size after busy interval: got %d, want 2", p.Size())
+	}
+	p.workers[0].stats.Exhausted += 5
+	p.scale(policy, now.Add(time.Second))
+	if p.Size() != 2 {
+		t.Errorf("size past Max: got %d, want 2", p.Size())
+	}
+	p.scale(policy, now.Add(30*time.Second))
+	if p.Size() != 2 {
+		t.Errorf("shrunk before IdleAfter")
+	}
+	p.scale(policy, now.Add(2*time.Minute))
+	if p.Size() != 1 {
+		t.Errorf("size after idle: got %d, want 1", p.Size())
+	}
+}
@@ -0,0 +1,42 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Batch is a pooled slice of ids returned by AcquireBatch.
+type Batch struct {
+	IDs []ID
+}
+
+var batchPool = sync.Pool{
+	New: func() interface{} {
+		return &Batch{IDs: make([]ID, 0, maxNextIdsNum)}
+	},
+}
+
+// AcquireBatch get num snowflake ids like NextIds, in a batch taken from a
+// pool instead of a new slice. Hand it back with ReleaseBatch once done, so
+// steady-state batch consumers allocate nothing. On a *BatchError the
+// batch holds the ids issued before the failure and must be released too.
+func (id *IdWorker) AcquireBatch(num int) (*Batch, error) {
+	if num > maxNextIdsNum || num < 0 {
+		return nil, errors.New(fmt.Sprintf("AcquireBatch num: %d error", num))
+	}
+	b := batchPool.Get().(*Batch)
+	b.IDs = b.IDs[:num]
+	if n, err := id.fill(b.IDs); err != nil {
+		b.IDs = b.IDs[:n]
+		return b, err
+	}
+	return b, nil
+}
+
+// ReleaseBatch returns b to the pool. Neither b nor its IDs may be used
+// afterwards.
+func ReleaseBatch(b *Batch) {
+	b.IDs = b.IDs[:0]
+	batchPool.Put(b)
+}
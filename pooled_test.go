@@ -0,0 +1,53 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestAcquireBatch(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	seen := make(map[ID]bool)
+	for i := 0; i < 10; i++ {
+		b, err := idworker.AcquireBatch(maxNextIdsNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b.IDs) != maxNextIdsNum {
+			t.Fatalf("got %d ids", len(b.IDs))
+		}
+		for _, id := range b.IDs {
+			if seen[id] {
+				t.Fatalf("duplicate id %d", id)
+			}
+			seen[id] = true
+		}
+		ReleaseBatch(b)
+	}
+	if _, err := idworker.AcquireBatch(maxNextIdsNum + 1); err == nil {
+		t.Error("too many ids should fail")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		b, _ := idworker.AcquireBatch(10)
+		ReleaseBatch(b)
+	})
+	if allocs > 0.1 {
+		t.Errorf("AcquireBatch allocates %.1f times per call", allocs)
+	}
+}
+
+func BenchmarkNextIds(b *testing.B) {
+	idworker, _ := NewIdWorker(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		idworker.NextIds(maxNextIdsNum)
+	}
+}
+
+func BenchmarkAcquireBatch(b *testing.B) {
+	idworker, _ := NewIdWorker(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		batch, _ := idworker.AcquireBatch(maxNextIdsNum)
+		ReleaseBatch(batch)
+	}
+}
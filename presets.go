@@ -0,0 +1,66 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LayoutPresets are the layouts ParseLayout knows by name.
+var LayoutPresets = map[string]Layout{
+	"default": DefaultLayout,
+	"legacy":  LegacyLayout,
+	"tagged":  TaggedLayout,
+	"micro":   MicroLayout,
+}
+
+// ParseLayout parses a layout from a config file or a flag: either the name
+// of a preset or the bit widths in the form of Layout.String,
+// "timestamp-tag-district-node-sequence", or without the tag as
+// "timestamp-district-node-sequence", e.g. "39-5-9-10". Parsed widths use
+// the default epoch.
+func ParseLayout(s string) (Layout, error) {
+	if l, ok := LayoutPresets[s]; ok {
+		return l, nil
+	}
+	segments := strings.Split(s, "-")
+	names := []string{"timestamp", "tag", "district", "node", "sequence"}
+	switch len(segments) {
+	case 5:
+	case 4:
+		names = []string{"timestamp", "district", "node", "sequence"}
+	default:
+		return Layout{}, errors.New(fmt.Sprintf("layout %q: want a preset (%s) or 4 or 5 bit widths separated by '-'", s, presetNames()))
+	}
+	bits := make(map[string]uint)
+	for i, seg := range segments {
+		n, err := strconv.ParseUint(seg, 10, 8)
+		if err != nil || n > 63 {
+			return Layout{}, errors.New(fmt.Sprintf("layout %q: segment %d (%s) %q is not a bit width between 0 and 63", s, i+1, names[i], seg))
+		}
+		bits[names[i]] = uint(n)
+	}
+	l := Layout{
+		TimestampBits: bits["timestamp"],
+		TagBits:       bits["tag"],
+		DistrictBits:  bits["district"],
+		NodeBits:      bits["node"],
+		SequenceBits:  bits["sequence"],
+		Epoch:         twepoch,
+	}
+	if err := l.Validate(); err != nil {
+		return Layout{}, errors.New(fmt.Sprintf("layout %q: %v", s, err))
+	}
+	return l, nil
+}
+
+func presetNames() string {
+	names := make([]string, 0, len(LayoutPresets))
+	for name := range LayoutPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
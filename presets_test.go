@@ -0,0 +1,33 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLayout(t *testing.T) {
+	for name, want := range LayoutPresets {
+		if l, err := ParseLayout(name); err != nil || l != want {
+			t.Errorf("preset %s: got %+v, %v", name, l, err)
+		}
+	}
+	if l, err := ParseLayout("39-5-9-10"); err != nil || l != LegacyLayout {
+		t.Errorf("39-5-9-10: got %+v, %v", l, err)
+	}
+	if l, err := ParseLayout(DefaultLayout.String()); err != nil || l != DefaultLayout {
+		t.Errorf("%s: got %+v, %v", DefaultLayout, l, err)
+	}
+
+	for s, want := range map[string]string{
+		"39-5-x-10":   "segment 3 (node)",
+		"39-2-3-9-99": "segment 5 (sequence)",
+		"39-5-9":      "4 or 5 bit widths",
+		"40-5-9-10":   "64 bits",
+		"39-5-9-0":    "sequence bits",
+	} {
+		_, err := ParseLayout(s)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: want an error mentioning %q, got %v", s, want, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+)
+
+// WithRandomFill makes the worker fill the node and sequence bits with
+// crypto-random values instead of a configured node ID, for serverless
+// functions that cannot coordinate node IDs. Every new millisecond draws a
+// random node and a random first sequence, and later ids of the same
+// millisecond count up from it, so one worker never repeats an id.
+//
+// Two workers are not guaranteed distinct. With the default layout, two
+// workers issuing k1 and k2 ids within the same millisecond collide with
+// probability about (k1+k2-1) / 2^19: 1 in 524288 when each issues one id.
+// Across n workers all active in one millisecond it grows to about
+// n*(n-1)/2 times that. The time, district and tag bits are kept as usual.
+func WithRandomFill() Option {
+	return func(id *IdWorker) error {
+		id.randomFill = true
+		id.sequencer = &cryptoStartSequencer{}
+		return nil
+	}
+}
+
+// cryptoStartSequencer is RandomStartSequencer drawing from crypto/rand.
+type cryptoStartSequencer struct {
+	RandomStartSequencer
+}
+
+func (c *cryptoStartSequencer) Start(max int64) int64 {
+	c.start = cryptoInt63() & max
+	return c.start
+}
+
+// cryptoInt63 returns a non-negative crypto-random int64.
+func cryptoInt63() int64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
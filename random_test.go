@@ -0,0 +1,31 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestRandomFill(t *testing.T) {
+	w, err := NewIdWorker(0, WithRandomFill())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[ID]bool)
+	nodes := make(map[int64]bool)
+	for i := 0; i < 5000; i++ {
+		id, err := w.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+		nodes[id.NodeId()] = true
+		if id.DistrictId() != 1 {
+			t.Fatalf("district: got %d", id.DistrictId())
+		}
+	}
+	if len(nodes) < 2 {
+		t.Error("node bits were not randomised")
+	}
+}
@@ -0,0 +1,12 @@
+package snowflake
+
+import (
+	"time"
+)
+
+// Redacted returns f for logs with everything but its time removed, e.g.
+// "2024-05-01T12:00:00.123Z/redacted": it shows when the entity was created
+// without exposing an identifier that could be used to look it up.
+func (f ID) Redacted() string {
+	return time.UnixMilli(f.timestamp()).UTC().Format("2006-01-02T15:04:05.000Z") + "/redacted"
+}
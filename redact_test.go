@@ -0,0 +1,21 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedacted(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 123e6, time.UTC)
+	a, _ := NewIdWorker(1)
+	b, _ := NewIdWorker(2)
+	x, _ := a.NextIdAt(at)
+	y, _ := b.NextIdAt(at)
+	if got := x.Redacted(); got != "2024-05-01T12:00:00.123Z/redacted" {
+		t.Errorf("got %q", got)
+	}
+	if x.Redacted() != y.Redacted() || strings.Contains(x.Redacted(), x.String()) {
+		t.Error("redaction leaks the node or sequence")
+	}
+}
@@ -0,0 +1,27 @@
+// Package echoreqid is the echo middleware of package reqid.
+package echoreqid
+
+import (
+	"github.com/labstack/echo/v4"
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/reqid"
+)
+
+// ContextKey is the echo context key holding the snowflake.ID of the request.
+const ContextKey = "request_id"
+
+// Middleware sets the request ID of every request in the echo context, the
+// request context and the reqid.Header of the response.
+func Middleware(g reqid.Generator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			if id, ok := reqid.ForRequest(g, r); ok {
+				c.Response().Header().Set(reqid.Header, id.String())
+				c.Set(ContextKey, id)
+				c.SetRequest(r.WithContext(snowflake.NewContext(r.Context(), id)))
+			}
+			return next(c)
+		}
+	}
+}
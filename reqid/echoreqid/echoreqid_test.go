@@ -0,0 +1,28 @@
+package echoreqid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/reqid"
+)
+
+func TestMiddleware(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(4)
+	e := echo.New()
+	e.Use(Middleware(worker))
+	var fromEcho, fromCtx snowflake.ID
+	e.GET("/", func(c echo.Context) error {
+		fromEcho = c.Get(ContextKey).(snowflake.ID)
+		fromCtx, _ = snowflake.FromContext(c.Request().Context())
+		return nil
+	})
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if fromEcho.NodeId() != 4 || fromEcho != fromCtx || w.Header().Get(reqid.Header) != fromEcho.String() {
+		t.Errorf("echo %d, context %d, header %q", fromEcho, fromCtx, w.Header().Get(reqid.Header))
+	}
+}
@@ -0,0 +1,24 @@
+// Package ginreqid is the gin middleware of package reqid.
+package ginreqid
+
+import (
+	"github.com/gin-gonic/gin"
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/reqid"
+)
+
+// ContextKey is the gin context key holding the snowflake.ID of the request.
+const ContextKey = "request_id"
+
+// Middleware sets the request ID of every request in the gin context, the
+// request context and the reqid.Header of the response.
+func Middleware(g reqid.Generator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id, ok := reqid.ForRequest(g, c.Request); ok {
+			c.Header(reqid.Header, id.String())
+			c.Set(ContextKey, id)
+			c.Request = c.Request.WithContext(snowflake.NewContext(c.Request.Context(), id))
+		}
+		c.Next()
+	}
+}
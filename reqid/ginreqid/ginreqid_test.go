@@ -0,0 +1,28 @@
+package ginreqid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/reqid"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	worker, _ := snowflake.NewIdWorker(4)
+	r := gin.New()
+	r.Use(Middleware(worker))
+	var fromGin, fromCtx snowflake.ID
+	r.GET("/", func(c *gin.Context) {
+		fromGin = c.MustGet(ContextKey).(snowflake.ID)
+		fromCtx, _ = snowflake.FromContext(c.Request.Context())
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if fromGin.NodeId() != 4 || fromGin != fromCtx || w.Header().Get(reqid.Header) != fromGin.String() {
+		t.Errorf("gin %d, context %d, header %q", fromGin, fromCtx, w.Header().Get(reqid.Header))
+	}
+}
@@ -0,0 +1,47 @@
+// Package reqid stamps incoming HTTP requests with a snowflake id, in place
+// of a random request ID: the ids sort by arrival and tell when and on
+// which node a request came in. The id goes in a header and in the request
+// context, where snowflake.FromContext finds it.
+//
+// Middleware serves net/http; the subpackages ginreqid and echoreqid adapt
+// it to gin and echo.
+package reqid
+
+import (
+	"net/http"
+	"strconv"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Header is the header carrying the request ID, on requests and responses.
+const Header = "X-Request-Id"
+
+// Generator issues ids, as *snowflake.IdWorker and *snowflake.WorkerPool do.
+type Generator interface {
+	NextId() (snowflake.ID, error)
+}
+
+// ForRequest returns the request ID of r: the id in its Header when an
+// upstream service already set a snowflake id there, so one id follows the
+// request across services, or a new id from g. ok is false when g fails;
+// the request should go on without an id rather than fail.
+func ForRequest(g Generator, r *http.Request) (id snowflake.ID, ok bool) {
+	if n, err := strconv.ParseInt(r.Header.Get(Header), 10, 64); err == nil && n > 0 {
+		return snowflake.ID(n), true
+	}
+	id, err := g.NextId()
+	return id, err == nil
+}
+
+// Middleware sets the request ID of every request in the request context
+// and in the Header of the response.
+func Middleware(g Generator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := ForRequest(g, r); ok {
+			w.Header().Set(Header, id.String())
+			r = r.WithContext(snowflake.NewContext(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
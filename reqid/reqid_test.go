@@ -0,0 +1,38 @@
+package reqid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestMiddleware(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(4)
+	var got snowflake.ID
+	h := Middleware(worker, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = snowflake.FromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got.NodeId() != 4 || w.Header().Get(Header) != got.String() {
+		t.Errorf("context %d, header %q", got, w.Header().Get(Header))
+	}
+
+	// 上游已设置的 ID 原样传递
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(Header, "12345")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if got != 12345 || w.Header().Get(Header) != "12345" {
+		t.Errorf("propagated: context %d, header %q", got, w.Header().Get(Header))
+	}
+
+	r.Header.Set(Header, "not-an-id")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if got == 12345 || got.NodeId() != 4 {
+		t.Errorf("foreign request ID should be replaced, got %d", got)
+	}
+}
@@ -0,0 +1,71 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrClockMovedBackwards matches, with errors.Is, every error returned
+// because the clock moved backwards.
+var ErrClockMovedBackwards = errors.New("clock moved backwards")
+
+// ClockMovedBackwardsError is returned while the clock is behind the last
+// timestamp a worker used.
+type ClockMovedBackwardsError struct {
+	Millis int64 // 回拨的毫秒数
+}
+
+func (e *ClockMovedBackwardsError) Error() string {
+	return fmt.Sprintf("Clock moved backwards.  Refusing to generate id for %d milliseconds", e.Millis)
+}
+
+func (e *ClockMovedBackwardsError) Is(target error) bool {
+	return target == ErrClockMovedBackwards
+}
+
+// RetryPolicy controls RetryNextId.
+type RetryPolicy struct {
+	MaxAttempts    int           // 最多尝试次数, 含第一次
+	InitialBackoff time.Duration // 第一次重试前的等待
+	MaxBackoff     time.Duration // 等待上限
+	Multiplier     float64       // 每次重试等待的倍数
+}
+
+// DefaultRetryPolicy rides out rollbacks of up to about a second.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    8,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     500 * time.Millisecond,
+	Multiplier:     2,
+}
+
+// RetryNextId get a snowflake id, retrying with exponential backoff while
+// the clock is behind. Each wait is at least the reported rollback, capped
+// at MaxBackoff. Other errors and ctx cancellation are returned at once.
+func RetryNextId(ctx context.Context, worker *IdWorker, policy RetryPolicy) (ID, error) {
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		id, err := worker.NextId()
+		var rollback *ClockMovedBackwardsError
+		if err == nil || !errors.As(err, &rollback) || attempt >= policy.MaxAttempts {
+			return id, err
+		}
+		wait := backoff
+		if d := time.Duration(rollback.Millis) * time.Millisecond; d > wait {
+			wait = d
+		}
+		if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+			wait = policy.MaxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+	}
+}
@@ -0,0 +1,49 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rewindClock starts behind the worker's last timestamp and catches up.
+type rewindClock struct {
+	base  int64
+	calls int64
+}
+
+func (c *rewindClock) Millis() int64 {
+	n := atomic.AddInt64(&c.calls, 1)
+	return c.base - 3 + n
+}
+
+func TestRetryNextId(t *testing.T) {
+	clock := &rewindClock{base: timeGen()}
+	idworker, _ := NewIdWorker(1, WithClock(clock))
+	idworker.lastTimestamp = clock.base
+
+	_, err := idworker.NextId()
+	if !errors.Is(err, ErrClockMovedBackwards) {
+		t.Fatalf("want ErrClockMovedBackwards, got %v", err)
+	}
+	id, err := RetryNextId(context.Background(), idworker, DefaultRetryPolicy)
+	if err != nil || id.IsZero() {
+		t.Fatalf("retry: got %d, %v", id, err)
+	}
+}
+
+func TestRetryNextIdGivesUp(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	idworker.lastTimestamp = timeGen() + int64(time.Hour/time.Millisecond)
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Multiplier: 2}
+	if _, err := RetryNextId(context.Background(), idworker, policy); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Errorf("want ErrClockMovedBackwards, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := RetryNextId(ctx, idworker, DefaultRetryPolicy); err != context.Canceled {
+		t.Errorf("cancelled: got %v", err)
+	}
+}
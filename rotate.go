@@ -0,0 +1,29 @@
+package snowflake
+
+// RotateNode moves the worker to a node ID acquired from alloc and releases
+// its old one, without stopping it: calls in flight finish on the old node,
+// later ones use the new node. The old node is released only once the clock
+// has passed the last millisecond it issued ids in, so its next owner cannot
+// repeat them. It returns the new node ID.
+func (id *IdWorker) RotateNode(alloc NodeAllocator) (int64, error) {
+	nodeId, err := alloc.Acquire()
+	if err != nil {
+		return 0, err
+	}
+	id.Lock()
+	l := id.layout
+	old := id.nodeId
+	id.nodeId = nodeId
+	if err := id.checkLayout(l); err != nil {
+		id.nodeId = old
+		id.Unlock()
+		alloc.Release(nodeId)
+		return 0, err
+	}
+	last := id.lastTimestamp
+	clock := id.clock
+	id.Unlock()
+
+	waitPast(clock, last)
+	return nodeId, alloc.Release(old)
+}
@@ -0,0 +1,32 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestRotateNode(t *testing.T) {
+	alloc := NewMemoryNodeAllocator(5, 6)
+	first, _ := alloc.Acquire()
+	w, _ := NewIdWorker(first)
+	before, _ := w.NextId()
+
+	nodeId, err := w.RotateNode(alloc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _ := w.NextId()
+	if before.NodeId() != 5 || nodeId != 6 || after.NodeId() != 6 {
+		t.Errorf("nodes: before %d, rotated to %d, after %d", before.NodeId(), nodeId, after.NodeId())
+	}
+	if n, err := alloc.Acquire(); err != nil || n != 5 {
+		t.Errorf("old node not released: got %d, %v", n, err)
+	}
+	if _, err := w.RotateNode(alloc); err == nil {
+		t.Error("rotating with no free node should fail")
+	}
+
+	big := NewMemoryNodeAllocator(maxNodeId+1, maxNodeId+1)
+	if _, err := w.RotateNode(big); err == nil || w.nodeId != 6 {
+		t.Errorf("node outside the layout: got %v, node %d", err, w.nodeId)
+	}
+}
@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Sampler picks at most K ids per time bucket out of a stream, by the time
+// decoded from each id, for sparse indexes and dashboards over high-volume
+// streams. The first K ids of a bucket are kept.
+//
+// Streams may be somewhat out of order: the buckets of the last Window
+// intervals before the newest id seen stay open, and older ids are
+// dropped, so no bucket ever yields more than K.
+type Sampler struct {
+	sync.Mutex
+	layout   Layout
+	interval int64 // 桶宽, 毫秒
+	k        int
+	Window   int           // 保持打开的桶数
+	counts   map[int64]int // 每个桶已选中的数量
+	newest   int64         // 见过的最新桶
+	dropped  int64         // 因过旧丢弃的 ID 数
+}
+
+// NewSampler new a sampler keeping k ids per interval, decoding ids with
+// layout. Window starts at 2 buckets.
+func NewSampler(layout Layout, interval time.Duration, k int) (*Sampler, error) {
+	if interval < time.Millisecond || k < 1 {
+		return nil, errors.New("sampler needs an interval of at least 1ms and k >= 1")
+	}
+	return &Sampler{
+		layout:   layout,
+		interval: int64(interval / time.Millisecond),
+		k:        k,
+		Window:   2,
+		counts:   make(map[int64]int),
+		newest:   -1 << 63,
+	}, nil
+}
+
+// Offer feeds the next id of the stream and reports whether it is sampled.
+func (s *Sampler) Offer(f ID) bool {
+	bucket := f.WithLayout(s.layout).Timestamp() / s.interval
+	s.Lock()
+	defer s.Unlock()
+	if bucket > s.newest {
+		s.newest = bucket
+		for b := range s.counts {
+			if b <= s.newest-int64(s.Window) {
+				delete(s.counts, b)
+			}
+		}
+	}
+	if bucket <= s.newest-int64(s.Window) {
+		s.dropped++
+		return false
+	}
+	if s.counts[bucket] >= s.k {
+		return false
+	}
+	s.counts[bucket]++
+	return true
+}
+
+// Dropped returns how many ids arrived too late for their bucket.
+func (s *Sampler) Dropped() int64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.dropped
+}
@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	s, err := NewSampler(DefaultLayout, time.Second, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idworker, _ := NewIdWorker(1)
+	start := time.Unix(1700000000, 0)
+	at := func(d time.Duration) ID {
+		f, err := idworker.NextIdAt(start.Add(d))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	sampled := 0
+	for i := 0; i < 100; i++ {
+		if s.Offer(at(time.Duration(i) * 10 * time.Millisecond)) {
+			sampled++
+		}
+	}
+	if sampled != 3 {
+		t.Errorf("sampled %d ids of one second, want 3", sampled)
+	}
+	if !s.Offer(at(1500 * time.Millisecond)) {
+		t.Error("first id of the next bucket should be sampled")
+	}
+	// 仍在窗口内的旧桶: 已满
+	late, _ := NewIdWorker(2)
+	old, _ := late.NextIdAt(start.Add(500 * time.Millisecond))
+	if s.Offer(old) {
+		t.Error("full bucket should not sample more")
+	}
+	s.Offer(at(5 * time.Second))
+	older, _ := late.NextIdAt(start.Add(600 * time.Millisecond))
+	if s.Offer(older) || s.Dropped() != 1 {
+		t.Errorf("id behind the window should be dropped, dropped %d", s.Dropped())
+	}
+	if _, err := NewSampler(DefaultLayout, time.Second, 0); err == nil {
+		t.Error("k 0 should fail")
+	}
+}
@@ -0,0 +1,126 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Sequencer chooses the sequences used within one millisecond. A Sequencer
+// belongs to a single worker and is only called with the worker locked.
+// max is the largest sequence the worker's layout can hold.
+type Sequencer interface {
+	// Start returns the first sequence of a new millisecond.
+	Start(max int64) int64
+	// Next returns the sequence following seq in the same millisecond, or
+	// -1 when the millisecond is exhausted.
+	Next(seq, max int64) int64
+}
+
+// WithSequencer makes the worker use s for the sequence bits.
+func WithSequencer(s Sequencer) Option {
+	return func(id *IdWorker) error {
+		id.sequencer = s
+		return nil
+	}
+}
+
+// nextSequence returns the sequence after the current one, or -1.
+func (id *IdWorker) nextSequence() int64 {
+	if id.sequence < 0 {
+		return -1
+	}
+	return id.sequencer.Next(id.sequence, id.layout.MaxSequence())
+}
+
+// startSequence returns the first sequence of a new millisecond, drawing a
+// new node too in random-fill mode.
+func (id *IdWorker) startSequence() int64 {
+	if id.randomFill {
+		id.nodeId = cryptoInt63() & id.layout.MaxNodeId()
+	}
+	return id.sequencer.Start(id.layout.MaxSequence())
+}
+
+// IncrementingSequencer counts 0, 1, 2, ... and is the default.
+type IncrementingSequencer struct{}
+
+func (IncrementingSequencer) Start(max int64) int64 {
+	return 0
+}
+
+func (IncrementingSequencer) Next(seq, max int64) int64 {
+	if seq >= max {
+		return -1
+	}
+	return seq + 1
+}
+
+// RandomStartSequencer starts every millisecond at a random sequence and
+// counts up, wrapping around, so ids do not reveal how many were issued.
+type RandomStartSequencer struct {
+	start int64
+}
+
+func (r *RandomStartSequencer) Start(max int64) int64 {
+	r.start = rand.Int63n(max + 1)
+	return r.start
+}
+
+func (r *RandomStartSequencer) Next(seq, max int64) int64 {
+	next := (seq + 1) & max
+	if next == r.start {
+		return -1
+	}
+	return next
+}
+
+// SteppedSequencer counts offset, offset+step, offset+2*step, ... so that
+// workers with different offsets never share a sequence. With step 2 and
+// offsets 0 and 1, two datacenters can dual-write under the same node ID
+// during an emergency without colliding.
+type SteppedSequencer struct {
+	step, offset int64
+}
+
+// NewSteppedSequencer new a stepped sequencer.
+func NewSteppedSequencer(step, offset int64) (*SteppedSequencer, error) {
+	if step <= 0 {
+		return nil, errors.New("step must be positive")
+	}
+	if offset < 0 || offset >= step {
+		return nil, errors.New(fmt.Sprintf("offset must be between 0 and %d", step-1))
+	}
+	return &SteppedSequencer{step: step, offset: offset}, nil
+}
+
+func (s *SteppedSequencer) Start(max int64) int64 {
+	return s.offset
+}
+
+func (s *SteppedSequencer) Next(seq, max int64) int64 {
+	if seq+s.step > max {
+		return -1
+	}
+	return seq + s.step
+}
+
+// WithSequencePartition restricts the worker to partition index of count
+// interleaved partitions of the sequence space: with count 2, partition 0
+// uses the even sequences and partition 1 the odd ones. Blue and green
+// deployments can then share a node ID during a rollout without colliding,
+// each at 1/count of the usual throughput. Partitioned workers cannot lease
+// ranges, which take whole milliseconds.
+func WithSequencePartition(index, count int64) Option {
+	return func(id *IdWorker) error {
+		if count > id.layout.MaxSequence()+1 {
+			return errors.New(fmt.Sprintf("at most %d sequence partitions", id.layout.MaxSequence()+1))
+		}
+		s, err := NewSteppedSequencer(count, index)
+		if err != nil {
+			return err
+		}
+		id.sequencer = s
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+// countSequences returns how many sequences s yields in one millisecond.
+func countSequences(s Sequencer) (int, map[int64]bool) {
+	seen := make(map[int64]bool)
+	for seq := s.Start(sequenceMask); seq >= 0; seq = s.Next(seq, sequenceMask) {
+		if seen[seq] {
+			break
+		}
+		seen[seq] = true
+	}
+	return len(seen), seen
+}
+
+func TestSequencers(t *testing.T) {
+	if n, _ := countSequences(IncrementingSequencer{}); n != sequenceMask+1 {
+		t.Errorf("incrementing: got %d sequences", n)
+	}
+	if n, _ := countSequences(&RandomStartSequencer{}); n != sequenceMask+1 {
+		t.Errorf("random start: got %d sequences", n)
+	}
+	even, _ := NewSteppedSequencer(2, 0)
+	odd, _ := NewSteppedSequencer(2, 1)
+	n, evens := countSequences(even)
+	m, odds := countSequences(odd)
+	if n != (sequenceMask+1)/2 || m != (sequenceMask+1)/2 {
+		t.Errorf("stepped: got %d and %d sequences", n, m)
+	}
+	for seq := range evens {
+		if odds[seq] {
+			t.Errorf("stepped sequencers share sequence %d", seq)
+		}
+	}
+	if _, err := NewSteppedSequencer(2, 2); err == nil {
+		t.Error("offset >= step should fail")
+	}
+}
+
+func TestWorkerSequencer(t *testing.T) {
+	odd, _ := NewSteppedSequencer(2, 1)
+	idworker, _ := NewIdWorker(1, WithSequencer(odd))
+	seen := make(map[ID]bool)
+	for i := 0; i < 2*(sequenceMask+1); i++ {
+		id, err := idworker.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.WithLayout(DefaultLayout).Sequence()%2 != 1 {
+			t.Fatalf("id %d has an even sequence", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSequencePartition(t *testing.T) {
+	blue, _ := NewIdWorker(1, WithSequencePartition(0, 2))
+	green, _ := NewIdWorker(1, WithSequencePartition(1, 2))
+	at := time.Now()
+	seen := make(map[ID]bool)
+	for _, w := range []*IdWorker{blue, green} {
+		for i := 0; i < (sequenceMask+1)/2; i++ {
+			id, err := w.NextIdAt(at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen[id] {
+				t.Fatalf("duplicate id %d", id)
+			}
+			seen[id] = true
+		}
+		if _, err := w.NextIdAt(at); err != ErrSequenceExhausted {
+			t.Errorf("partition should be exhausted, got %v", err)
+		}
+	}
+	if _, err := blue.LeaseRange(1); err == nil {
+		t.Error("partitioned worker should not lease ranges")
+	}
+	if _, err := NewIdWorker(1, WithSequencePartition(2, 2)); err == nil {
+		t.Error("index out of range should fail")
+	}
+	if _, err := NewIdWorker(1, WithSequencePartition(0, sequenceMask+2)); err == nil {
+		t.Error("more partitions than sequences should fail")
+	}
+}
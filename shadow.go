@@ -0,0 +1,131 @@
+package snowflake
+
+import (
+	"sync"
+)
+
+// ShadowHooks are called by a ShadowWorker, with its lock held.
+type ShadowHooks struct {
+	// OnMap is called for every new legacy id to id mapping, e.g. to
+	// persist it.
+	OnMap func(legacy string, id ID)
+	// OnLegacyCollision is called when a legacy id is seen again; the
+	// mapping made the first time is kept.
+	OnLegacyCollision func(legacy string, id ID)
+	// OnCollision is called when an id issued by this worker is also one
+	// passed to Observe, for another legacy id, which means two generators
+	// share a node.
+	OnCollision func(id ID, legacy, other string)
+}
+
+// ShadowStats counts what a ShadowWorker has seen.
+type ShadowStats struct {
+	Mapped           int64 `json:"mapped"`            // 新建的映射数
+	LegacyCollisions int64 `json:"legacy_collisions"` // 旧系统 ID 重复的次数
+	Collisions       int64 `json:"collisions"`        // 雪花 ID 重复的次数
+}
+
+// ShadowWorker runs alongside a legacy id system, UUIDs or database
+// auto-increments, during a migration: every record written with a legacy
+// id is also given a snowflake id, and the mapping and any collisions on
+// either side are recorded.
+//
+// A worker never issues the same id twice, so snowflake collisions are
+// only found against ids of other generators passed to Observe, e.g. from
+// the OnMap hook of the shadow workers of other processes.
+//
+// The mappings and the observed ids are held in memory and never evicted,
+// so they grow with every record of the migration; use OnMap to keep the
+// mapping elsewhere, and run a shadow worker only for the migration.
+type ShadowWorker struct {
+	sync.Mutex
+	worker   *IdWorker
+	hooks    ShadowHooks
+	byLegacy map[string]ID
+	byID     map[ID]string
+	observed map[ID]string // 其他生成器发放的 ID
+	stats    ShadowStats
+}
+
+// NewShadowWorker new a shadow worker issuing ids from worker.
+func NewShadowWorker(worker *IdWorker, hooks ShadowHooks) *ShadowWorker {
+	return &ShadowWorker{
+		worker:   worker,
+		hooks:    hooks,
+		byLegacy: make(map[string]ID),
+		byID:     make(map[ID]string),
+		observed: make(map[ID]string),
+	}
+}
+
+// NextIdFor returns the snowflake id of the record with the given legacy
+// id, issuing one the first time the legacy id is seen.
+func (s *ShadowWorker) NextIdFor(legacy string) (ID, error) {
+	s.Lock()
+	defer s.Unlock()
+	if id, ok := s.byLegacy[legacy]; ok {
+		s.stats.LegacyCollisions++
+		if s.hooks.OnLegacyCollision != nil {
+			s.hooks.OnLegacyCollision(legacy, id)
+		}
+		return id, nil
+	}
+	id, err := s.worker.NextId()
+	if err != nil {
+		return 0, err
+	}
+	if other, ok := s.observed[id]; ok {
+		s.collision(id, legacy, other)
+	}
+	s.byLegacy[legacy] = id
+	s.byID[id] = legacy
+	s.stats.Mapped++
+	if s.hooks.OnMap != nil {
+		s.hooks.OnMap(legacy, id)
+	}
+	return id, nil
+}
+
+// Observe records that another generator issued id for legacy, and reports
+// a collision if this worker issued id for a different legacy id. Ids
+// observed before this worker issues them are reported when it does.
+func (s *ShadowWorker) Observe(id ID, legacy string) {
+	s.Lock()
+	defer s.Unlock()
+	if mine, ok := s.byID[id]; ok && mine != legacy {
+		s.collision(id, mine, legacy)
+	}
+	s.observed[id] = legacy
+}
+
+// collision counts and reports id issued here for legacy and elsewhere for
+// other. Called with the lock held.
+func (s *ShadowWorker) collision(id ID, legacy, other string) {
+	s.stats.Collisions++
+	if s.hooks.OnCollision != nil {
+		s.hooks.OnCollision(id, legacy, other)
+	}
+}
+
+// Lookup returns the snowflake id mapped to a legacy id.
+func (s *ShadowWorker) Lookup(legacy string) (ID, bool) {
+	s.Lock()
+	defer s.Unlock()
+	id, ok := s.byLegacy[legacy]
+	return id, ok
+}
+
+// Legacy returns the legacy id mapped to a snowflake id.
+func (s *ShadowWorker) Legacy(id ID) (string, bool) {
+	s.Lock()
+	defer s.Unlock()
+	legacy, ok := s.byID[id]
+	return legacy, ok
+}
+
+// Stats returns the counters of the shadow worker.
+func (s *ShadowWorker) Stats() ShadowStats {
+	s.Lock()
+	defer s.Unlock()
+	return s.stats
+}
@@ -0,0 +1,64 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestShadowWorker(t *testing.T) {
+	w, _ := NewIdWorker(1)
+	var mapped, dup int
+	s := NewShadowWorker(w, ShadowHooks{
+		OnMap:             func(string, ID) { mapped++ },
+		OnLegacyCollision: func(string, ID) { dup++ },
+	})
+	a, _ := s.NextIdFor("order-1")
+	b, _ := s.NextIdFor("order-2")
+	again, _ := s.NextIdFor("order-1")
+	if a == b || again != a {
+		t.Errorf("ids: %d %d %d", a, b, again)
+	}
+	if legacy, ok := s.Legacy(b); !ok || legacy != "order-2" {
+		t.Errorf("Legacy: got %q, %v", legacy, ok)
+	}
+	if id, ok := s.Lookup("order-2"); !ok || id != b {
+		t.Errorf("Lookup: got %d, %v", id, ok)
+	}
+	want := ShadowStats{Mapped: 2, LegacyCollisions: 1}
+	if st := s.Stats(); st != want || mapped != 2 || dup != 1 {
+		t.Errorf("stats: got %+v, hooks %d/%d", st, mapped, dup)
+	}
+}
+
+type fixedClock int64
+
+func (c fixedClock) Millis() int64 { return int64(c) }
+
+func TestShadowWorkerCollision(t *testing.T) {
+	clock := fixedClock(timeGen())
+	w, _ := NewIdWorker(1, WithClock(clock))
+	var got string
+	s := NewShadowWorker(w, ShadowHooks{
+		OnCollision: func(id ID, legacy, other string) { got = other },
+	})
+	// 另一台共享节点 ID 的生成器先发放了同一个 ID
+	ow, _ := NewIdWorker(1, WithClock(clock))
+	other := NewShadowWorker(ow, ShadowHooks{
+		OnMap: func(legacy string, id ID) { s.Observe(id, legacy) },
+	})
+	other.NextIdFor("elsewhere")
+	s.NextIdFor("x")
+	if s.Stats().Collisions != 1 || got != "elsewhere" {
+		t.Errorf("collision not recorded: %+v", s.Stats())
+	}
+
+	// 本 worker 先发放, 之后才观察到
+	y, _ := s.NextIdFor("y")
+	s.Observe(y, "elsewhere-2")
+	if s.Stats().Collisions != 2 || got != "elsewhere-2" {
+		t.Errorf("late collision not recorded: %+v", s.Stats())
+	}
+	s.Observe(y, "y")
+	if s.Stats().Collisions != 2 {
+		t.Error("observing the same mapping is not a collision")
+	}
+}
@@ -0,0 +1,16 @@
+package snowflake
+
+// Shard maps f to one of n shards, for sharding databases by id. Taking f
+// modulo n spreads ids poorly: the sequence restarts at 0 every millisecond,
+// so low bits are mostly zero. Shard first mixes all 64 bits with the
+// splitmix64 finalizer (xor-shift 30, multiply 0xbf58476d1ce4e5b9, xor-shift
+// 27, multiply 0x94d049bb133111eb, xor-shift 31), then takes the result
+// modulo n. The function is part of the API: it never changes, so shard
+// assignments stay put across versions, platforms and languages. It panics
+// if n < 1.
+func (f ID) Shard(n int) int {
+	if n < 1 {
+		panic("snowflake: shard count must be at least 1")
+	}
+	return int(mix64(uint64(f)) % uint64(n))
+}
@@ -0,0 +1,33 @@
+package snowflake
+
+import "testing"
+
+func TestShard(t *testing.T) {
+	// 固定的取值: 分片函数变化会移动已有数据
+	for _, c := range []struct {
+		id   ID
+		n    int
+		want int
+	}{
+		{0, 16, 0},
+		{1, 16, 5},
+		{2703196044657165313, 16, 11},
+		{2703196044657165313, 1000, 211},
+	} {
+		if got := c.id.Shard(c.n); got != c.want {
+			t.Errorf("%d.Shard(%d) = %d, want %d", c.id, c.n, got, c.want)
+		}
+	}
+
+	// 同一毫秒内的连续 ID 应均匀分布
+	counts := make([]int, 8)
+	base := ID(2703196044657165313) &^ sequenceMask
+	for seq := ID(0); seq <= sequenceMask; seq++ {
+		counts[(base|seq).Shard(8)]++
+	}
+	for s, c := range counts {
+		if c < 96 || c > 160 {
+			t.Errorf("shard %d got %d of %d ids", s, c, sequenceMask+1)
+		}
+	}
+}
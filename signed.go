@@ -0,0 +1,73 @@
+package snowflake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSignature is returned for a signed id that is malformed,
+// forged or tampered with.
+var ErrInvalidSignature = errors.New("snowflake: invalid id signature")
+
+const signatureSize = 6 // 截断的 HMAC 长度, 48 位
+
+// SignedEncoder encodes ids as "<decimal id>.<signature>", where the
+// signature is a truncated HMAC-SHA256 of the id, so public APIs can reject
+// forged ids without a database lookup.
+type SignedEncoder struct {
+	keys [][]byte // 第一个用于签名, 全部用于校验
+}
+
+// NewSignedEncoder new an encoder signing with key. Ids signed with any of
+// previous still decode, so keys can be rotated without breaking links
+// already handed out: sign with the new key, keep the old one in previous
+// until its links may expire.
+func NewSignedEncoder(key []byte, previous ...[]byte) *SignedEncoder {
+	return &SignedEncoder{keys: append([][]byte{key}, previous...)}
+}
+
+func sign(key []byte, id ID) []byte {
+	b := id.IntBytes()
+	m := hmac.New(sha256.New, key)
+	m.Write(b[:])
+	return m.Sum(nil)[:signatureSize]
+}
+
+// Encode returns the signed form of id.
+func (e *SignedEncoder) Encode(id ID) string {
+	return id.String() + "." + base64.RawURLEncoding.EncodeToString(sign(e.keys[0], id))
+}
+
+// Decode verifies s and returns its id.
+func (e *SignedEncoder) Decode(s string) (ID, error) {
+	i := strings.LastIndexByte(s, '.')
+	if i < 0 {
+		return 0, ErrInvalidSignature
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(s[i+1:])
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+	id := ID(n)
+	if len(sig) != signatureSize || s[:i] != id.String() {
+		return 0, ErrInvalidSignature
+	}
+	// 每个密钥都校验且不提前返回, 耗时不泄露签名匹配了哪个密钥或匹配到哪一位
+	ok := 0
+	for _, key := range e.keys {
+		ok |= subtle.ConstantTimeCompare(sig, sign(key, id))
+	}
+	if ok != 1 {
+		return 0, ErrInvalidSignature
+	}
+	return id, nil
+}
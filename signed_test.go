@@ -0,0 +1,48 @@
+package snowflake
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignedEncoder(t *testing.T) {
+	e := NewSignedEncoder([]byte("secret"))
+	id := ID(1234567890123)
+	s := e.Encode(id)
+	if !strings.HasPrefix(s, "1234567890123.") {
+		t.Errorf("encode: got %q", s)
+	}
+	if got, err := e.Decode(s); err != nil || got != id {
+		t.Errorf("decode: got %d, %v", got, err)
+	}
+	tampered := "1234567890124" + s[strings.IndexByte(s, '.'):]
+	if _, err := e.Decode(tampered); err != ErrInvalidSignature {
+		t.Errorf("tampered id: got %v", err)
+	}
+	for _, bad := range []string{"", "123", "abc.def", "123.!!"} {
+		if _, err := e.Decode(bad); err != ErrInvalidSignature {
+			t.Errorf("%q: got %v", bad, err)
+		}
+	}
+}
+
+func TestSignedEncoderRotation(t *testing.T) {
+	old := NewSignedEncoder([]byte("old"))
+	rotated := NewSignedEncoder([]byte("new"), []byte("old"))
+	id := ID(1234567890123)
+	link := old.Encode(id)
+	if got, err := rotated.Decode(link); err != nil || got != id {
+		t.Errorf("link signed with the previous key: got %d, %v", got, err)
+	}
+	if s := rotated.Encode(id); s == link {
+		t.Error("rotated encoder should sign with the new key")
+	} else if _, err := old.Decode(s); err != ErrInvalidSignature {
+		t.Errorf("old encoder should not accept the new key: %v", err)
+	}
+	if _, err := NewSignedEncoder([]byte("new")).Decode(link); err != ErrInvalidSignature {
+		t.Errorf("dropped key should no longer verify: %v", err)
+	}
+	if _, err := old.Decode("0" + link); err != ErrInvalidSignature {
+		t.Errorf("non-canonical decimal: got %v", err)
+	}
+}
@@ -12,9 +12,9 @@ import (
  	"encoding/binary"
 	"strconv"
 	"errors"
-	"sync"
 	"time"
 	"fmt"
+	"runtime"
 )
 
 const (
@@ -24,6 +24,7 @@ const (
 	DistrictIdBits = uint(5)              // 区域 所占用位置
 	NodeIdBits     = uint(9)              // 节点 所占位置, 2^9 = 512
 	sequenceBits   = uint(10)             // 自增 ID 所占用位置, 每秒上限 1000 * (2^10) = 102.4w
+	TagBits        = uint(2)              // TaggedLayout 中类型标签所占用位置, 取自区域的高位
 
 	/*
 	 * snowflake-64bit :
@@ -33,9 +34,12 @@ const (
 	 * 1. 39 位时间截(毫秒级)，注意这是时间截的差值（当前时间截 - 开始时间截)。可以使用约: (1L << 39) / (1000L * 60 * 60 * 24 * 365)
 	 * 2. 9  位数据机器位，可以部署在 512 个节点
 	 * 3. 10 位序列，毫秒内的计数，同一机器，同一时间截并发 1024 个序号
+	 *
+	 * 类型标签需显式选用 TaggedLayout: 2 位标签 | 3 位区域，其余不变
 	 */
 	maxNodeId     = -1 ^ (-1 << NodeIdBits)     // 节点 ID 最大范围
 	maxDistrictId = -1 ^ (-1 << DistrictIdBits) // 最大区域范围
+	maxTag        = -1 ^ (-1 << TagBits)        // 最大标签范围
 
 	nodeIdShift        	= sequenceBits 	// 左移次数
 	districtIdShift		= sequenceBits + NodeIdBits
@@ -47,18 +51,44 @@ const (
 )
 
 type IdWorker struct {
-	sync.Mutex
-	sequence      int64 // 序号
-	lastTimestamp int64 // 最后时间戳
-	nodeId        int64 // 节点 ID
-	twepoch       int64 // 起始时间戳
-	districtId    int64 // 区域 ID
+	workerMutex
+	sequence      int64                 // 序号
+	lastTimestamp int64                 // 最后时间戳
+	nodeId        int64                 // 节点 ID
+	twepoch       int64                 // 起始时间戳
+	offset        int64                 // 时间戳的秘密偏移毫秒, 见 WithEpochOffset
+	districtId    int64                 // 区域 ID
+	tag           int64                 // 类型标签
+	store         TimestampStore        // 最后时间戳持久化
+	stats         WorkerStats           // 计数
+	clock         Clock                 // 时钟
+	fairBatch     bool                  // 批量生成跨毫秒时让出锁
+	sequencer     Sequencer             // 毫秒内序号策略
+	layout        Layout                // 位布局, 起始时间戳见 twepoch
+	cutover       *cutover              // 计划中的布局切换
+	onTick        func(prev, now int64) // 毫秒推进时的回调
+	randomFill    bool                  // 节点与序号随机填充
+	epochs        DistrictEpochs        // 各区域的起始时间戳
+	audit         *AuditLog             // 已发放 ID 的审计记录
+	frozen        *FrozenError          // 非空时暂停发放
+	created       time.Time             // 创建时间
+	lease         *nodeLease            // 节点租约丢失时的策略
+	ticks         *tickFlight           // 序号耗尽时合并等待下一毫秒
+	logs          *workerLog            // 事件日志, nil 时不记录
 }
 
+// Option configures an IdWorker created by NewIdWorker.
+type Option func(*IdWorker) error
+
 type ID int64
 
+const (
+	MinID ID = 0         // 默认布局下最小的 ID
+	MaxID ID = 1<<63 - 1 // 默认布局下最大的 ID
+)
+
 // NewIdWorker new a snowflake id generator object.
-func NewIdWorker(NodeId int64) (*IdWorker, error) {
+func NewIdWorker(NodeId int64, opts ...Option) (*IdWorker, error) {
 	var districtId int64
 	districtId = 1 // 暂时默认给1 ，方便以后扩展
 	if NodeId > maxNodeId || NodeId < 0 {
@@ -71,13 +101,32 @@ func NewIdWorker(NodeId int64) (*IdWorker, error) {
 	}
 
 	//fmt.Printf("worker starting. timestamp left shift %d, District id bits %d, worker id bits %d, sequence bits %d, workerid %d\n", timestampLeftShift, DistrictIdBits, NodeIdBits, sequenceBits, NodeId)
-	return &IdWorker{
+	worker := &IdWorker{
 		nodeId:        NodeId,
 		districtId:    districtId,
 		lastTimestamp: -1,
 		sequence:      0,
 		twepoch:       twepoch,
-	}, nil
+		clock:         systemClock{},
+		sequencer:     IncrementingSequencer{},
+		layout:        DefaultLayout,
+		created:       time.Now(),
+	}
+	for _, opt := range opts {
+		if err := opt(worker); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkDistrict(worker.layout, worker.districtId); err != nil {
+		return nil, err
+	}
+	if worker.layout.Micros {
+		worker.clock = microClock{worker.clock}
+	}
+	if _, ok := worker.clock.(systemClock); ok && worker.ticks != nil {
+		worker.ticks = processTicks
+	}
+	return worker, nil
 }
 
 // timeGen generate a unix millisecond.
@@ -87,10 +136,11 @@ func timeGen() int64 {
 }
 
 // tilNextMillis spin wait till next millisecond.
-func tilNextMillis(lastTimestamp int64) int64 {
-	timestamp := timeGen()
+func tilNextMillis(clock Clock, lastTimestamp int64) int64 {
+	timestamp := clock.Millis()
 	for timestamp <= lastTimestamp {
-		timestamp = timeGen()
+		runtime.Gosched()
+		timestamp = clock.Millis()
 	}
 	return timestamp
 }
@@ -102,36 +152,154 @@ func (id *IdWorker) NextId() (ID, error) {
 	return id.nextid()
 }
 
-// NextIds get snowflake ids.
+// NextIds get snowflake ids. When an id fails mid-batch, for instance on
+// a clock rollback, it returns the ids issued before it along with a
+// *BatchError.
 func (id *IdWorker) NextIds(num int) ([]ID, error) {
 	if num > maxNextIdsNum || num < 0 {
 		//fmt.Printf("NextIds num can't be greater than %d or less than 0\n", maxNextIdsNum)
 		return nil, errors.New(fmt.Sprintf("NextIds num: %d error", num))
 	}
 	ids := make([]ID, num)
+	if n, err := id.fill(ids); err != nil {
+		return ids[:n], err
+	}
+	return ids, nil
+}
+
+// NextIdsFor get one snowflake id per distinct key, all under one lock
+// acquisition, for handlers creating several entities at once.
+func (id *IdWorker) NextIdsFor(keys []string) (map[string]ID, error) {
+	distinct := make([]string, 0, len(keys))
+	out := make(map[string]ID, len(keys))
+	for _, k := range keys {
+		if _, ok := out[k]; !ok {
+			out[k] = 0
+			distinct = append(distinct, k)
+		}
+	}
+	if len(distinct) > maxNextIdsNum {
+		return nil, errors.New(fmt.Sprintf("NextIdsFor keys: %d error", len(distinct)))
+	}
+	ids := make([]ID, len(distinct))
+	if _, err := id.fill(ids); err != nil {
+		return nil, err
+	}
+	for i, k := range distinct {
+		out[k] = ids[i]
+	}
+	return out, nil
+}
+
+// fill sets every element of ids to a new id, stopping at the first
+// failure with a *BatchError. It returns how many were set.
+func (id *IdWorker) fill(ids []ID) (int, error) {
 	id.Lock()
 	defer id.Unlock()
-	for i := 0; i < num; i++ {
-		ids[i], _ = id.nextid()
+	for i := range ids {
+		if id.fairBatch {
+			id.yieldIfExhausted()
+		}
+		f, err := id.nextid()
+		if err != nil {
+			return i, &BatchError{IDs: ids[:i], Index: i, Err: err}
+		}
+		ids[i] = f
 	}
-	return ids, nil
+	return len(ids), nil
+}
+
+// NextInt64 get a snowflake id as a raw int64.
+func (id *IdWorker) NextInt64() (int64, error) {
+	f, err := id.NextId()
+	return int64(f), err
+}
+
+// NextInt64s get snowflake ids as raw int64s.
+func (id *IdWorker) NextInt64s(num int) ([]int64, error) {
+	ids, err := id.NextIds(num)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int64, len(ids))
+	for i, f := range ids {
+		out[i] = int64(f)
+	}
+	return out, nil
 }
 
 func (id *IdWorker) nextid() (ID, error) {
-	timestamp := timeGen()
+	return id.nextidAt(id.clock.Millis(), true)
+}
+
+// nextidAt issues an id for timestamp. When the millisecond is exhausted it
+// waits for the next one if wait is set, or fails otherwise.
+func (id *IdWorker) nextidAt(timestamp int64, wait bool) (ID, error) {
+	if id.frozen != nil {
+		return 0, id.frozen
+	}
+	waited, err := id.checkLease()
+	if err != nil {
+		return 0, err
+	}
+	if waited && wait {
+		timestamp = id.clock.Millis()
+	}
 	if timestamp < id.lastTimestamp {
-		return 0, errors.New(fmt.Sprintf("Clock moved backwards.  Refusing to generate id for %d milliseconds", id.lastTimestamp-timestamp))
+		id.stats.Errors++
+		expvarErrors.Add(1)
+		id.logRollback(id.lastTimestamp - timestamp)
+		return 0, &ClockMovedBackwardsError{Millis: id.lastTimestamp - timestamp}
 	}
 	if id.lastTimestamp == timestamp {
-		id.sequence = (id.sequence + 1) & sequenceMask
-		if id.sequence == 0 {
-			timestamp = tilNextMillis(id.lastTimestamp)
+		id.sequence = id.nextSequence()
+		if id.sequence < 0 {
+			id.stats.Exhausted++
+			expvarRollovers.Add(1)
+			id.logIssued(id.lastTimestamp, true)
+			if !wait {
+				return 0, ErrSequenceExhausted
+			}
+			if id.awaitTick(id.lastTimestamp) {
+				return id.nextidAt(id.clock.Millis(), wait)
+			}
+			timestamp = id.tilNextMillis(id.lastTimestamp)
+			id.sequence = id.startSequence()
 		}
 	} else {
-		id.sequence = 0
+		id.sequence = id.startSequence()
 	}
+	if id.store != nil && timestamp != id.lastTimestamp {
+		if err := id.store.Save(timestamp); err != nil {
+			id.stats.Errors++
+			expvarErrors.Add(1)
+			return 0, err
+		}
+	}
+	id.applyCutover(timestamp)
+	if id.onTick != nil && timestamp != id.lastTimestamp {
+		id.onTick(id.lastTimestamp, timestamp)
+	}
+	id.logIssued(timestamp, false)
 	id.lastTimestamp = timestamp
-	return ID(((timestamp - id.twepoch) << timestampLeftShift) | (id.districtId << districtIdShift) | (id.nodeId << nodeIdShift) | id.sequence), nil
+	id.stats.Generated++
+	expvarGenerated.Add(1)
+	f := id.pack(timestamp, id.sequence)
+	if id.audit != nil {
+		id.audit.record(id.nodeId, timestamp, f, f, 1)
+	}
+	return f, nil
+}
+
+// pack assembles an id from a millisecond timestamp and a sequence.
+func (id *IdWorker) pack(timestamp, sequence int64) ID {
+	return id.packFor(timestamp, sequence, id.districtId)
+}
+
+// packFor is pack for the given district.
+func (id *IdWorker) packFor(timestamp, sequence, districtId int64) ID {
+	l := id.layout
+	return ID(((timestamp - id.epochFor(districtId)) << l.timestampShift()) | (id.tag << l.tagShift()) | (districtId << l.districtShift()) | (id.nodeId << l.nodeShift()) | sequence)
 }
 
 func (f ID) Time() int64 {
@@ -146,6 +314,16 @@ func (f ID) DistrictId() int64 {
 	return int64(f) & districtMask >> districtIdShift
 }
 
+// IsZero reports whether f is the zero id.
+func (f ID) IsZero() bool {
+	return f == 0
+}
+
+// IsValid reports whether f is a non-zero id in the default layout.
+func (f ID) IsValid() bool {
+	return f > MinID && f <= MaxID
+}
+
 func (f ID) Int64() int64 {
 	return int64(f)
 }
@@ -154,16 +332,19 @@ func (f ID) String() string {
 	return strconv.FormatInt(int64(f), 10)
 }
 
+// Bytes returns the ASCII decimal of f; see RawBytes for the 8 raw bytes.
 func (f ID) Bytes() []byte {
 	return []byte(f.String())
 }
 
+// IntBytes returns the 8 raw bytes of f, most significant first.
 func (f ID) IntBytes() [8]byte {
 	var b [8]byte
 	binary.BigEndian.PutUint64(b[:], uint64(f))
 	return b
 }
 
+// Base64 returns the standard base64 of the ASCII decimal of f.
 func (f ID) Base64() string {
 	return base64.StdEncoding.EncodeToString(f.Bytes())
 }
@@ -1,6 +1,7 @@
 package snowflake
 
 import (
+	"strconv"
 	"testing"
 )
 
@@ -35,3 +36,39 @@ func BenchmarkSnowflake(b *testing.B) {
 		}
 	}
 }
+
+func TestNextInt64(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	n, err := idworker.NextInt64()
+	if err != nil || n <= 0 {
+		t.Errorf("NextInt64: got %d, %v", n, err)
+	}
+	ns, err := idworker.NextInt64s(5)
+	if err != nil || len(ns) != 5 || ns[0] <= n {
+		t.Errorf("NextInt64s: got %v, %v", ns, err)
+	}
+	if _, err := idworker.NextInt64s(-1); err == nil {
+		t.Error("negative num should fail")
+	}
+}
+
+func TestNextIdsFor(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	ids, err := idworker.NextIdsFor([]string{"order", "invoice", "order", "shipment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("got %d ids, want 3", len(ids))
+	}
+	if !(ids["order"] < ids["invoice"] && ids["invoice"] < ids["shipment"]) {
+		t.Errorf("ids should follow the order of the keys: %v", ids)
+	}
+	keys := make([]string, maxNextIdsNum+1)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	if _, err := idworker.NextIdsFor(keys); err == nil {
+		t.Error("too many keys should fail")
+	}
+}
@@ -0,0 +1,141 @@
+// Package snowflakeconnect serves an IdWorker as a connect-go service, so
+// teams on connect mount the generator with their usual interceptors and
+// HTTP middleware. Messages are plain Go structs encoded as JSON, with no
+// generated protobuf code; clients use NewClient or any Connect client
+// speaking JSON, e.g.
+//
+//	curl -H 'Content-Type: application/json' -d '{"n": 3}' \
+//		http://host/snowflake.v1.SnowflakeService/NextIds
+package snowflakeconnect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+const (
+	// ServiceName is the fully-qualified name of the service.
+	ServiceName = "snowflake.v1.SnowflakeService"
+
+	NextIdsProcedure = "/" + ServiceName + "/NextIds"
+	DecodeProcedure  = "/" + ServiceName + "/Decode"
+)
+
+// NextIdsRequest asks for Count ids.
+type NextIdsRequest struct {
+	Count int `json:"n"`
+}
+
+// NextIdsResponse holds the ids issued.
+type NextIdsResponse struct {
+	IDs []snowflake.ID `json:"ids"`
+}
+
+// DecodeRequest asks for the fields of ID.
+type DecodeRequest struct {
+	ID snowflake.ID `json:"id"`
+}
+
+// DecodeResponse holds the fields of an id, decoded with the default layout.
+type DecodeResponse struct {
+	ID         snowflake.ID `json:"id"`
+	Time       int64        `json:"time"`
+	DistrictId int64        `json:"district_id"`
+	NodeId     int64        `json:"node_id"`
+	Tag        string       `json:"tag"`
+}
+
+// jsonCodec encodes the plain structs above with encoding/json, standing
+// in for connect's protojson codec, which only takes protobuf messages.
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(b []byte, v interface{}) error {
+	return json.Unmarshal(b, v)
+}
+
+// NewHandler returns the path to mount the service on and its handler, in
+// the shape of generated connect code:
+//
+//	mux.Handle(snowflakeconnect.NewHandler(worker, connect.WithInterceptors(auth)))
+func NewHandler(worker *snowflake.IdWorker, opts ...connect.HandlerOption) (string, http.Handler) {
+	opts = append([]connect.HandlerOption{connect.WithCodec(jsonCodec{})}, opts...)
+	next := connect.NewUnaryHandler(NextIdsProcedure, func(ctx context.Context, req *connect.Request[NextIdsRequest]) (*connect.Response[NextIdsResponse], error) {
+		ids, err := worker.NextIds(req.Msg.Count)
+		if err != nil {
+			return nil, connectError(err)
+		}
+		return connect.NewResponse(&NextIdsResponse{IDs: ids}), nil
+	}, opts...)
+	decode := connect.NewUnaryHandler(DecodeProcedure, func(ctx context.Context, req *connect.Request[DecodeRequest]) (*connect.Response[DecodeResponse], error) {
+		id := req.Msg.ID
+		v := id.WithLayout(worker.Layout())
+		return connect.NewResponse(&DecodeResponse{
+			ID:         id,
+			Time:       v.Time(),
+			DistrictId: v.DistrictId(),
+			NodeId:     v.NodeId(),
+			Tag:        v.Tag().String(),
+		}), nil
+	}, opts...)
+	return "/" + ServiceName + "/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == NextIdsProcedure:
+			next.ServeHTTP(w, r)
+		case r.URL.Path == DecodeProcedure:
+			decode.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// connectError maps worker errors to codes the way httpapi maps them to
+// statuses: unavailable while frozen, invalid argument otherwise.
+func connectError(err error) error {
+	if errors.Is(err, snowflake.ErrFrozen) {
+		return connect.NewError(connect.CodeUnavailable, err)
+	}
+	return connect.NewError(connect.CodeInvalidArgument, err)
+}
+
+// Client calls the service.
+type Client struct {
+	next   *connect.Client[NextIdsRequest, NextIdsResponse]
+	decode *connect.Client[DecodeRequest, DecodeResponse]
+}
+
+// NewClient new a client of the service at baseURL.
+func NewClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) *Client {
+	baseURL = strings.TrimRight(baseURL, "/")
+	opts = append([]connect.ClientOption{connect.WithCodec(jsonCodec{})}, opts...)
+	return &Client{
+		next:   connect.NewClient[NextIdsRequest, NextIdsResponse](httpClient, baseURL+NextIdsProcedure, opts...),
+		decode: connect.NewClient[DecodeRequest, DecodeResponse](httpClient, baseURL+DecodeProcedure, opts...),
+	}
+}
+
+// NextIds asks the service for n ids.
+func (c *Client) NextIds(ctx context.Context, n int) ([]snowflake.ID, error) {
+	resp, err := c.next.CallUnary(ctx, connect.NewRequest(&NextIdsRequest{Count: n}))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Msg.IDs, nil
+}
+
+// Decode asks the service for the fields of id.
+func (c *Client) Decode(ctx context.Context, id snowflake.ID) (*DecodeResponse, error) {
+	resp, err := c.decode.CallUnary(ctx, connect.NewRequest(&DecodeRequest{ID: id}))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Msg, nil
+}
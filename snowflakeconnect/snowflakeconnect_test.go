@@ -0,0 +1,61 @@
+package snowflakeconnect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestService(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(6)
+	calls := 0
+	count := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			calls++
+			return next(ctx, req)
+		}
+	})
+	mux := http.NewServeMux()
+	mux.Handle(NewHandler(worker, connect.WithInterceptors(count)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewClient(srv.Client(), srv.URL)
+	ctx := context.Background()
+	ids, err := c.NextIds(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 5 || ids[0].NodeId() != 6 {
+		t.Errorf("next: got %v", ids)
+	}
+	d, err := c.Decode(ctx, ids[0])
+	if err != nil || d.ID != ids[0] || d.NodeId != 6 {
+		t.Errorf("decode: got %+v, %v", d, err)
+	}
+	if calls != 2 {
+		t.Errorf("interceptor saw %d calls, want 2", calls)
+	}
+
+	if _, err := c.NextIds(ctx, 1000); connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("too many ids: got %v", err)
+	}
+	worker.Freeze("test")
+	if _, err := c.NextIds(ctx, 1); connect.CodeOf(err) != connect.CodeUnavailable {
+		t.Errorf("frozen: got %v", err)
+	}
+
+	resp, err := http.Post(srv.URL+DecodeProcedure, "application/json", strings.NewReader(`{"id": 42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("plain JSON call: got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,120 @@
+// Package snowflakekit exposes an IdWorker as go-kit endpoints, so services
+// built on go-kit mount the generator behind their own middleware stacks
+// (auth, logging, rate limiting) like any other endpoint. NewHTTPHandler
+// serves the endpoints with the request and response shapes of httpapi.
+package snowflakekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// NextIdsRequest asks for Count ids.
+type NextIdsRequest struct {
+	Count int `json:"n"`
+}
+
+// NextIdsResponse holds the ids issued.
+type NextIdsResponse struct {
+	IDs []snowflake.ID `json:"ids"`
+}
+
+// DecodeRequest asks for the fields of ID.
+type DecodeRequest struct {
+	ID snowflake.ID `json:"id"`
+}
+
+// DecodeResponse holds the fields of an id, decoded with the default layout.
+type DecodeResponse struct {
+	ID         snowflake.ID `json:"id"`
+	Time       int64        `json:"time"`
+	DistrictId int64        `json:"district_id"`
+	NodeId     int64        `json:"node_id"`
+	Tag        string       `json:"tag"`
+}
+
+// Endpoints are the endpoints of the generator service.
+type Endpoints struct {
+	NextIds endpoint.Endpoint
+	Decode  endpoint.Endpoint
+}
+
+// MakeEndpoints new the endpoints serving ids from worker.
+func MakeEndpoints(worker *snowflake.IdWorker) Endpoints {
+	return Endpoints{
+		NextIds: func(ctx context.Context, request interface{}) (interface{}, error) {
+			req := request.(NextIdsRequest)
+			ids, err := worker.NextIds(req.Count)
+			if err != nil {
+				return nil, err
+			}
+			return NextIdsResponse{IDs: ids}, nil
+		},
+		Decode: func(ctx context.Context, request interface{}) (interface{}, error) {
+			id := request.(DecodeRequest).ID
+			v := id.WithLayout(worker.Layout())
+			return DecodeResponse{
+				ID:         id,
+				Time:       v.Time(),
+				DistrictId: v.DistrictId(),
+				NodeId:     v.NodeId(),
+				Tag:        v.Tag().String(),
+			}, nil
+		},
+	}
+}
+
+// NewHTTPHandler serves e over HTTP as GET /next?n= and GET /decode?id=.
+// Options, such as kithttp.ServerBefore for auth, apply to both.
+func NewHTTPHandler(e Endpoints, options ...kithttp.ServerOption) http.Handler {
+	options = append([]kithttp.ServerOption{kithttp.ServerErrorEncoder(encodeError)}, options...)
+	mux := http.NewServeMux()
+	mux.Handle("/next", kithttp.NewServer(e.NextIds, decodeNextIds, kithttp.EncodeJSONResponse, options...))
+	mux.Handle("/decode", kithttp.NewServer(e.Decode, decodeDecode, kithttp.EncodeJSONResponse, options...))
+	return mux
+}
+
+func decodeNextIds(_ context.Context, r *http.Request) (interface{}, error) {
+	req := NextIdsRequest{Count: 1}
+	if s := r.URL.Query().Get("n"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, badRequest{err}
+		}
+		req.Count = n
+	}
+	return req, nil
+}
+
+func decodeDecode(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := snowflake.ParseString(r.URL.Query().Get("id"))
+	if err != nil {
+		return nil, badRequest{err}
+	}
+	return DecodeRequest{ID: id}, nil
+}
+
+// badRequest marks errors of the request itself.
+type badRequest struct{ error }
+
+// encodeError answers like httpapi: 503 while the worker is frozen, 400
+// for anything else the caller can fix.
+func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	code := http.StatusBadRequest
+	if errors.Is(err, snowflake.ErrFrozen) {
+		code = http.StatusServiceUnavailable
+	}
+	if sc, ok := err.(kithttp.StatusCoder); ok {
+		code = sc.StatusCode()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
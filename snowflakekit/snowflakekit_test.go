@@ -0,0 +1,60 @@
+package snowflakekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/kit/endpoint"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestEndpoints(t *testing.T) {
+	worker, _ := snowflake.NewIdWorker(4)
+	e := MakeEndpoints(worker)
+	calls := 0
+	count := func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return next(ctx, req)
+		}
+	}
+	e.NextIds = count(e.NextIds)
+	srv := httptest.NewServer(NewHTTPHandler(e))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/next?n=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var next NextIdsResponse
+	json.NewDecoder(resp.Body).Decode(&next)
+	resp.Body.Close()
+	if len(next.IDs) != 3 || next.IDs[0].NodeId() != 4 || calls != 1 {
+		t.Errorf("next: got %v, %d middleware calls", next.IDs, calls)
+	}
+
+	resp, _ = http.Get(srv.URL + "/decode?id=" + next.IDs[0].String())
+	var d DecodeResponse
+	json.NewDecoder(resp.Body).Decode(&d)
+	resp.Body.Close()
+	if d.ID != next.IDs[0] || d.NodeId != 4 {
+		t.Errorf("decode: got %+v", d)
+	}
+
+	for url, want := range map[string]int{"/next?n=x": 400, "/next?n=1000": 400, "/decode?id=x": 400} {
+		resp, _ := http.Get(srv.URL + url)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: got %d, want %d", url, resp.StatusCode, want)
+		}
+	}
+	worker.Freeze("test")
+	resp, _ = http.Get(srv.URL + "/next")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("frozen: got %d", resp.StatusCode)
+	}
+}
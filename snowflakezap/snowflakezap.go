@@ -0,0 +1,61 @@
+// Package snowflakezap builds zap fields for snowflake ids. Ids are logged
+// as strings, since JSON log pipelines holding numbers in float64 lose the
+// low bits of an id.
+package snowflakezap
+
+import (
+	"context"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ID returns a field logging id as a string.
+func ID(key string, id snowflake.ID) zap.Field {
+	return zap.String(key, id.String())
+}
+
+// IDs returns a field logging ids as an array of strings.
+func IDs(key string, ids []snowflake.ID) zap.Field {
+	return zap.Array(key, idArray(ids))
+}
+
+// Decomposed returns a field logging id as an object with its fields,
+// decoded with the default layout.
+func Decomposed(key string, id snowflake.ID) zap.Field {
+	return zap.Object(key, decomposed(id))
+}
+
+// RequestID returns a request_id field for the id stored in ctx by
+// snowflake.NewContext, or a field logging nothing when there is none.
+func RequestID(ctx context.Context) zap.Field {
+	if id, ok := snowflake.FromContext(ctx); ok {
+		return ID("request_id", id)
+	}
+	return zap.Skip()
+}
+
+type idArray []snowflake.ID
+
+func (a idArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, id := range a {
+		enc.AppendString(id.String())
+	}
+	return nil
+}
+
+type decomposed snowflake.ID
+
+func (d decomposed) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	id := snowflake.ID(d)
+	v := id.WithLayout(snowflake.DefaultLayout)
+	enc.AddString("id", id.String())
+	enc.AddString("time", time.UnixMilli(v.Timestamp()).UTC().Format(time.RFC3339Nano))
+	enc.AddString("tag", v.Tag().String())
+	enc.AddInt64("district_id", v.DistrictId())
+	enc.AddInt64("node_id", v.NodeId())
+	enc.AddInt64("sequence", v.Sequence())
+	return nil
+}
@@ -0,0 +1,30 @@
+package snowflakezap
+
+import (
+	"context"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFields(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := zap.New(core)
+	id := snowflake.ID(1<<62 + 1)
+	ctx := snowflake.NewContext(context.Background(), id)
+	log.Info("order", ID("order_id", id), IDs("items", []snowflake.ID{1, 2}), Decomposed("decoded", id), RequestID(ctx), RequestID(context.Background()))
+
+	fields := logs.All()[0].ContextMap()
+	if fields["order_id"] != "4611686018427387905" || fields["request_id"] != "4611686018427387905" {
+		t.Errorf("got %v", fields)
+	}
+	if items, _ := fields["items"].([]interface{}); len(items) != 2 || items[0] != "1" {
+		t.Errorf("items: got %v", fields["items"])
+	}
+	decoded, _ := fields["decoded"].(map[string]interface{})
+	if decoded["id"] != "4611686018427387905" || decoded["sequence"] != int64(1) {
+		t.Errorf("decoded: got %v", decoded)
+	}
+}
@@ -0,0 +1,60 @@
+// Package snowflakezerolog logs snowflake ids with zerolog. Ids are logged
+// as strings, since JSON log pipelines holding numbers in float64 lose the
+// low bits of an id.
+package snowflakezerolog
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// ID adds id to e as a string.
+func ID(e *zerolog.Event, key string, id snowflake.ID) *zerolog.Event {
+	return e.Str(key, id.String())
+}
+
+// IDs adds ids to e as an array of strings.
+func IDs(e *zerolog.Event, key string, ids []snowflake.ID) *zerolog.Event {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return e.Strs(key, s)
+}
+
+// Decomposed adds id to e as an object with its fields, decoded with the
+// default layout.
+func Decomposed(e *zerolog.Event, key string, id snowflake.ID) *zerolog.Event {
+	return e.Object(key, Object(id))
+}
+
+// RequestID adds a request_id for the id stored in ctx by
+// snowflake.NewContext, if any.
+func RequestID(e *zerolog.Event, ctx context.Context) *zerolog.Event {
+	if id, ok := snowflake.FromContext(ctx); ok {
+		return ID(e, "request_id", id)
+	}
+	return e
+}
+
+// Object returns id as a zerolog object with its fields, for use with
+// zerolog.Context.Object and the like.
+func Object(id snowflake.ID) zerolog.LogObjectMarshaler {
+	return decomposed(id)
+}
+
+type decomposed snowflake.ID
+
+func (d decomposed) MarshalZerologObject(e *zerolog.Event) {
+	id := snowflake.ID(d)
+	v := id.WithLayout(snowflake.DefaultLayout)
+	e.Str("id", id.String()).
+		Str("time", time.UnixMilli(v.Timestamp()).UTC().Format(time.RFC3339Nano)).
+		Str("tag", v.Tag().String()).
+		Int64("district_id", v.DistrictId()).
+		Int64("node_id", v.NodeId()).
+		Int64("sequence", v.Sequence())
+}
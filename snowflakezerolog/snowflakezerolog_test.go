@@ -0,0 +1,39 @@
+package snowflakezerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestEvents(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+	id := snowflake.ID(1<<62 + 1)
+	ctx := snowflake.NewContext(context.Background(), id)
+	e := log.Info()
+	e = ID(e, "order_id", id)
+	e = IDs(e, "items", []snowflake.ID{1, 2})
+	e = Decomposed(e, "decoded", id)
+	RequestID(e, ctx).Msg("order")
+
+	var got struct {
+		OrderId   string   `json:"order_id"`
+		RequestId string   `json:"request_id"`
+		Items     []string `json:"items"`
+		Decoded   struct {
+			ID       string `json:"id"`
+			Sequence int64  `json:"sequence"`
+		} `json:"decoded"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.OrderId != "4611686018427387905" || got.RequestId != got.OrderId || len(got.Items) != 2 || got.Decoded.ID != got.OrderId || got.Decoded.Sequence != 1 {
+		t.Errorf("got %s", buf.Bytes())
+	}
+}
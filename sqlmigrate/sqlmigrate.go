@@ -0,0 +1,133 @@
+// Package sqlmigrate backfills snowflake ids into existing database rows,
+// taking each id's time from the row's creation time so that the ids sort
+// like the rows were created.
+package sqlmigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/sqlschema"
+)
+
+// Backfill fills the nullable IDColumn of Table for every row where it is
+// NULL, in CreatedColumn order, in batches of BatchSize rows each updated
+// in one transaction.
+//
+// A backfill can be stopped and run again: it skips rows that already have
+// an id and resumes the worker after the largest id already written. Rows
+// inserted during the backfill with an older creation time than rows
+// already filled must be given ids by the application instead.
+type Backfill struct {
+	DB            *sql.DB
+	Dialect       sqlschema.Dialect // 决定占位符, MySQL 用 ?, Postgres 用 $n
+	Table         string
+	KeyColumn     string // 现有主键, 例如自增 ID
+	CreatedColumn string // 创建时间列
+	IDColumn      string // 待回填的雪花 ID 列
+	Worker        *snowflake.BackfillWorker
+	BatchSize     int                // 默认 1000
+	Progress      func(filled int64) // 每批提交后调用
+}
+
+// Run backfills until no row is left or ctx is done, and returns the number
+// of rows filled.
+func (b *Backfill) Run(ctx context.Context) (int64, error) {
+	if b.Dialect != sqlschema.MySQL && b.Dialect != sqlschema.Postgres {
+		return 0, errors.New(fmt.Sprintf("sqlmigrate: unsupported dialect %q", b.Dialect))
+	}
+	if err := b.resume(ctx); err != nil {
+		return 0, err
+	}
+	size := b.BatchSize
+	if size <= 0 {
+		size = 1000
+	}
+	var filled int64
+	for {
+		n, err := b.batch(ctx, size)
+		filled += int64(n)
+		if err != nil {
+			return filled, err
+		}
+		if n == 0 {
+			return filled, nil
+		}
+		if b.Progress != nil {
+			b.Progress(filled)
+		}
+	}
+}
+
+// resume moves the worker past the largest id written by an earlier run.
+func (b *Backfill) resume(ctx context.Context) error {
+	var last sql.NullInt64
+	q := fmt.Sprintf("SELECT MAX(%s) FROM %s", b.IDColumn, b.Table)
+	if err := b.DB.QueryRowContext(ctx, q).Scan(&last); err != nil {
+		return err
+	}
+	if !last.Valid {
+		return nil
+	}
+	return b.Worker.Resume(snowflake.ID(last.Int64))
+}
+
+type row struct {
+	key     interface{}
+	created time.Time
+}
+
+// batch fills up to size rows and returns how many it filled.
+func (b *Backfill) batch(ctx context.Context, size int) (int, error) {
+	q := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IS NULL ORDER BY %s, %s LIMIT %d",
+		b.KeyColumn, b.CreatedColumn, b.Table, b.IDColumn, b.CreatedColumn, b.KeyColumn, size)
+	rs, err := b.DB.QueryContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	var rows []row
+	for rs.Next() {
+		var r row
+		if err := rs.Scan(&r.key, &r.created); err != nil {
+			rs.Close()
+			return 0, err
+		}
+		rows = append(rows, r)
+	}
+	rs.Close()
+	if err := rs.Err(); err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	update := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s",
+		b.Table, b.IDColumn, b.placeholder(1), b.KeyColumn, b.placeholder(2))
+	for _, r := range rows {
+		id, err := b.Worker.NextIdAt(r.created)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, update, int64(id), r.key)
+		}
+		if err != nil {
+			tx.Rollback()
+			return 0, errors.New(fmt.Sprintf("sqlmigrate: row %v: %v", r.key, err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func (b *Backfill) placeholder(n int) string {
+	if b.Dialect == sqlschema.Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
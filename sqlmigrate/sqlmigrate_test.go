@@ -0,0 +1,61 @@
+package sqlmigrate
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/sqlschema"
+)
+
+func TestBackfill(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	worker, _ := snowflake.NewBackfillWorker(500, created.Add(-time.Hour), created.Add(time.Hour))
+	// 上一次运行已在同一毫秒写入了序号 0
+	prev, _ := worker.NextIdAt(created)
+	worker, _ = snowflake.NewBackfillWorker(500, created.Add(-time.Hour), created.Add(time.Hour))
+	want := int64(prev) + 1
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MAX(sf_id) FROM orders")).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(int64(prev)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, created_at FROM orders WHERE sf_id IS NULL ORDER BY created_at, id LIMIT 2")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, created).AddRow(8, created.Add(time.Second)))
+	mock.ExpectBegin()
+	update := regexp.QuoteMeta("UPDATE orders SET sf_id = $1 WHERE id = $2")
+	mock.ExpectExec(update).WithArgs(want, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(update).WithArgs(sqlmock.AnyArg(), 8).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT id, created_at").WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+	var progress []int64
+	b := &Backfill{
+		DB:            db,
+		Dialect:       sqlschema.Postgres,
+		Table:         "orders",
+		KeyColumn:     "id",
+		CreatedColumn: "created_at",
+		IDColumn:      "sf_id",
+		Worker:        worker,
+		BatchSize:     2,
+		Progress:      func(n int64) { progress = append(progress, n) },
+	}
+	n, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(progress) != 1 || progress[0] != 2 {
+		t.Errorf("filled %d, progress %v", n, progress)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
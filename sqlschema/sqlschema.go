@@ -0,0 +1,152 @@
+// Package sqlschema emits recommended DDL for snowflake-keyed tables in
+// MySQL, PostgreSQL and ClickHouse, with time partitions computed from the
+// id layout so partition pruning works on the primary key alone, and SQL
+// functions decoding ids inside ClickHouse and BigQuery.
+package sqlschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// Dialect is a SQL dialect.
+type Dialect string
+
+const (
+	MySQL      Dialect = "mysql"
+	Postgres   Dialect = "postgres"
+	ClickHouse Dialect = "clickhouse"
+	BigQuery   Dialect = "bigquery"
+)
+
+// ColumnType returns the column type recommended for ids.
+func ColumnType(d Dialect) (string, error) {
+	switch d {
+	case MySQL:
+		return "BIGINT NOT NULL", nil
+	case Postgres:
+		return "bigint NOT NULL", nil
+	case ClickHouse:
+		return "Int64", nil
+	case BigQuery:
+		return "INT64 NOT NULL", nil
+	}
+	return "", unknown(d)
+}
+
+// TimeExpr returns an expression giving the creation time of the id held
+// in column.
+func TimeExpr(d Dialect, column string, l snowflake.Layout) (string, error) {
+	shift := timestampShift(l)
+	switch d {
+	case MySQL:
+		return fmt.Sprintf("FROM_UNIXTIME(((%s >> %d) + %d) / 1000)", column, shift, l.Epoch), nil
+	case Postgres:
+		return fmt.Sprintf("to_timestamp(((%s >> %d) + %d) / 1000.0)", column, shift, l.Epoch), nil
+	case ClickHouse:
+		return fmt.Sprintf("fromUnixTimestamp64Milli(bitShiftRight(%s, %d) + %d)", column, shift, l.Epoch), nil
+	case BigQuery:
+		return fmt.Sprintf("TIMESTAMP_MILLIS((%s >> %d) + %d)", column, shift, l.Epoch), nil
+	}
+	return "", unknown(d)
+}
+
+// IDAt returns the smallest id of layout l issued at t, the boundary to use
+// when partitioning or filtering ids by time.
+func IDAt(l snowflake.Layout, t time.Time) int64 {
+	ms := t.UnixNano() / int64(time.Millisecond)
+	if ms < l.Epoch {
+		return 0
+	}
+	return (ms - l.Epoch) << timestampShift(l)
+}
+
+// timestampShift is the number of bits below the timestamp.
+func timestampShift(l snowflake.Layout) uint {
+	return l.TagBits + l.DistrictBits + l.NodeBits + l.SequenceBits
+}
+
+// Table describes a snowflake-keyed table.
+type Table struct {
+	Name     string
+	IDColumn string           // 默认 "id"
+	Columns  []string         // 其余列的定义, 原样输出
+	Layout   snowflake.Layout // 零值时使用 snowflake.DefaultLayout
+	From, To time.Time        // 按月分区的范围, 零值时不分区 (ClickHouse 总是按月分区)
+}
+
+// DDL returns the CREATE TABLE statements for t.
+func (t Table) DDL(d Dialect) (string, error) {
+	if t.Name == "" {
+		return "", errors.New("sqlschema: table needs a name")
+	}
+	col := t.IDColumn
+	if col == "" {
+		col = "id"
+	}
+	l := t.Layout
+	if l == (snowflake.Layout{}) {
+		l = snowflake.DefaultLayout
+	}
+	typ, err := ColumnType(d)
+	if err != nil {
+		return "", err
+	}
+	cols := append([]string{col + " " + typ}, t.Columns...)
+	months := monthBoundaries(t.From, t.To)
+
+	var b strings.Builder
+	switch d {
+	case MySQL:
+		fmt.Fprintf(&b, "CREATE TABLE %s (\n  %s,\n  PRIMARY KEY (%s)\n)", t.Name, strings.Join(cols, ",\n  "), col)
+		if len(months) > 1 {
+			fmt.Fprintf(&b, "\nPARTITION BY RANGE (%s) (\n", col)
+			for i := 1; i < len(months); i++ {
+				fmt.Fprintf(&b, "  PARTITION p%s VALUES LESS THAN (%d),\n", months[i-1].Format("200601"), IDAt(l, months[i]))
+			}
+			b.WriteString("  PARTITION pmax VALUES LESS THAN MAXVALUE\n)")
+		}
+		b.WriteString(";\n")
+	case Postgres:
+		fmt.Fprintf(&b, "CREATE TABLE %s (\n  %s,\n  PRIMARY KEY (%s)\n)", t.Name, strings.Join(cols, ",\n  "), col)
+		if len(months) > 1 {
+			fmt.Fprintf(&b, " PARTITION BY RANGE (%s)", col)
+		}
+		b.WriteString(";\n")
+		for i := 1; i < len(months); i++ {
+			fmt.Fprintf(&b, "CREATE TABLE %s_p%s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);\n",
+				t.Name, months[i-1].Format("200601"), t.Name, IDAt(l, months[i-1]), IDAt(l, months[i]))
+		}
+	case ClickHouse:
+		expr, _ := TimeExpr(d, col, l)
+		fmt.Fprintf(&b, "CREATE TABLE %s (\n  %s\n)\nENGINE = MergeTree\nPARTITION BY toYYYYMM(%s)\nORDER BY %s;\n",
+			t.Name, strings.Join(cols, ",\n  "), expr, col)
+	case BigQuery:
+		// BigQuery 只能按整数范围或时间列分区, 按 id 聚簇已足够裁剪
+		fmt.Fprintf(&b, "CREATE TABLE %s (\n  %s\n)\nCLUSTER BY %s;\n", t.Name, strings.Join(cols, ",\n  "), col)
+	}
+	return b.String(), nil
+}
+
+// monthBoundaries returns the first instants of every month from the month
+// of from up to and including the month after to, in UTC.
+func monthBoundaries(from, to time.Time) []time.Time {
+	if from.IsZero() || to.IsZero() || !to.After(from) {
+		return nil
+	}
+	from = from.UTC()
+	m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var out []time.Time
+	for ; !m.After(to); m = m.AddDate(0, 1, 0) {
+		out = append(out, m)
+	}
+	return append(out, m)
+}
+
+func unknown(d Dialect) error {
+	return errors.New(fmt.Sprintf("sqlschema: unknown dialect %q", d))
+}
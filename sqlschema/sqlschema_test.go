@@ -0,0 +1,56 @@
+package sqlschema
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestIDAt(t *testing.T) {
+	w, _ := snowflake.NewIdWorker(1)
+	before := time.Now().Add(-time.Second)
+	id, _ := w.NextId()
+	after := time.Now().Add(time.Second)
+	l := snowflake.DefaultLayout
+	if lo, hi := IDAt(l, before), IDAt(l, after); int64(id) < lo || int64(id) >= hi {
+		t.Errorf("id %d outside [%d, %d)", id, lo, hi)
+	}
+	if IDAt(l, time.Unix(0, 0)) != 0 {
+		t.Error("times before the epoch should map to 0")
+	}
+}
+
+func TestDDL(t *testing.T) {
+	tab := Table{
+		Name:    "orders",
+		Columns: []string{"amount BIGINT NOT NULL"},
+		From:    time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	jan := IDAt(snowflake.DefaultLayout, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	feb := IDAt(snowflake.DefaultLayout, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	for _, c := range []struct {
+		d    Dialect
+		want []string
+	}{
+		{MySQL, []string{"PARTITION BY RANGE (id)", "PARTITION p202601 VALUES LESS THAN (" + strconv.FormatInt(feb, 10) + ")", "p202603", "MAXVALUE"}},
+		{Postgres, []string{"PARTITION BY RANGE (id);", "orders_p202601 PARTITION OF orders FOR VALUES FROM (" + strconv.FormatInt(jan, 10) + ") TO (" + strconv.FormatInt(feb, 10) + ")"}},
+		{ClickHouse, []string{"id Int64", "PARTITION BY toYYYYMM(fromUnixTimestamp64Milli(bitShiftRight(id, 24) + 1542944160000))", "ORDER BY id"}},
+	} {
+		ddl, err := tab.DDL(c.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range c.want {
+			if !strings.Contains(ddl, w) {
+				t.Errorf("%s: missing %q in\n%s", c.d, w, ddl)
+			}
+		}
+	}
+	if _, err := tab.DDL("oracle"); err == nil {
+		t.Error("unknown dialect should fail")
+	}
+}
@@ -0,0 +1,59 @@
+package sqlschema
+
+import (
+	"fmt"
+	"strings"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// UDFs returns CREATE FUNCTION statements decoding ids of layout l inside
+// the warehouse, so queries agree with the Go decoder. Each function takes
+// an id and is named prefix followed by Time, Node, District, Tag or
+// Sequence; prefix may carry a dataset or database qualifier.
+func UDFs(d Dialect, prefix string, l snowflake.Layout) (string, error) {
+	if prefix == "" {
+		prefix = "snowflake"
+	}
+	seq := l.SequenceBits
+	fields := []struct {
+		name        string
+		shift, bits uint
+	}{
+		{"Node", seq, l.NodeBits},
+		{"District", seq + l.NodeBits, l.DistrictBits},
+		{"Tag", seq + l.NodeBits + l.DistrictBits, l.TagBits},
+		{"Sequence", 0, seq},
+	}
+
+	var b strings.Builder
+	switch d {
+	case ClickHouse:
+		expr, _ := TimeExpr(d, "id", l)
+		fmt.Fprintf(&b, "CREATE OR REPLACE FUNCTION %sTime AS (id) -> %s;\n", prefix, expr)
+		for _, f := range fields {
+			if f.bits == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "CREATE OR REPLACE FUNCTION %s%s AS (id) -> bitAnd(bitShiftRight(id, %d), %d);\n",
+				prefix, f.name, f.shift, mask(f.bits))
+		}
+	case BigQuery:
+		expr, _ := TimeExpr(d, "id", l)
+		fmt.Fprintf(&b, "CREATE OR REPLACE FUNCTION %sTime(id INT64) RETURNS TIMESTAMP AS (%s);\n", prefix, expr)
+		for _, f := range fields {
+			if f.bits == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "CREATE OR REPLACE FUNCTION %s%s(id INT64) RETURNS INT64 AS ((id >> %d) & %d);\n",
+				prefix, f.name, f.shift, mask(f.bits))
+		}
+	default:
+		return "", unknown(d)
+	}
+	return b.String(), nil
+}
+
+func mask(bits uint) int64 {
+	return -1 ^ (-1 << bits)
+}
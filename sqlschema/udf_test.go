@@ -0,0 +1,39 @@
+package sqlschema
+
+import (
+	"strings"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestUDFs(t *testing.T) {
+	l := snowflake.TaggedLayout
+	ch, err := UDFs(ClickHouse, "", l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{
+		"FUNCTION snowflakeTime AS (id) -> fromUnixTimestamp64Milli(bitShiftRight(id, 24) + 1542944160000);",
+		"FUNCTION snowflakeNode AS (id) -> bitAnd(bitShiftRight(id, 10), 511);",
+		"FUNCTION snowflakeTag AS (id) -> bitAnd(bitShiftRight(id, 22), 3);",
+	} {
+		if !strings.Contains(ch, w) {
+			t.Errorf("clickhouse: missing %q in\n%s", w, ch)
+		}
+	}
+
+	bq, err := UDFs(BigQuery, "ds.sf_", snowflake.LegacyLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(bq, "sf_Tag") {
+		t.Error("layouts without tag bits should not get a tag function")
+	}
+	if w := "FUNCTION ds.sf_District(id INT64) RETURNS INT64 AS ((id >> 19) & 31);"; !strings.Contains(bq, w) {
+		t.Errorf("bigquery: missing %q in\n%s", w, bq)
+	}
+	if _, err := UDFs(MySQL, "", l); err == nil {
+		t.Error("mysql should not be supported")
+	}
+}
@@ -0,0 +1,178 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Coordinator is the shared backend a primary worker heartbeats to and a
+// warm standby watches.
+type Coordinator interface {
+	// Heartbeat records that the primary of nodeId is alive and has used
+	// timestamps up to highWater.
+	Heartbeat(nodeId int64, highWater int64) error
+	// Status returns the time of the last heartbeat of nodeId and the
+	// high-water timestamp it carried.
+	Status(nodeId int64) (lastBeat time.Time, highWater int64, err error)
+}
+
+// heartbeatFreeze is the freeze reason of a worker whose heartbeats fail.
+const heartbeatFreeze = "heartbeats to the coordinator are failing"
+
+// StartHeartbeat reports worker's high-water timestamp to coord every
+// interval until ctx is done. Failed heartbeats are logged, and once they
+// have failed for failAfter the worker is frozen, so it stops issuing ids
+// before a standby that no longer sees them takes over its node; the next
+// heartbeat that succeeds unfreezes it. Set failAfter below the standby's
+// DeadAfter; 0 never freezes.
+func StartHeartbeat(ctx context.Context, worker *IdWorker, coord Coordinator, interval, failAfter time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var failing time.Time // 心跳开始失败的时间, 零值表示正常
+		for {
+			worker.Lock()
+			nodeId, hw := worker.nodeId, worker.lastTimestamp
+			worker.Unlock()
+			err := coord.Heartbeat(nodeId, hw)
+			worker.Lock()
+			switch {
+			case err != nil:
+				if failing.IsZero() {
+					failing = time.Now()
+				}
+				worker.log(slog.LevelWarn, "snowflake: heartbeat failed", "err", err)
+				if failAfter > 0 && time.Since(failing) >= failAfter && worker.frozen == nil {
+					worker.frozen = &FrozenError{Reason: heartbeatFreeze, Since: time.Now()}
+					worker.log(slog.LevelWarn, "snowflake: worker frozen", "reason", heartbeatFreeze)
+				}
+			case !failing.IsZero():
+				failing = time.Time{}
+				if worker.frozen != nil && worker.frozen.Reason == heartbeatFreeze {
+					worker.frozen = nil
+					worker.log(slog.LevelInfo, "snowflake: worker unfrozen")
+				}
+			}
+			worker.Unlock()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Standby is a warm backup of a primary worker. It mirrors the primary's
+// high-water timestamp and can take over the same node ID once the primary
+// is confirmed dead.
+type Standby struct {
+	NodeId    int64
+	Coord     Coordinator
+	DeadAfter time.Duration // 多久没有心跳视为主节点死亡
+	SkewGuard time.Duration // 接管前额外等待, 吸收两台机器的时钟偏差
+	Poll      time.Duration // 轮询间隔
+
+	mu        sync.Mutex
+	highWater int64
+}
+
+// HighWater returns the last high-water timestamp mirrored from the primary.
+func (s *Standby) HighWater() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.highWater
+}
+
+// Takeover watches the primary until it has missed heartbeats for
+// DeadAfter, waits SkewGuard and until the local clock is past the
+// primary's high-water mark, then returns a worker for the same node ID that
+// refuses to reuse any millisecond the primary may have used.
+func (s *Standby) Takeover(ctx context.Context, opts ...Option) (*IdWorker, error) {
+	poll := s.Poll
+	if poll <= 0 {
+		poll = s.DeadAfter / 4
+	}
+	if poll <= 0 {
+		return nil, errors.New("standby needs DeadAfter or Poll")
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		beat, hw, err := s.Coord.Status(s.NodeId)
+		if err == nil {
+			s.mu.Lock()
+			if hw > s.highWater {
+				s.highWater = hw
+			}
+			s.mu.Unlock()
+			if time.Since(beat) >= s.DeadAfter {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	// 主节点确认死亡: 再等一个时钟偏差的保护时间, 并且越过主节点用过的最大毫秒
+	hw := s.HighWater()
+	wait := s.SkewGuard
+	if ahead := time.Duration(hw-timeGen()) * time.Millisecond; ahead > wait {
+		wait = ahead
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(wait):
+	}
+
+	worker, err := NewIdWorker(s.NodeId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if hw > worker.lastTimestamp {
+		worker.lastTimestamp = hw
+		worker.sequence = -1
+	}
+	return worker, nil
+}
+
+// MemoryCoordinator is an in-process Coordinator, for tests and for
+// primaries and standbys sharing a process.
+type MemoryCoordinator struct {
+	sync.Mutex
+	beats map[int64]memoryBeat
+}
+
+type memoryBeat struct {
+	at        time.Time
+	highWater int64
+}
+
+// NewMemoryCoordinator new an in-process coordinator.
+func NewMemoryCoordinator() *MemoryCoordinator {
+	return &MemoryCoordinator{beats: make(map[int64]memoryBeat)}
+}
+
+func (m *MemoryCoordinator) Heartbeat(nodeId int64, highWater int64) error {
+	m.Lock()
+	defer m.Unlock()
+	m.beats[nodeId] = memoryBeat{at: time.Now(), highWater: highWater}
+	return nil
+}
+
+func (m *MemoryCoordinator) Status(nodeId int64) (time.Time, int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	b, ok := m.beats[nodeId]
+	if !ok {
+		return time.Time{}, 0, errors.New("no heartbeat for node")
+	}
+	return b.at, b.highWater, nil
+}
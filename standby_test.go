@@ -0,0 +1,101 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStandbyTakeover(t *testing.T) {
+	coord := NewMemoryCoordinator()
+	primary, _ := NewIdWorker(20)
+	last, _ := primary.NextId()
+
+	ctx, stopPrimary := context.WithCancel(context.Background())
+	StartHeartbeat(ctx, primary, coord, 5*time.Millisecond, 0)
+
+	standby := &Standby{NodeId: 20, Coord: coord, DeadAfter: 50 * time.Millisecond, SkewGuard: 10 * time.Millisecond}
+	done := make(chan *IdWorker)
+	go func() {
+		w, err := standby.Takeover(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- w
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("standby took over while the primary was alive")
+	case <-time.After(100 * time.Millisecond):
+	}
+	stopPrimary()
+
+	select {
+	case w := <-done:
+		id, err := w.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last || id.NodeId() != 20 {
+			t.Errorf("takeover id %d not after primary's %d", id, last)
+		}
+		if standby.HighWater() < last.timestamp() {
+			t.Errorf("high water %d behind primary %d", standby.HighWater(), last.timestamp())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("standby did not take over")
+	}
+}
+
+// flakyCoordinator fails heartbeats while down is set.
+type flakyCoordinator struct {
+	*MemoryCoordinator
+	down  atomic.Bool
+	nodes sync.Map // 收到心跳的节点
+}
+
+func (c *flakyCoordinator) Heartbeat(nodeId int64, highWater int64) error {
+	c.nodes.Store(nodeId, true)
+	if c.down.Load() {
+		return errors.New("coordinator unreachable")
+	}
+	return c.MemoryCoordinator.Heartbeat(nodeId, highWater)
+}
+
+func TestHeartbeatFailure(t *testing.T) {
+	coord := &flakyCoordinator{MemoryCoordinator: NewMemoryCoordinator()}
+	coord.down.Store(true)
+	primary, _ := NewIdWorker(20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	StartHeartbeat(ctx, primary, coord, 5*time.Millisecond, 20*time.Millisecond)
+
+	waitFor := func(cond func() bool) bool {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			if cond() {
+				return true
+			}
+		}
+		return false
+	}
+	if !waitFor(func() bool { return primary.Frozen() != nil }) {
+		t.Fatal("worker not frozen after failed heartbeats")
+	}
+	if _, err := primary.NextId(); !errors.Is(err, ErrFrozen) {
+		t.Errorf("frozen worker issued an id: %v", err)
+	}
+	if _, err := primary.RotateNode(NewMemoryNodeAllocator(21, 21)); err != nil {
+		t.Fatal(err)
+	}
+	coord.down.Store(false)
+	if !waitFor(func() bool { return primary.Frozen() == nil }) {
+		t.Fatal("worker not unfrozen after a heartbeat succeeded")
+	}
+	if _, ok := coord.nodes.Load(int64(21)); !ok {
+		t.Error("heartbeat did not follow the rotated node")
+	}
+}
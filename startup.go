@@ -0,0 +1,109 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Severity ranks the findings of a StartupReport.
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota // 仅供参考
+	SeverityWarning                 // 可运行, 但建议处理
+	SeveritySevere                  // 可能生成重复或无效的 ID
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeveritySevere:
+		return "severe"
+	}
+	return "info"
+}
+
+// Finding is one result of a StartupReport.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// StartupReport describes how far a worker can trust its clock.
+type StartupReport struct {
+	Granularity   time.Duration `json:"granularity"`    // 墙上时钟的最小步长
+	WallStep      time.Duration `json:"wall_step"`      // 进程启动以来墙上时钟相对单调时钟的跳变
+	Epoch         int64         `json:"epoch"`          // unix 毫秒
+	RemainingDays int64         `json:"remaining_days"` // 时间戳溢出前的天数
+	Findings      []Finding     `json:"findings"`
+}
+
+// Severe reports whether any finding is severe.
+func (r StartupReport) Severe() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeveritySevere {
+			return true
+		}
+	}
+	return false
+}
+
+// processStart has both a wall and a monotonic reading, to detect steps of
+// the wall clock since the process started.
+var processStart = time.Now()
+
+// StartupReport measures the clock the worker depends on and checks its
+// configuration against it: the clock's resolution, steps of the wall
+// clock since the process started (an NTP step is only detectable when it
+// happens after that), the epoch and the years left before the timestamp
+// overflows, and a saved high-water timestamp ahead of the clock.
+func (id *IdWorker) StartupReport() StartupReport {
+	id.Lock()
+	l := id.currentLayout()
+	last := id.lastTimestamp
+	clock := id.clock
+	id.Unlock()
+
+	r := StartupReport{Granularity: ClockGranularity(), Epoch: l.Epoch}
+	add := func(check string, s Severity, format string, args ...interface{}) {
+		r.Findings = append(r.Findings, Finding{Check: check, Severity: s, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if r.Granularity > time.Millisecond {
+		add("resolution", SeverityWarning, "wall clock steps by %s, coarser than the 1ms of ids; consider WithClockCompensation", r.Granularity)
+	} else {
+		add("resolution", SeverityInfo, "wall clock steps by %s", r.Granularity)
+	}
+
+	now := time.Now()
+	r.WallStep = now.Round(0).Sub(processStart.Round(0)) - now.Sub(processStart)
+	if step := r.WallStep; step < -time.Millisecond {
+		add("wall-step", SeveritySevere, "wall clock stepped back %s since the process started", -step)
+	} else if step > time.Second {
+		add("wall-step", SeverityWarning, "wall clock stepped forward %s since the process started", step)
+	}
+
+	ms := now.UnixMilli()
+	switch {
+	case l.Epoch > ms:
+		add("epoch", SeveritySevere, "epoch %s is in the future", time.UnixMilli(l.Epoch).UTC().Format(time.RFC3339))
+	case l.Epoch < time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli():
+		add("epoch", SeverityWarning, "epoch %s wastes timestamp range", time.UnixMilli(l.Epoch).UTC().Format(time.RFC3339))
+	}
+	r.RemainingDays = (l.Epoch + l.MaxTimestamp()/l.tick() - ms) / (24 * 3600 * 1000)
+	switch {
+	case r.RemainingDays < 0:
+		add("overflow", SeveritySevere, "timestamp field overflowed %d days ago", -r.RemainingDays)
+	case r.RemainingDays < 365:
+		add("overflow", SeveritySevere, "timestamp field overflows in %d days", r.RemainingDays)
+	case r.RemainingDays < 5*365:
+		add("overflow", SeverityWarning, "timestamp field overflows in %d days", r.RemainingDays)
+	}
+
+	if cur := clock.Millis(); last > cur {
+		add("high-water", SeveritySevere, "saved timestamp is %dms ahead of the clock; ids will fail until it catches up", (last-cur)/l.tick())
+	}
+	return r
+}
@@ -0,0 +1,32 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartupReport(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	r := idworker.StartupReport()
+	if r.Severe() || r.Granularity <= 0 || r.Epoch != twepoch || r.RemainingDays < 5*365 {
+		t.Errorf("healthy worker: %+v", r)
+	}
+
+	future := DefaultLayout
+	future.Epoch = time.Now().Add(time.Hour).UnixMilli()
+	idworker, _ = NewIdWorker(1, WithLayout(future))
+	if r := idworker.StartupReport(); !r.Severe() {
+		t.Errorf("future epoch should be severe: %+v", r.Findings)
+	}
+
+	idworker, _ = NewIdWorker(1)
+	idworker.lastTimestamp = timeGen() + 5000
+	r = idworker.StartupReport()
+	found := false
+	for _, f := range r.Findings {
+		found = found || (f.Check == "high-water" && f.Severity == SeveritySevere)
+	}
+	if !found {
+		t.Errorf("high-water ahead of the clock should be severe: %+v", r.Findings)
+	}
+}
@@ -0,0 +1,21 @@
+package snowflake
+
+// WorkerStats counts what a worker has done since it was created.
+type WorkerStats struct {
+	Generated int64 `json:"generated"` // 已生成的 ID 数
+	Errors    int64 `json:"errors"`    // 生成失败次数
+	Exhausted int64 `json:"exhausted"` // 毫秒内序号用尽的次数
+}
+
+// Stats returns the counters of the worker.
+func (id *IdWorker) Stats() WorkerStats {
+	id.Lock()
+	defer id.Unlock()
+	return id.stats
+}
+
+func (s *WorkerStats) add(o WorkerStats) {
+	s.Generated += o.Generated
+	s.Errors += o.Errors
+	s.Exhausted += o.Exhausted
+}
@@ -0,0 +1,28 @@
+package snowflake
+
+// TimestampStore persists the high-water timestamp of a worker, so a
+// restarted process refuses to issue ids for milliseconds it may already
+// have used before a clock rollback.
+type TimestampStore interface {
+	// Load returns the last saved timestamp, or -1 if nothing was saved.
+	Load() (int64, error)
+	// Save records timestamp as the new high-water mark.
+	Save(timestamp int64) error
+}
+
+// WithStore makes the worker resume from the timestamp saved in store and
+// record every new millisecond to it.
+func WithStore(store TimestampStore) Option {
+	return func(id *IdWorker) error {
+		last, err := store.Load()
+		if err != nil {
+			return err
+		}
+		if last > id.lastTimestamp {
+			id.lastTimestamp = last
+			id.sequence = -1 // 续用上次的毫秒时, 从下一毫秒开始
+		}
+		id.store = store
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+//go:build unix
+
+package snowflake
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+const mmapStoreSize = 8 // 一个 int64 时间戳
+
+// MmapStore is a TimestampStore backed by a memory-mapped file. Save is a
+// single atomic store into the shared mapping, the kernel writes the page
+// back to disk, so recording every millisecond costs almost nothing.
+type MmapStore struct {
+	file *os.File
+	data []byte
+}
+
+// NewMmapStore opens (or creates) the file at path and maps it into memory.
+func NewMmapStore(path string) (*MmapStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fresh := fi.Size() < mmapStoreSize
+	if fresh {
+		if err := f.Truncate(mmapStoreSize); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, mmapStoreSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &MmapStore{file: f, data: data}
+	if fresh {
+		s.Save(-1)
+	}
+	return s, nil
+}
+
+func (s *MmapStore) word() *int64 {
+	return (*int64)(unsafe.Pointer(&s.data[0]))
+}
+
+// Load returns the timestamp recorded in the mapped file.
+func (s *MmapStore) Load() (int64, error) {
+	return atomic.LoadInt64(s.word()), nil
+}
+
+// Save records timestamp in the mapped file.
+func (s *MmapStore) Save(timestamp int64) error {
+	atomic.StoreInt64(s.word(), timestamp)
+	return nil
+}
+
+// Close releases the mapping and flushes the file to disk.
+func (s *MmapStore) Close() error {
+	// 映射的页就是文件的页缓存, 解除映射后 fsync 即可落盘
+	if err := syscall.Munmap(s.data); err != nil {
+		s.file.Close()
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
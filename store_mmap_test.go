@@ -0,0 +1,54 @@
+//go:build unix
+
+package snowflake
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMmapStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snowflake.ts")
+	store, err := NewMmapStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idworker, err := NewIdWorker(1, WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idworker.NextId(); err != nil {
+		t.Fatal(err)
+	}
+	last := idworker.lastTimestamp
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewMmapStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if ts, _ := store.Load(); ts != last {
+		t.Errorf("load: got %d, want %d", ts, last)
+	}
+	idworker, err = NewIdWorker(1, WithStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idworker.lastTimestamp != last {
+		t.Errorf("resume: got %d, want %d", idworker.lastTimestamp, last)
+	}
+}
+
+func TestMmapStoreFresh(t *testing.T) {
+	store, err := NewMmapStore(filepath.Join(t.TempDir(), "snowflake.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if ts, _ := store.Load(); ts != -1 {
+		t.Errorf("fresh store: got %d, want -1", ts)
+	}
+}
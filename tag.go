@@ -0,0 +1,106 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Tag is the type tag embedded in an ID, so ids issued by the same
+// generator fleet for different entities are distinguishable on sight.
+type Tag int64
+
+const (
+	TagNone    Tag = iota // 未打标签, 与旧版 ID 兼容
+	TagOrder              // 订单 ID
+	TagUser               // 用户 ID
+	TagMessage            // 消息 ID
+)
+
+// String returns the name of the tag.
+func (t Tag) String() string {
+	tagRegistry.RLock()
+	defer tagRegistry.RUnlock()
+	if name, ok := tagRegistry.names[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("tag(%d)", int64(t))
+}
+
+// tagRegistry maps tags to entity names, so that two services cannot give
+// one tag two meanings within a process.
+var tagRegistry = struct {
+	sync.RWMutex
+	names map[Tag]string
+	tags  map[string]Tag
+}{
+	names: map[Tag]string{TagNone: "none", TagOrder: "order", TagUser: "user", TagMessage: "message"},
+	tags:  map[string]Tag{"none": TagNone, "order": TagOrder, "user": TagUser, "message": TagMessage},
+}
+
+// RegisterTag claims tag for the entity name. The tag must fit in the tag
+// bits of TaggedLayout. Registering the same pair twice is allowed; giving
+// a tag a second name or a name a second tag fails.
+func RegisterTag(name string, tag Tag) error {
+	if tag <= TagNone || tag > maxTag {
+		return errors.New(fmt.Sprintf("tag of %q must be between 1 and %d", name, maxTag))
+	}
+	tagRegistry.Lock()
+	defer tagRegistry.Unlock()
+	if other, ok := tagRegistry.names[tag]; ok && other != name {
+		return errors.New(fmt.Sprintf("tag %d claimed by both %q and %q", int64(tag), other, name))
+	}
+	if other, ok := tagRegistry.tags[name]; ok && other != tag {
+		return errors.New(fmt.Sprintf("%q registered with both tag %d and %d", name, int64(other), int64(tag)))
+	}
+	tagRegistry.names[tag] = name
+	tagRegistry.tags[name] = tag
+	return nil
+}
+
+// MustRegisterTag is RegisterTag panicking on a collision, for the init
+// functions generated by "snowflake tags", so a binary linking two services
+// that claim the same tag fails at startup.
+func MustRegisterTag(name string, tag Tag) Tag {
+	if err := RegisterTag(name, tag); err != nil {
+		panic(err)
+	}
+	return tag
+}
+
+// LookupTag returns the tag registered for name.
+func LookupTag(name string) (Tag, bool) {
+	tagRegistry.RLock()
+	defer tagRegistry.RUnlock()
+	tag, ok := tagRegistry.tags[name]
+	return tag, ok
+}
+
+// NewTaggedIdWorker new a snowflake id generator object whose ids carry tag,
+// in TaggedLayout: decode them with ID.WithLayout(TaggedLayout).
+func NewTaggedIdWorker(NodeId int64, tag Tag) (*IdWorker, error) {
+	if tag > maxTag || tag < 0 {
+		return nil, errors.New(fmt.Sprintf("tag must be between 0 and %d", maxTag))
+	}
+	id, err := NewIdWorker(NodeId, WithLayout(TaggedLayout))
+	if err != nil {
+		return nil, err
+	}
+	id.tag = int64(tag)
+	return id, nil
+}
+
+// NewOrderIdWorker new a snowflake id generator object issuing order ids.
+func NewOrderIdWorker(NodeId int64) (*IdWorker, error) {
+	return NewTaggedIdWorker(NodeId, TagOrder)
+}
+
+// NewUserIdWorker new a snowflake id generator object issuing user ids.
+func NewUserIdWorker(NodeId int64) (*IdWorker, error) {
+	return NewTaggedIdWorker(NodeId, TagUser)
+}
+
+// NewMessageIdWorker new a snowflake id generator object issuing message ids.
+func NewMessageIdWorker(NodeId int64) (*IdWorker, error) {
+	return NewTaggedIdWorker(NodeId, TagMessage)
+}
@@ -0,0 +1,68 @@
+package snowflake
+
+import (
+	"testing"
+)
+
+func TestTaggedIdWorker(t *testing.T) {
+	idworker, err := NewUserIdWorker(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := idworker.NextId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := id.WithLayout(TaggedLayout)
+	if v.Tag() != TagUser {
+		t.Errorf("tag: got %s, want %s", v.Tag(), TagUser)
+	}
+	if v.NodeId() != 10 || v.DistrictId() != 1 {
+		t.Errorf("node/district: got %d/%d", v.NodeId(), v.DistrictId())
+	}
+	if idworker.Layout() != TaggedLayout {
+		t.Errorf("layout: got %s, want %s", idworker.Layout(), TaggedLayout)
+	}
+}
+
+func TestTaggedIdWorkerFail(t *testing.T) {
+	if _, err := NewTaggedIdWorker(1, Tag(maxTag+1)); err == nil {
+		t.Error("tag out of range should fail")
+	}
+}
+
+func TestUntaggedId(t *testing.T) {
+	idworker, _ := NewIdWorker(1)
+	id, _ := idworker.NextId()
+	if tag := id.WithLayout(DefaultLayout).Tag(); tag != TagNone || DefaultLayout.TagBits != 0 {
+		t.Errorf("tag: got %s, want %s", tag, TagNone)
+	}
+	// 默认布局的区域仍为 5 位
+	f, err := idworker.NextIdForDistrict(maxDistrictId)
+	if err != nil || f.DistrictId() != 31 {
+		t.Errorf("district 31: got %d, %v", f.DistrictId(), err)
+	}
+}
+
+func TestRegisterTag(t *testing.T) {
+	if err := RegisterTag("order", TagOrder); err != nil {
+		t.Errorf("re-registering the same pair: %v", err)
+	}
+	if err := RegisterTag("billing", TagOrder); err == nil {
+		t.Error("second name for a tag should fail")
+	}
+	if err := RegisterTag("order", TagUser); err == nil {
+		t.Error("second tag for a name should fail")
+	}
+	for _, tag := range []Tag{TagNone, Tag(maxTag + 1), 9} {
+		if err := RegisterTag("invoice", tag); err == nil {
+			t.Errorf("tag %d out of range should fail", tag)
+		}
+	}
+	if tag, ok := LookupTag("user"); !ok || tag != TagUser || tag.String() != "user" {
+		t.Errorf("lookup: got %v, %v", tag, ok)
+	}
+	if s := Tag(11).String(); s != "tag(11)" {
+		t.Errorf("unregistered tag: got %q", s)
+	}
+}
@@ -0,0 +1,15 @@
+package snowflake
+
+// WithOnTick calls fn whenever the millisecond of the worker advances, just
+// before the first id of the new millisecond is issued, with the previous
+// millisecond (-1 for the first id) and the new one. It lets writers that
+// bucket by id time flush exactly at bucket boundaries.
+//
+// fn runs with the worker locked: it must be quick and must not call the
+// worker.
+func WithOnTick(fn func(prev, now int64)) Option {
+	return func(id *IdWorker) error {
+		id.onTick = fn
+		return nil
+	}
+}
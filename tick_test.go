@@ -0,0 +1,26 @@
+package snowflake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnTick(t *testing.T) {
+	var ticks [][2]int64
+	w, _ := NewIdWorker(1, WithOnTick(func(prev, now int64) {
+		ticks = append(ticks, [2]int64{prev, now})
+	}))
+	at := time.UnixMilli(1600000000000)
+	w.NextIdAt(at)
+	w.NextIdAt(at)
+	w.NextIdAt(at.Add(3 * time.Millisecond))
+	want := [][2]int64{{-1, 1600000000000}, {1600000000000, 1600000000003}}
+	if len(ticks) != len(want) {
+		t.Fatalf("got %v, want %v", ticks, want)
+	}
+	for i := range want {
+		if ticks[i] != want[i] {
+			t.Errorf("tick %d: got %v, want %v", i, ticks[i], want[i])
+		}
+	}
+}
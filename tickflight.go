@@ -0,0 +1,80 @@
+package snowflake
+
+import (
+	"sync"
+)
+
+// tickFlight lets one goroutine spin for the next millisecond while the
+// others waiting past the same millisecond sleep until it is done.
+type tickFlight struct {
+	mu      sync.Mutex
+	waiting map[int64]chan struct{} // 按等待越过的毫秒, 完成后关闭
+}
+
+// processTicks is shared by the workers reading the system clock.
+var processTicks = &tickFlight{}
+
+// wait returns once clock is past last. Only the first caller for last
+// spins; later callers block until it has seen the tick.
+func (f *tickFlight) wait(clock Clock, last int64) int64 {
+	f.mu.Lock()
+	if done, ok := f.waiting[last]; ok {
+		f.mu.Unlock()
+		<-done
+		return tilNextMillis(clock, last)
+	}
+	if f.waiting == nil {
+		f.waiting = make(map[int64]chan struct{})
+	}
+	done := make(chan struct{})
+	f.waiting[last] = done
+	f.mu.Unlock()
+
+	timestamp := tilNextMillis(clock, last)
+	f.mu.Lock()
+	delete(f.waiting, last)
+	f.mu.Unlock()
+	close(done)
+	return timestamp
+}
+
+// WithExhaustionSingleflight makes goroutines that exhaust the sequence in
+// the same millisecond wait for the next one together: one spins on the
+// clock and wakes the rest, instead of every one of them burning a CPU.
+// Workers reading the system clock share one waiter across the process.
+// The lock is released while waiting, so a batch may interleave with other
+// callers at millisecond boundaries, as with WithFairBatching.
+func WithExhaustionSingleflight() Option {
+	return func(id *IdWorker) error {
+		id.ticks = &tickFlight{}
+		return nil
+	}
+}
+
+// tilNextMillis waits for the millisecond after last, holding the lock. In
+// counter mode it moves the counter on.
+func (id *IdWorker) tilNextMillis(last int64) int64 {
+	if c, ok := id.clock.(*counterClock); ok {
+		return c.advance(last)
+	}
+	return tilNextMillis(id.clock, last)
+}
+
+// awaitTick waits for the millisecond after last through the worker's
+// singleflight, with the lock released so the worker's other goroutines
+// exhausting the same millisecond join the wait instead of spinning in
+// turn. It reports false at once without a singleflight or in counter mode.
+// Called with the lock held; after true the caller rechecks the worker.
+func (id *IdWorker) awaitTick(last int64) bool {
+	if id.ticks == nil {
+		return false
+	}
+	if _, ok := id.clock.(*counterClock); ok {
+		return false
+	}
+	clock := id.clock
+	id.Unlock()
+	id.ticks.wait(clock, last)
+	id.Lock()
+	return true
+}
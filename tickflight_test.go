@@ -0,0 +1,158 @@
+package snowflake
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gateClock stays at base until opened, counting its reads.
+type gateClock struct {
+	base  int64
+	open  atomic.Bool
+	reads atomic.Int64
+}
+
+func (c *gateClock) Millis() int64 {
+	c.reads.Add(1)
+	if c.open.Load() {
+		return c.base + 1
+	}
+	return c.base
+}
+
+func TestTickFlight(t *testing.T) {
+	var f tickFlight
+	clock := &gateClock{base: 1000}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ts := f.wait(clock, 1000); ts != 1001 {
+				t.Errorf("got %d, want 1001", ts)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	f.mu.Lock()
+	if len(f.waiting) != 1 {
+		t.Errorf("%d waits in flight, want 1", len(f.waiting))
+	}
+	f.mu.Unlock()
+	clock.open.Store(true)
+	wg.Wait()
+	if len(f.waiting) != 0 {
+		t.Errorf("finished wait left behind")
+	}
+}
+
+func TestExhaustionSingleflight(t *testing.T) {
+	idworker, _ := NewIdWorker(1, WithExhaustionSingleflight(), WithFairBatching())
+	if idworker.ticks != processTicks {
+		t.Error("system clock workers should share the process waiter")
+	}
+	var mu sync.Mutex
+	seen := make(map[ID]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				ids, err := idworker.NextIds(maxNextIdsNum)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				for _, f := range ids {
+					if seen[f] {
+						t.Errorf("duplicate id %d", f)
+					}
+					seen[f] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	other, _ := NewIdWorker(1, WithExhaustionSingleflight(), WithClock(&gateClock{}))
+	if other.ticks == processTicks {
+		t.Error("custom clock workers should not share the process waiter")
+	}
+}
+
+// spinClock stays at base until opened and records which goroutines read
+// it, to count the goroutines spinning on it.
+type spinClock struct {
+	base    int64
+	open    atomic.Bool
+	mu      sync.Mutex
+	readers map[string]bool
+}
+
+func (c *spinClock) Millis() int64 {
+	if c.open.Load() {
+		return c.base + 1
+	}
+	buf := make([]byte, 32)
+	buf = buf[:runtime.Stack(buf, false)]
+	gid := string(bytes.Fields(buf)[1]) // "goroutine N [..."
+	c.mu.Lock()
+	c.readers[gid] = true
+	c.mu.Unlock()
+	return c.base
+}
+
+// spinners exhausts the millisecond of a worker built with opts, starts 8
+// goroutines asking for ids and returns how many of them spin on the clock.
+func spinners(t *testing.T, opts ...Option) int {
+	clock := &spinClock{base: twepoch + 1000, readers: make(map[string]bool)}
+	idworker, _ := NewIdWorker(1, append([]Option{WithClock(clock)}, opts...)...)
+	idworker.Lock()
+	for {
+		if _, err := idworker.nextidAt(clock.base, false); err != nil {
+			break
+		}
+	}
+	idworker.Unlock()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := idworker.NextIds(1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	clock.mu.Lock()
+	clock.readers = make(map[string]bool) // 只计已进入等待后的读取
+	clock.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	clock.mu.Lock()
+	n := len(clock.readers)
+	clock.mu.Unlock()
+	clock.open.Store(true)
+	wg.Wait()
+	return n
+}
+
+func TestExhaustionSingleflightSpinners(t *testing.T) {
+	if n := spinners(t, WithFairBatching()); n < 2 {
+		t.Skipf("only %d goroutine spinning without singleflight", n)
+	}
+	if n := spinners(t, WithFairBatching(), WithExhaustionSingleflight()); n != 1 {
+		t.Errorf("%d goroutines spinning with a fair batching singleflight, want 1", n)
+	}
+	if n := spinners(t, WithExhaustionSingleflight()); n != 1 {
+		t.Errorf("%d goroutines spinning with a singleflight, want 1", n)
+	}
+}
@@ -0,0 +1,52 @@
+package snowflake
+
+// Typed is an id of the entity kind K. K is a marker type that only exists
+// to make ids of different entities distinct Go types:
+//
+//	type userKind struct{}
+//	type UserID = snowflake.Typed[userKind]
+//
+// A UserID cannot be passed where an OrderID is expected, yet both decode
+// through the same ID methods and encode to JSON like ID.
+type Typed[K any] ID
+
+// NextTyped get a snowflake id of kind K from worker.
+func NextTyped[K any](worker *IdWorker) (Typed[K], error) {
+	id, err := worker.NextId()
+	return Typed[K](id), err
+}
+
+// ID returns the untyped id.
+func (t Typed[K]) ID() ID {
+	return ID(t)
+}
+
+func (t Typed[K]) Time() int64 {
+	return ID(t).Time()
+}
+
+func (t Typed[K]) NodeId() int64 {
+	return ID(t).NodeId()
+}
+
+func (t Typed[K]) DistrictId() int64 {
+	return ID(t).DistrictId()
+}
+
+func (t Typed[K]) Int64() int64 {
+	return int64(t)
+}
+
+func (t Typed[K]) String() string {
+	return ID(t).String()
+}
+
+// MarshalJSON encodes t like its ID, so it follows StringIDs.
+func (t Typed[K]) MarshalJSON() ([]byte, error) {
+	return ID(t).MarshalJSON()
+}
+
+// UnmarshalJSON accepts what ID does.
+func (t *Typed[K]) UnmarshalJSON(b []byte) error {
+	return (*ID)(t).UnmarshalJSON(b)
+}
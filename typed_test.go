@@ -0,0 +1,49 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type userKind struct{}
+type orderKind struct{}
+
+type UserID = Typed[userKind]
+type OrderID = Typed[orderKind]
+
+func TestTyped(t *testing.T) {
+	idworker, _ := NewUserIdWorker(2)
+	uid, err := NextTyped[userKind](idworker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ UserID = uid
+	if uid.ID().WithLayout(TaggedLayout).Tag() != TagUser || uid.NodeId() != 2 || uid.String() != uid.ID().String() {
+		t.Errorf("typed id %d decodes wrong", uid)
+	}
+	oid := OrderID(uid.ID())
+	if oid.Int64() != uid.Int64() {
+		t.Error("conversion through ID should keep the value")
+	}
+}
+
+func TestTypedJSON(t *testing.T) {
+	StringIDs = true
+	defer func() { StringIDs = false }()
+	in := struct {
+		User UserID `json:"user"`
+	}{User: UserID(2703196044657165313)}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"user":"2703196044657165313"}` {
+		t.Errorf("marshal: got %s", b)
+	}
+	var out struct {
+		User UserID `json:"user"`
+	}
+	if err := json.Unmarshal(b, &out); err != nil || out.User != in.User {
+		t.Errorf("round trip: got %d, %v", out.User, err)
+	}
+}
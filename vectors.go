@@ -0,0 +1,72 @@
+package snowflake
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"strconv"
+)
+
+// TestVector is one id with the fields it was built from and its
+// encodings, for checking implementations in other languages against this
+// one byte for byte.
+type TestVector struct {
+	Timestamp  int64  `json:"timestamp"` // unix 毫秒, 微秒布局下为微秒
+	Tag        int64  `json:"tag"`
+	DistrictId int64  `json:"district_id"`
+	NodeId     int64  `json:"node_id"`
+	Sequence   int64  `json:"sequence"`
+	ID         string `json:"id"`        // 十进制
+	Hex        string `json:"hex"`       // 原始 8 字节, 大端
+	Base64URL  string `json:"base64url"` // 原始 8 字节, 无填充
+	Base64     string `json:"base64"`    // 十进制字符串的 base64, 旧编码
+}
+
+// TestVectorFile is a layout with test vectors, as written to disk.
+type TestVectorFile struct {
+	Layout  Layout       `json:"layout"`
+	Vectors []TestVector `json:"vectors"`
+}
+
+// GenerateTestVectors returns the edge cases of l (smallest and largest
+// value of every field) followed by n random vectors drawn from seed, so
+// the same arguments always give the same file.
+func GenerateTestVectors(l Layout, n int, seed int64) TestVectorFile {
+	r := rand.New(rand.NewSource(seed))
+	epoch := l.Epoch * l.tick()
+	maxTs := epoch + l.MaxTimestamp()
+	edges := [][5]int64{
+		{epoch, 0, 0, 0, 0},
+		{epoch, 0, 0, 0, 1},
+		{maxTs, l.MaxTag(), l.MaxDistrictId(), l.MaxNodeId(), l.MaxSequence()},
+		{epoch + 1, l.MaxTag(), 0, 0, 0},
+		{epoch + 1, 0, l.MaxDistrictId(), 0, 0},
+		{epoch + 1, 0, 0, l.MaxNodeId(), 0},
+		{epoch + 1, 0, 0, 0, l.MaxSequence()},
+	}
+	for i := 0; i < n; i++ {
+		edges = append(edges, [5]int64{
+			epoch + r.Int63n(l.MaxTimestamp()+1),
+			r.Int63n(l.MaxTag() + 1),
+			r.Int63n(l.MaxDistrictId() + 1),
+			r.Int63n(l.MaxNodeId() + 1),
+			r.Int63n(l.MaxSequence() + 1),
+		})
+	}
+	f := TestVectorFile{Layout: l}
+	for _, e := range edges {
+		id := ID(((e[0] - epoch) << l.timestampShift()) | (e[1] << l.tagShift()) | (e[2] << l.districtShift()) | (e[3] << l.nodeShift()) | e[4])
+		b := id.IntBytes()
+		f.Vectors = append(f.Vectors, TestVector{
+			Timestamp:  e[0],
+			Tag:        e[1],
+			DistrictId: e[2],
+			NodeId:     e[3],
+			Sequence:   e[4],
+			ID:         strconv.FormatInt(int64(id), 10),
+			Hex:        hex.EncodeToString(b[:]),
+			Base64URL:  id.Base64URL(),
+			Base64:     id.Base64(),
+		})
+	}
+	return f
+}
@@ -0,0 +1,31 @@
+package snowflake
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestGenerateTestVectors(t *testing.T) {
+	f := GenerateTestVectors(DefaultLayout, 20, 1)
+	if len(f.Vectors) != 27 {
+		t.Fatalf("got %d vectors", len(f.Vectors))
+	}
+	if !reflect.DeepEqual(f, GenerateTestVectors(DefaultLayout, 20, 1)) {
+		t.Error("same seed gave different vectors")
+	}
+	for _, v := range f.Vectors {
+		n, _ := strconv.ParseInt(v.ID, 10, 64)
+		d := ID(n).WithLayout(DefaultLayout)
+		if d.Timestamp() != v.Timestamp || int64(d.Tag()) != v.Tag || d.DistrictId() != v.DistrictId ||
+			d.NodeId() != v.NodeId || d.Sequence() != v.Sequence {
+			t.Errorf("vector %+v decodes differently", v)
+		}
+		if id, _ := ParseBase64URL(v.Base64URL); id != ID(n) {
+			t.Errorf("vector %s: base64url %s", v.ID, v.Base64URL)
+		}
+	}
+	if last := f.Vectors[2]; last.ID != strconv.FormatInt(int64(MaxID), 10) || last.Hex != "7fffffffffffffff" {
+		t.Errorf("largest vector: %+v", last)
+	}
+}
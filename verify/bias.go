@@ -0,0 +1,116 @@
+package verify
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/export"
+)
+
+// ShardFunc is a candidate way of mapping ids to n shards.
+type ShardFunc struct {
+	Name string
+	Fn   func(id snowflake.ID, n int) int
+}
+
+// ShardFuncs are the candidates a BiasAnalyzer compares, cheapest first.
+// Plain modulo sees mostly the node and sequence, and the sequence restarts
+// at 0 every millisecond, so at low traffic it puts each node on one
+// shard. Folding the high half in adds timestamp bits; ID.Shard mixes all
+// of them.
+var ShardFuncs = []ShardFunc{
+	{"modulo", func(id snowflake.ID, n int) int { return int(uint64(id) % uint64(n)) }},
+	{"xor-fold", func(id snowflake.ID, n int) int { return int((uint64(id) ^ uint64(id)>>32) % uint64(n)) }},
+	{"hash", func(id snowflake.ID, n int) int { return id.Shard(n) }},
+}
+
+// maxBiasZ is the z-score of the chi-square statistic above which a
+// function is reported biased, a one-sided p of 0.001.
+const maxBiasZ = 3.09
+
+// BiasReport is how one function spread a sample over the shards.
+type BiasReport struct {
+	Func      string
+	Shards    int
+	Total     int64
+	Counts    []int64
+	MaxRatio  float64 // 最大分片与均值之比
+	ChiSquare float64
+	Z         float64 // 卡方的正态近似 (Wilson-Hilferty), 均匀时约为 N(0,1)
+	Biased    bool    // Z 超过 maxBiasZ
+}
+
+// BiasAnalyzer measures the modulo bias of a sample of real ids for a
+// candidate shard count, with every function of ShardFuncs.
+type BiasAnalyzer struct {
+	shards int
+	total  int64
+	counts [][]int64
+}
+
+// NewBiasAnalyzer new an analyzer for n shards.
+func NewBiasAnalyzer(n int) (*BiasAnalyzer, error) {
+	if n < 2 {
+		return nil, errors.New("bias analysis needs at least 2 shards")
+	}
+	a := &BiasAnalyzer{shards: n, counts: make([][]int64, len(ShardFuncs))}
+	for i := range a.counts {
+		a.counts[i] = make([]int64, n)
+	}
+	return a, nil
+}
+
+// Add counts id.
+func (a *BiasAnalyzer) Add(id snowflake.ID) error {
+	for i, f := range ShardFuncs {
+		a.counts[i][f.Fn(id, a.shards)]++
+	}
+	a.total++
+	return nil
+}
+
+// AddFrom counts every id of r, one decimal id per line.
+func (a *BiasAnalyzer) AddFrom(r io.Reader) error {
+	return export.ReadIDs(r, a.Add)
+}
+
+// Reports returns the spread of the sample with every function of
+// ShardFuncs, in the same order.
+func (a *BiasAnalyzer) Reports() []BiasReport {
+	reports := make([]BiasReport, len(ShardFuncs))
+	mean := float64(a.total) / float64(a.shards)
+	k := float64(a.shards - 1)
+	for i, f := range ShardFuncs {
+		r := BiasReport{Func: f.Name, Shards: a.shards, Total: a.total, Counts: append([]int64(nil), a.counts[i]...)}
+		if a.total > 0 {
+			var max int64
+			for _, c := range r.Counts {
+				d := float64(c) - mean
+				r.ChiSquare += d * d / mean
+				if c > max {
+					max = c
+				}
+			}
+			r.MaxRatio = float64(max) / mean
+			r.Z = (math.Cbrt(r.ChiSquare/k) - (1 - 2/(9*k))) / math.Sqrt(2/(9*k))
+			r.Biased = r.Z > maxBiasZ
+		}
+		reports[i] = r
+	}
+	return reports
+}
+
+// Recommend returns the reports of the functions that spread the sample
+// evenly, cheapest first. The sample should hold at least a few dozen ids
+// per shard, or every function looks fine.
+func (a *BiasAnalyzer) Recommend() []BiasReport {
+	var safe []BiasReport
+	for _, r := range a.Reports() {
+		if a.total > 0 && !r.Biased {
+			safe = append(safe, r)
+		}
+	}
+	return safe
+}
@@ -0,0 +1,47 @@
+package verify
+
+import (
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+// tickClock advances one millisecond per read, so every id starts a
+// millisecond and has sequence 0, as at low traffic.
+type tickClock struct{ ms int64 }
+
+func (c *tickClock) Millis() int64 {
+	c.ms++
+	return c.ms
+}
+
+func TestBiasAnalyzer(t *testing.T) {
+	a, err := NewBiasAnalyzer(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker, _ := snowflake.NewIdWorker(3, snowflake.WithClock(&tickClock{ms: snowflake.DefaultLayout.Epoch}))
+	for i := 0; i < 4000; i++ {
+		id, err := worker.NextId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Add(id)
+	}
+	reports := a.Reports()
+	if r := reports[0]; r.Func != "modulo" || !r.Biased || r.MaxRatio != 16 {
+		t.Errorf("modulo should put every id on one shard: %+v", r)
+	}
+	safe := a.Recommend()
+	if len(safe) == 0 || safe[len(safe)-1].Func != "hash" {
+		t.Fatalf("hash should be recommended: %+v", safe)
+	}
+	for _, r := range safe {
+		if r.Func == "modulo" || r.MaxRatio > 1.3 {
+			t.Errorf("recommended a biased function: %+v", r)
+		}
+	}
+	if _, err := NewBiasAnalyzer(1); err == nil {
+		t.Error("one shard should fail")
+	}
+}
@@ -0,0 +1,133 @@
+package verify
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/export"
+)
+
+// AnomalyKind names what is wrong with an id of a foreign dataset.
+type AnomalyKind string
+
+const (
+	OutsideLayout   AnomalyKind = "outside-layout"   // 负数或超出布局的位
+	NoTimestamp     AnomalyKind = "no-timestamp"     // 时间戳字段为 0, 多半是自增 ID
+	FutureTimestamp AnomalyKind = "future-timestamp" // 晚于检查时刻加 MaxSkew
+	ImpossibleNode  AnomalyKind = "impossible-node"  // 节点不在声明的范围内
+	DuplicateTuple  AnomalyKind = "duplicate-tuple"  // (时间戳, 节点, 序号) 重复
+)
+
+// Anomaly is one finding of a Validator. For DuplicateTuple, ID has the
+// tag and district cleared and Count says how often the tuple occurred.
+type Anomaly struct {
+	Kind    AnomalyKind
+	ID      snowflake.ID
+	Count   int
+	Decoded export.Record
+}
+
+func (a Anomaly) String() string {
+	d := a.Decoded
+	s := fmt.Sprintf("%s %s time=%s node=%d sequence=%d", a.Kind, a.ID, d.Time, d.NodeId, d.Sequence)
+	if a.Kind == DuplicateTuple {
+		s += fmt.Sprintf(" x%d", a.Count)
+	}
+	return s
+}
+
+// Validator vets a dataset of ids claimed to come from one layout before
+// it is ingested. Each id is checked as it is added; duplicate tuples are
+// found by Finish, with the partitioned scan of Verifier, so datasets
+// larger than memory can be checked.
+type Validator struct {
+	Layout    snowflake.Layout
+	Now       time.Time     // 检查时刻, 默认为创建时
+	MaxSkew   time.Duration // 容许的时钟超前
+	MinNodeId int64         // 声明的节点范围, 默认为布局的全部节点
+	MaxNodeId int64
+
+	tuples *Verifier
+	total  int64
+	counts map[AnomalyKind]int64
+	report func(Anomaly)
+}
+
+// NewValidator new a validator of ids in layout, calling report for every
+// anomaly. dir and partitions are as for New.
+func NewValidator(layout snowflake.Layout, dir string, partitions int, report func(Anomaly)) (*Validator, error) {
+	v, err := New(dir, partitions)
+	if err != nil {
+		return nil, err
+	}
+	v.Layout = layout
+	return &Validator{
+		Layout:    layout,
+		Now:       time.Now(),
+		MaxNodeId: layout.MaxNodeId(),
+		tuples:    v,
+		counts:    make(map[AnomalyKind]int64),
+		report:    report,
+	}, nil
+}
+
+func (x *Validator) found(kind AnomalyKind, id snowflake.ID, count int) {
+	x.counts[kind]++
+	if x.report != nil {
+		x.report(Anomaly{Kind: kind, ID: id, Count: count, Decoded: export.Decode(id, x.Layout)})
+	}
+}
+
+// Add checks one id.
+func (x *Validator) Add(id snowflake.ID) error {
+	l := x.Layout
+	x.total++
+	if id < 0 || id > l.MaxID() {
+		x.found(OutsideLayout, id, 1)
+		return nil
+	}
+	v := id.WithLayout(l)
+	if v.Timestamp() == l.Epoch {
+		x.found(NoTimestamp, id, 1)
+	} else if v.Timestamp() > x.Now.Add(x.MaxSkew).UnixMilli() {
+		x.found(FutureTimestamp, id, 1)
+	}
+	if n := v.NodeId(); n < x.MinNodeId || n > x.MaxNodeId {
+		x.found(ImpossibleNode, id, 1)
+	}
+	return x.tuples.Add(id &^ x.tagDistrictMask())
+}
+
+// tagDistrictMask covers the fields outside the (timestamp, node,
+// sequence) tuple.
+func (x *Validator) tagDistrictMask() snowflake.ID {
+	l := x.Layout
+	shift := l.SequenceBits + l.NodeBits
+	return snowflake.ID((l.MaxDistrictId() | l.MaxTag()<<l.DistrictBits) << shift)
+}
+
+// AddFrom checks every id of r, one decimal id per line.
+func (x *Validator) AddFrom(r io.Reader) error {
+	return export.ReadIDs(r, x.Add)
+}
+
+// Finish reports the duplicate tuples and returns how many anomalies of
+// each kind were found.
+func (x *Validator) Finish() (map[AnomalyKind]int64, error) {
+	err := x.tuples.Check(func(c Collision) {
+		x.found(DuplicateTuple, c.ID, c.Count)
+	})
+	return x.counts, err
+}
+
+// Total returns the number of ids checked.
+func (x *Validator) Total() int64 {
+	return x.total
+}
+
+// Close removes the temporary files.
+func (x *Validator) Close() error {
+	return x.tuples.Close()
+}
@@ -0,0 +1,55 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestValidator(t *testing.T) {
+	l := snowflake.DefaultLayout
+	got := make(map[AnomalyKind][]snowflake.ID)
+	x, err := NewValidator(l, t.TempDir(), 4, func(a Anomaly) { got[a.Kind] = append(got[a.Kind], a.ID) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Close()
+	x.MaxNodeId = 15
+	x.MaxSkew = time.Second
+
+	worker, _ := snowflake.NewIdWorker(2)
+	ids, _ := worker.NextIds(50)
+	for _, id := range ids {
+		x.Add(id)
+	}
+	districtShift := l.SequenceBits + l.NodeBits
+	moved := ids[5] ^ snowflake.ID(4)<<districtShift // 同一元组, 另一区域
+	future := snowflake.ID((time.Now().Add(time.Hour).UnixMilli()-l.Epoch)<<(districtShift+l.DistrictBits+l.TagBits) | 2<<l.SequenceBits)
+	foreign, _ := snowflake.NewIdWorker(300)
+	other, _ := foreign.NextId()
+	in := strings.Join([]string{moved.String(), future.String(), other.String(), "12345", "-1"}, "\n")
+	if err := x.AddFrom(strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := x.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[AnomalyKind]int64{OutsideLayout: 1, NoTimestamp: 1, FutureTimestamp: 1, ImpossibleNode: 1, DuplicateTuple: 1}
+	for k, n := range want {
+		if counts[k] != n {
+			t.Errorf("%s: got %d, want %d (%v)", k, counts[k], n, got[k])
+		}
+	}
+	if len(got[FutureTimestamp]) == 1 && got[FutureTimestamp][0] != future {
+		t.Errorf("future: got %v", got[FutureTimestamp])
+	}
+	if len(got[ImpossibleNode]) == 1 && got[ImpossibleNode][0] != other {
+		t.Errorf("impossible node: got %v", got[ImpossibleNode])
+	}
+	if x.Total() != 55 {
+		t.Errorf("total: got %d, want 55", x.Total())
+	}
+}
@@ -0,0 +1,137 @@
+// Package verify checks very large sets of ids for duplicates without
+// holding them all in memory: ids are hash-partitioned into temporary files
+// and each partition is sorted and scanned on its own. Validator builds on
+// it to vet foreign datasets for ids that could not have been issued.
+package verify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	snowflake "github.com/sakishum/go_snowflake"
+	"github.com/sakishum/go_snowflake/export"
+)
+
+// Collision is an id seen more than once.
+type Collision struct {
+	ID      snowflake.ID
+	Count   int
+	Decoded export.Record
+}
+
+// Verifier collects ids and finds duplicates among them.
+type Verifier struct {
+	Layout snowflake.Layout
+
+	dir   string
+	files []*os.File
+	bufs  []*bufio.Writer
+	total int64
+}
+
+// New new a verifier spreading ids over partitions temporary files in dir
+// (the system temp directory when empty). Memory use while checking is
+// about 8 bytes times the ids of the largest partition.
+func New(dir string, partitions int) (*Verifier, error) {
+	if partitions < 1 {
+		partitions = 1
+	}
+	d, err := os.MkdirTemp(dir, "snowflake-verify-")
+	if err != nil {
+		return nil, err
+	}
+	v := &Verifier{Layout: snowflake.DefaultLayout, dir: d}
+	for i := 0; i < partitions; i++ {
+		f, err := os.Create(fmt.Sprintf("%s/%04d", d, i))
+		if err != nil {
+			v.Close()
+			return nil, err
+		}
+		v.files = append(v.files, f)
+		v.bufs = append(v.bufs, bufio.NewWriterSize(f, 64<<10))
+	}
+	return v, nil
+}
+
+// Add records one id.
+func (v *Verifier) Add(id snowflake.ID) error {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(id))
+	v.total++
+	_, err := v.bufs[partition(id, len(v.bufs))].Write(b[:])
+	return err
+}
+
+// AddFrom records every id of r, one decimal id per line.
+func (v *Verifier) AddFrom(r io.Reader) error {
+	return export.ReadIDs(r, v.Add)
+}
+
+// Total returns the number of ids added.
+func (v *Verifier) Total() int64 {
+	return v.total
+}
+
+// Check sorts each partition and calls fn for every duplicated id.
+func (v *Verifier) Check(fn func(Collision)) error {
+	for i, f := range v.files {
+		if err := v.bufs[i].Flush(); err != nil {
+			return err
+		}
+		ids, err := readPartition(f)
+		if err != nil {
+			return err
+		}
+		sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+		for j := 0; j < len(ids); {
+			k := j + 1
+			for k < len(ids) && ids[k] == ids[j] {
+				k++
+			}
+			if k-j > 1 {
+				id := snowflake.ID(ids[j])
+				fn(Collision{ID: id, Count: k - j, Decoded: export.Decode(id, v.Layout)})
+			}
+			j = k
+		}
+	}
+	return nil
+}
+
+// Close removes the temporary files.
+func (v *Verifier) Close() error {
+	for _, f := range v.files {
+		f.Close()
+	}
+	return os.RemoveAll(v.dir)
+}
+
+func readPartition(f *os.File) ([]int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, fi.Size())
+	if _, err := f.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	ids := make([]int64, len(buf)/8)
+	for i := range ids {
+		ids[i] = int64(binary.LittleEndian.Uint64(buf[8*i:]))
+	}
+	return ids, nil
+}
+
+// partition spreads ids evenly: the low bits of an id are mostly sequence
+// numbers close to zero, so the id is mixed (splitmix64) first.
+func partition(id snowflake.ID, n int) int {
+	z := uint64(id) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return int(z % uint64(n))
+}
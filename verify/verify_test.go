@@ -0,0 +1,34 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+
+	snowflake "github.com/sakishum/go_snowflake"
+)
+
+func TestVerifier(t *testing.T) {
+	v, err := New(t.TempDir(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+	worker, _ := snowflake.NewIdWorker(2)
+	ids, _ := worker.NextIds(100)
+	for _, id := range ids {
+		v.Add(id)
+	}
+	if err := v.AddFrom(strings.NewReader(ids[7].String() + "\n" + ids[7].String() + "\n" + ids[42].String() + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[snowflake.ID]int)
+	if err := v.Check(func(c Collision) { got[c.ID] = c.Count }); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[ids[7]] != 3 || got[ids[42]] != 2 {
+		t.Errorf("collisions: got %v", got)
+	}
+	if v.Total() != 103 {
+		t.Errorf("total: got %d", v.Total())
+	}
+}